@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Ingress annotation risk classification
+// ─────────────────────────────────────────────
+
+// nginxAnnotationPrefix prefixes every ingress-nginx annotation.
+const nginxAnnotationPrefix = "nginx.ingress.kubernetes.io/"
+
+// Annotation risk levels, as used by the annotations-risk-level ConfigMap key.
+const (
+	riskLow      = "Low"
+	riskMedium   = "Medium"
+	riskHigh     = "High"
+	riskCritical = "Critical"
+)
+
+// riskRank orders the levels for comparison with annotations-risk-level.
+var riskRank = map[string]int{riskLow: 0, riskMedium: 1, riskHigh: 2, riskCritical: 3}
+
+// annotationRisks is upstream's per-annotation risk (ingress-nginx v1.12
+// annotation parsers), keyed by the name without the prefix. Low-risk names
+// are listed too so that unrecognised annotations can be reported.
+var annotationRisks = map[string]string{
+	// Raw NGINX configuration
+	"configuration-snippet": riskCritical,
+	"server-snippet":        riskCritical,
+	"stream-snippet":        riskCritical,
+	"auth-snippet":          riskCritical,
+	"modsecurity-snippet":   riskCritical,
+
+	// Values rendered into directives or pointing traffic elsewhere
+	"server-alias":                          riskHigh,
+	"auth-url":                              riskHigh,
+	"auth-signin":                           riskHigh,
+	"auth-tls-error-page":                   riskHigh,
+	"auth-tls-match-cn":                     riskHigh,
+	"mirror-target":                         riskHigh,
+	"mirror-host":                           riskHigh,
+	"modsecurity-transaction-id":            riskHigh,
+	"proxy-ssl-name":                        riskHigh,
+	"upstream-hash-by":                      riskHigh,
+	"auth-signin-redirect-param":            riskMedium,
+	"auth-response-headers":                 riskMedium,
+	"auth-proxy-set-headers":                riskMedium,
+	"auth-request-redirect":                 riskMedium,
+	"auth-cache-key":                        riskMedium,
+	"auth-cache-duration":                   riskMedium,
+	"auth-secret":                           riskMedium,
+	"auth-realm":                            riskMedium,
+	"auth-tls-secret":                       riskMedium,
+	"auth-tls-verify-client":                riskMedium,
+	"cors-allow-origin":                     riskMedium,
+	"cors-allow-methods":                    riskMedium,
+	"cors-allow-headers":                    riskMedium,
+	"cors-expose-headers":                   riskMedium,
+	"custom-headers":                        riskMedium,
+	"allowlist-source-range":                riskMedium,
+	"whitelist-source-range":                riskMedium, // deprecated alias of allowlist-source-range
+	"denylist-source-range":                 riskMedium,
+	"fastcgi-index":                         riskMedium,
+	"fastcgi-params-configmap":              riskMedium,
+	"permanent-redirect":                    riskMedium,
+	"temporal-redirect":                     riskMedium,
+	"rewrite-target":                        riskMedium,
+	"force-ssl-redirect":                    riskMedium,
+	"app-root":                              riskMedium,
+	"x-forwarded-prefix":                    riskMedium,
+	"proxy-ssl-secret":                      riskMedium,
+	"proxy-ssl-ciphers":                     riskMedium,
+	"proxy-redirect-from":                   riskMedium,
+	"proxy-redirect-to":                     riskMedium,
+	"session-cookie-name":                   riskMedium,
+	"session-cookie-path":                   riskMedium,
+	"session-cookie-domain":                 riskMedium,
+	"proxy-cookie-domain":                   riskMedium,
+	"proxy-cookie-path":                     riskMedium,
+	"affinity-mode":                         riskMedium,
+	"opentelemetry-operation-name":          riskMedium,
+	"global-rate-limit-key":                 riskMedium,
+	"satisfy":                               riskLow,
+	"use-regex":                             riskLow,
+	"ssl-redirect":                          riskLow,
+	"backend-protocol":                      riskLow,
+	"enable-cors":                           riskLow,
+	"proxy-body-size":                       riskLow,
+	"proxy-read-timeout":                    riskLow,
+	"proxy-send-timeout":                    riskLow,
+	"proxy-connect-timeout":                 riskLow,
+	"proxy-buffering":                       riskLow,
+	"proxy-buffer-size":                     riskLow,
+	"limit-rps":                             riskLow,
+	"limit-connections":                     riskLow,
+	"affinity":                              riskLow,
+	"affinity-canary-behavior":              riskLow,
+	"auth-secret-type":                      riskLow,
+	"relative-redirects":                    riskLow,
+	"canary":                                riskLow,
+	"ssl-passthrough":                       riskLow,
+	"upstream-vhost":                        riskLow,
+	"service-upstream":                      riskLow,
+	"custom-http-errors":                    riskLow,
+	"default-backend":                       riskLow,
+	"enable-access-log":                     riskLow,
+	"enable-modsecurity":                    riskLow,
+	"enable-owasp-core-rules":               riskLow,
+	"mirror-request-body":                   riskLow,
+	"auth-type":                             riskLow,
+	"auth-method":                           riskLow,
+	"auth-always-set-cookie":                riskLow,
+	"from-to-www-redirect":                  riskLow,
+	"permanent-redirect-code":               riskLow,
+	"temporal-redirect-code":                riskLow,
+	"preserve-trailing-slash":               riskLow,
+	"load-balance":                          riskLow,
+	"client-body-buffer-size":               riskLow,
+	"connection-proxy-header":               riskLow,
+	"auth-tls-verify-depth":                 riskLow,
+	"auth-tls-pass-certificate-to-upstream": riskLow,
+	"proxy-ssl-verify":                      riskLow,
+	"proxy-ssl-verify-depth":                riskLow,
+	"proxy-ssl-protocols":                   riskLow,
+	"proxy-ssl-server-name":                 riskLow,
+	"ssl-ciphers":                           riskLow,
+	"ssl-prefer-server-ciphers":             riskLow,
+	"enable-opentelemetry":                  riskLow,
+	"x-forwarded-port":                      riskLow,
+}
+
+// annotationFamilies are prefixes of annotation groups whose members share a
+// risk and are too numerous to list (canary-*, proxy-*, limit-*, ...).
+var annotationFamilies = []string{
+	"canary-", "proxy-", "limit-", "session-cookie-", "cors-", "auth-keepalive",
+	"global-rate-limit", "upstream-", "opentelemetry-", "enable-", "ssl-",
+}
+
+// annotationRisk classifies one ingress-nginx annotation. known is false for
+// names the controller does not parse (ignored, most likely a typo).
+func annotationRisk(name string) (risk string, known bool) {
+	if r, ok := annotationRisks[name]; ok {
+		return r, true
+	}
+	for _, f := range annotationFamilies {
+		if strings.HasPrefix(name, f) {
+			return riskLow, true
+		}
+	}
+	return riskLow, false
+}
+
+// riskyAnnotation is an annotation at Medium risk or above.
+type riskyAnnotation struct {
+	Name string
+	Risk string
+}
+
+// clientVariableRE matches NGINX variables whose value the client controls.
+var clientVariableRE = regexp.MustCompile(`\$(http_\w+|arg_\w+|cookie_\w+|args|query_string|request_uri|uri|request_body|document_uri)\b`)
+
+// captureRefRE matches $1..$9 references in rewrite-target.
+var captureRefRE = regexp.MustCompile(`\$(\d)`)
+
+// validateAnnotations checks annotation values that upstream accepts but
+// that are wrong or dangerous. paths are the Ingress rule paths.
+func validateAnnotations(anns map[string]string, paths []string) []string {
+	var problems []string
+	get := func(k string) (string, bool) {
+		v, ok := anns[nginxAnnotationPrefix+k]
+		return v, ok
+	}
+
+	// Paths are regular expressions when use-regex is true or rewrite-target
+	// is set.
+	rewrite, hasRewrite := get("rewrite-target")
+	useRegex, _ := get("use-regex")
+	if useRegex == "true" || hasRewrite {
+		maxRef := 0
+		for _, m := range captureRefRE.FindAllStringSubmatch(rewrite, -1) {
+			n, _ := strconv.Atoi(m[1])
+			maxRef = max(maxRef, n)
+		}
+		for _, p := range paths {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("path %q is not a valid regular expression: %v", p, err))
+				continue
+			}
+			if hasRewrite && maxRef > re.NumSubexp() {
+				problems = append(problems, fmt.Sprintf("rewrite-target %q references $%d but path %q has %d capture group(s)",
+					rewrite, maxRef, p, re.NumSubexp()))
+			}
+		}
+	}
+
+	if v, ok := get("auth-url"); ok {
+		if u, err := url.Parse(v); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("auth-url %q is not an absolute http(s) URL", v))
+		}
+		if vars := clientVariableRE.FindAllString(v, -1); len(vars) > 0 {
+			problems = append(problems, fmt.Sprintf("auth-url interpolates client-controlled variables %s", strings.Join(vars, ", ")))
+		}
+	}
+
+	if v, ok := get("mirror-target"); ok {
+		u, err := url.Parse(v)
+		switch {
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			problems = append(problems, fmt.Sprintf("mirror-target %q is not an absolute http(s) URL", v))
+		case !isClusterLocalHost(u.Hostname()):
+			problems = append(problems, fmt.Sprintf("mirror-target sends a copy of every request, including credentials, to %s outside the cluster", u.Hostname()))
+		}
+	}
+	return problems
+}
+
+// isClusterLocalHost reports whether host is a Service name, not an
+// external host.
+func isClusterLocalHost(host string) bool {
+	return !strings.Contains(host, ".") || strings.HasSuffix(host, ".svc") ||
+		strings.Contains(host, ".svc.") || strings.HasSuffix(host, ".cluster.local")
+}
+
+// ingressPaths returns the HTTP paths of every rule of an Ingress.
+func ingressPaths(ing map[string]interface{}) []string {
+	var paths []string
+	for _, r := range getSlice(getMap(ing, "spec"), "rules") {
+		for _, p := range getSlice(getMap(asMap(r), "http"), "paths") {
+			if path := getString(asMap(p), "path"); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// evaluateIngressAnnotations classifies the ingress-nginx annotations of one
+// Ingress and returns one finding per concern, listing the annotations
+// involved. riskLevel is the effective annotations-risk-level ("" when the
+// controller does not enforce one).
+func evaluateIngressAnnotations(ing map[string]interface{}, riskLevel string) ([]riskyAnnotation, []Finding) {
+	meta := getMap(ing, "metadata")
+	res := fmt.Sprintf("ingress/%s/%s", getString(meta, "namespace"), getString(meta, "name"))
+	anns := getStringMap(meta, "annotations")
+
+	var names []string
+	for k := range anns {
+		if name, ok := strings.CutPrefix(k, nginxAnnotationPrefix); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var risky []riskyAnnotation
+	var rejected, accepted, unknown []string
+	worst := riskLow
+	for _, n := range names {
+		risk, known := annotationRisk(n)
+		if !known {
+			unknown = append(unknown, n)
+			continue
+		}
+		if riskRank[risk] < riskRank[riskMedium] {
+			continue
+		}
+		risky = append(risky, riskyAnnotation{n, risk})
+		label := fmt.Sprintf("%s (%s)", n, risk)
+		if riskLevel != "" && riskRank[risk] > riskRank[riskLevel] {
+			rejected = append(rejected, label)
+			continue
+		}
+		if riskRank[risk] >= riskRank[riskHigh] {
+			accepted = append(accepted, label)
+			if riskRank[risk] > riskRank[worst] {
+				worst = risk
+			}
+		}
+	}
+
+	var findings []Finding
+	if len(accepted) > 0 {
+		level := "WARN"
+		if worst == riskCritical {
+			level = "FAIL"
+		}
+		findings = append(findings, Finding{ID: "ing-annotation-risk", Level: level, Resource: res,
+			Message:     fmt.Sprintf("High-risk annotations in use: %s", strings.Join(accepted, ", ")),
+			Rationale:   "These annotations are rendered into nginx.conf or redirect traffic; a namespace user with Ingress access can use them to read Secrets or hijack routing.",
+			Remediation: "Replace them with lower-risk annotations or a ConfigMap setting, then lower annotations-risk-level"})
+	}
+	if len(rejected) > 0 {
+		findings = append(findings, Finding{ID: "ing-annotation-rejected", Level: "WARN", Resource: res,
+			Message:     fmt.Sprintf("Annotations above annotations-risk-level %s: %s — the controller ignores this Ingress", riskLevel, strings.Join(rejected, ", ")),
+			Remediation: "Remove the annotations; the Ingress is not served until then"})
+	}
+	if problems := validateAnnotations(anns, ingressPaths(ing)); len(problems) > 0 {
+		findings = append(findings, Finding{ID: "ing-annotation-invalid", Level: "WARN", Resource: res,
+			Message:     "Annotation values need attention: " + strings.Join(problems, "; "),
+			Remediation: "Fix the annotation values listed above"})
+	}
+	if len(unknown) > 0 {
+		findings = append(findings, Finding{ID: "ing-annotation-unknown", Level: "INFO", Resource: res,
+			Message:     fmt.Sprintf("Unrecognised ingress-nginx annotations (ignored): %s", strings.Join(unknown, ", ")),
+			Remediation: "Check for typos"})
+	}
+	return risky, findings
+}
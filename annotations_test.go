@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func annotatedIngress(anns map[string]interface{}, paths ...string) map[string]interface{} {
+	var ps []interface{}
+	for _, p := range paths {
+		ps = append(ps, map[string]interface{}{"path": p})
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"namespace": "team", "name": "web", "annotations": anns},
+		"spec": map[string]interface{}{"rules": []interface{}{
+			map[string]interface{}{"host": "a.example.com", "http": map[string]interface{}{"paths": ps}},
+		}},
+	}
+}
+
+func findingByID(fs []Finding, id string) (Finding, bool) {
+	for _, f := range fs {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return Finding{}, false
+}
+
+// ─── annotationRisk ──────────────────────────────────────────────────────────
+
+func TestAnnotationRisk(t *testing.T) {
+	cases := []struct {
+		name  string
+		risk  string
+		known bool
+	}{
+		{"configuration-snippet", riskCritical, true},
+		{"auth-url", riskHigh, true},
+		{"rewrite-target", riskMedium, true},
+		{"canary-weight", riskLow, true},
+		{"proxy-next-upstream-tries", riskLow, true},
+		{"proxy-cookie-path", riskMedium, true},
+		{"allowlist-source-range", riskMedium, true},
+		{"affinity-mode", riskMedium, true},
+		{"relative-redirects", riskLow, true},
+		{"rewite-target", riskLow, false},
+	}
+	for _, c := range cases {
+		risk, known := annotationRisk(c.name)
+		if risk != c.risk || known != c.known {
+			t.Errorf("annotationRisk(%s) = %s, %v; want %s, %v", c.name, risk, known, c.risk, c.known)
+		}
+	}
+}
+
+// ─── evaluateIngressAnnotations ──────────────────────────────────────────────
+
+func TestEvaluateIngressAnnotationsDeduplicated(t *testing.T) {
+	ing := annotatedIngress(map[string]interface{}{
+		nginxAnnotationPrefix + "configuration-snippet": "more_set_headers \"X: y\";",
+		nginxAnnotationPrefix + "server-snippet":        "location /x {}",
+		nginxAnnotationPrefix + "auth-url":              "https://auth.example.com/check",
+		nginxAnnotationPrefix + "proxy-body-size":       "10m",
+		"kubernetes.io/ingress.class":                   "nginx",
+	}, "/")
+	risky, fs := evaluateIngressAnnotations(ing, riskCritical)
+	if len(risky) != 3 {
+		t.Errorf("risky = %+v", risky)
+	}
+	f, ok := findingByID(fs, "ing-annotation-risk")
+	if !ok || len(fs) != 1 || f.Level != "FAIL" || f.Resource != "ingress/team/web" {
+		t.Fatalf("findings = %+v", fs)
+	}
+	for _, want := range []string{"configuration-snippet (Critical)", "server-snippet (Critical)", "auth-url (High)"} {
+		if !strings.Contains(f.Message, want) {
+			t.Errorf("message %q lacks %q", f.Message, want)
+		}
+	}
+}
+
+func TestEvaluateIngressAnnotationsRejectedByRiskLevel(t *testing.T) {
+	ing := annotatedIngress(map[string]interface{}{
+		nginxAnnotationPrefix + "server-snippet": "location /x {}",
+		nginxAnnotationPrefix + "auth-url":       "https://auth.example.com/check",
+	}, "/")
+	_, fs := evaluateIngressAnnotations(ing, riskHigh)
+	rej, ok := findingByID(fs, "ing-annotation-rejected")
+	if !ok || !strings.Contains(rej.Message, "server-snippet") {
+		t.Errorf("findings = %+v", fs)
+	}
+	if risk, ok := findingByID(fs, "ing-annotation-risk"); !ok || risk.Level != "WARN" || strings.Contains(risk.Message, "server-snippet") {
+		t.Errorf("risk finding = %+v", risk)
+	}
+}
+
+func TestEvaluateIngressAnnotationsUnknown(t *testing.T) {
+	ing := annotatedIngress(map[string]interface{}{nginxAnnotationPrefix + "rewite-target": "/"}, "/")
+	_, fs := evaluateIngressAnnotations(ing, riskHigh)
+	if len(fs) != 1 || fs[0].ID != "ing-annotation-unknown" {
+		t.Errorf("findings = %+v", fs)
+	}
+}
+
+// ─── validateAnnotations ─────────────────────────────────────────────────────
+
+func TestValidateAnnotations(t *testing.T) {
+	p := nginxAnnotationPrefix
+	cases := []struct {
+		name  string
+		anns  map[string]string
+		paths []string
+		want  string // substring of the only problem, "" for none
+	}{
+		{"rewrite ok", map[string]string{p + "rewrite-target": "/$2"}, []string{"/api(/|$)(.*)"}, ""},
+		{"rewrite missing group", map[string]string{p + "rewrite-target": "/$1"}, []string{"/api"}, "references $1"},
+		{"bad regex", map[string]string{p + "use-regex": "true"}, []string{"/api/(v1"}, "not a valid regular expression"},
+		{"plain paths without regex", map[string]string{}, []string{"/api/(v1"}, ""},
+		{"auth-url ok", map[string]string{p + "auth-url": "https://$host/oauth2/auth"}, nil, ""},
+		{"auth-url client vars", map[string]string{p + "auth-url": "http://auth.svc/check?u=$request_uri&t=$arg_token"}, nil, "$request_uri, $arg_token"},
+		{"auth-url relative", map[string]string{p + "auth-url": "/oauth2/auth"}, nil, "not an absolute"},
+		{"mirror in cluster", map[string]string{p + "mirror-target": "http://shadow.team.svc.cluster.local$request_uri"}, nil, ""},
+		{"mirror external", map[string]string{p + "mirror-target": "https://collector.example.net/$request_uri"}, nil, "outside the cluster"},
+	}
+	for _, c := range cases {
+		problems := validateAnnotations(c.anns, c.paths)
+		switch {
+		case c.want == "" && len(problems) != 0:
+			t.Errorf("%s: unexpected problems %v", c.name, problems)
+		case c.want != "" && (len(problems) != 1 || !strings.Contains(problems[0], c.want)):
+			t.Errorf("%s: problems = %v, want %q", c.name, problems, c.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -15,6 +16,10 @@ import (
 func (a *AuditState) auditAdmissionController() {
 	a.printHeader("PHASE 3 — ADMISSION CONTROLLER SECURITY AUDIT")
 
+	// ── Host-level exposure ──────────────────────────
+	a.printSection("Host Network Exposure")
+	a.auditHostExposure()
+
 	// ── Service discovery ────────────────────────────
 	a.printSection("Service Discovery")
 	a.logStep("Searching for admission controller service...")
@@ -66,7 +71,11 @@ func (a *AuditState) auditAdmissionController() {
 		a.writeln("  This configuration meets security best practices:")
 		a.writeln("    ✓ Not accessible from the internet")
 		a.writeln("    ✓ Protected by cluster network policies")
-		a.writeln(fmt.Sprintf("    ✓ Compliant with AbuseBSI requirements for %s", a.Domain))
+		if a.HostExposure.WebhookOnHost {
+			a.writeln("    ✗ But the webhook port is bound on the node network (see Host Network Exposure)")
+		} else {
+			a.writeln(fmt.Sprintf("    ✓ Compliant with AbuseBSI requirements for %s", a.Domain))
+		}
 
 	case "LoadBalancer":
 		a.AdmissionExternalIP, _ = kubectl("get", "svc", "-n", a.Namespace,
@@ -170,6 +179,7 @@ func (a *AuditState) auditAdmissionController() {
 	a.logStep("Analyzing ValidatingWebhookConfiguration...")
 
 	whJSON, _ := kubectl("get", "validatingwebhookconfigurations", "-o", "json")
+	var vwc map[string]interface{}
 	if whJSON != "" {
 		var whList map[string]interface{}
 		if json.Unmarshal([]byte(whJSON), &whList) == nil {
@@ -179,6 +189,7 @@ func (a *AuditState) auditAdmissionController() {
 				meta := getMap(itemMap, "metadata")
 				whName := fmt.Sprintf("%v", meta["name"])
 				if strings.Contains(whName, "ingress-nginx") {
+					vwc = itemMap
 					a.logInfo(fmt.Sprintf("Webhook name: %s", whName))
 					webhooks, _ := itemMap["webhooks"].([]interface{})
 					if len(webhooks) > 0 {
@@ -203,6 +214,12 @@ func (a *AuditState) auditAdmissionController() {
 		a.logWarn("ValidatingWebhookConfiguration not found — admission controller may not be active")
 	}
 
+	// ── CA bundle ────────────────────────────────────
+	if vwc != nil {
+		a.printSection("Webhook CA Bundle Consistency")
+		a.auditWebhookCA(vwc)
+	}
+
 	// ── Endpoints ────────────────────────────────────
 	a.printSection("Network Accessibility Analysis")
 	a.logStep("Checking service endpoints...")
@@ -220,7 +237,7 @@ func (a *AuditState) auditAdmissionController() {
 	// ── AbuseBSI compliance ──────────────────────────
 	a.printSection("AbuseBSI Compliance Summary")
 	boxColor := lipgloss.Color("196") // red
-	if a.AdmissionSvcType == "ClusterIP" && a.IngressExposing == "" {
+	if a.abuseBSICompliant() {
 		boxColor = lipgloss.Color("46") // green
 	}
 	box := infoBox(boxColor,
@@ -234,11 +251,12 @@ func (a *AuditState) auditAdmissionController() {
 		a.writeln("  " + line)
 	}
 
-	if a.AdmissionSvcType == "ClusterIP" && a.IngressExposing == "" {
+	if a.abuseBSICompliant() {
 		a.writeln(fmt.Sprintf("\n  %s%s✓ COMPLIANT — Vulnerability has been mitigated.%s\n", Green, Bold, Reset))
 		a.writeln("  Details:")
 		a.writeln("    ✓ Admission controller is ClusterIP (not exposed)")
 		a.writeln("    ✓ No Ingress resources expose the admission endpoint")
+		a.writeln("    ✓ Webhook port is not bound on the node network")
 		a.writeln("    ✓ Only accessible within cluster network")
 	} else {
 		a.writeln(fmt.Sprintf("\n  %s%s✗ NON-COMPLIANT — Vulnerability is STILL PRESENT!%s\n", Red, Bold, Reset))
@@ -248,6 +266,115 @@ func (a *AuditState) auditAdmissionController() {
 		if a.IngressExposing != "" {
 			a.writeln("    ✗ Ingress resources are exposing the admission controller")
 		}
+		if a.HostExposure.WebhookOnHost {
+			a.writeln("    ✗ Webhook port is bound on every node IP (hostNetwork/hostPort)")
+		}
 		a.writeln("\n  IMMEDIATE ACTION REQUIRED — See remediation steps above.")
 	}
 }
+
+// abuseBSICompliant reports whether the admission webhook is unreachable from
+// outside the cluster: a ClusterIP Service, no Ingress routing to it and no
+// webhook port bound on the node network.
+func (a *AuditState) abuseBSICompliant() bool {
+	return a.AdmissionSvcType == "ClusterIP" && a.IngressExposing == "" && !a.HostExposure.WebhookOnHost
+}
+
+// auditHostExposure checks hostNetwork, hostPort, host namespaces, hostPath
+// volumes and dnsPolicy of the controller pod. The Service checks below
+// cannot see ports published directly on the nodes.
+func (a *AuditState) auditHostExposure() {
+	podSpec := a.controllerPodSpec()
+	if len(getSlice(podSpec, "containers")) == 0 {
+		a.logWarn("Controller pod template not found — skipping host exposure checks")
+		return
+	}
+	res := fmt.Sprintf("%s/%s/%s", a.controllerResType(), a.Namespace, a.ControllerName)
+	ex, findings := evaluateHostExposure(podSpec, a.controllerArgs(), res)
+	a.HostExposure = ex
+	a.logInfo(fmt.Sprintf("hostNetwork: %v", ex.HostNetwork))
+	if len(ex.HostPorts) > 0 {
+		a.logInfo(fmt.Sprintf("hostPorts:   %s", strings.Join(ex.HostPorts, ", ")))
+	}
+	if len(findings) == 0 {
+		a.logPass("No ports, namespaces or paths of the node are shared with the controller")
+	}
+	for _, f := range findings {
+		a.recordFinding(f)
+	}
+}
+
+// auditWebhookCA checks that the admission serving certificate chains to
+// the caBundle of the webhook configuration. A stale caBundle is re-synced
+// from the admission secret; a broken secret is regenerated by re-running
+// the chart's certgen hooks.
+func (a *AuditState) auditWebhookCA(vwc map[string]interface{}) {
+	a.logStep("Comparing the serving certificate with the webhook caBundle...")
+	name := getString(getMap(vwc, "metadata"), "name")
+	var bundles []string
+	svcName, svcNS := "ingress-nginx-controller-admission", a.Namespace
+	for i, w := range getSlice(vwc, "webhooks") {
+		cc := getMap(asMap(w), "clientConfig")
+		bundles = append(bundles, getString(cc, "caBundle"))
+		if svc := getMap(cc, "service"); i == 0 && svc != nil {
+			svcName, svcNS = getString(svc, "name"), getString(svc, "namespace")
+		}
+	}
+	secret, err := kubectlJSON("get", "secret", "-n", a.Namespace, admissionSecretName)
+	if err != nil {
+		a.logWarn(fmt.Sprintf("Could not read secret %s/%s — CA bundle not verified", a.Namespace, admissionSecretName))
+		return
+	}
+	data := getMap(secret, "data")
+	res := "validatingwebhookconfiguration/" + name
+	r := evaluateWebhookCA(bundles, data, svcName, svcNS, time.Now(), res)
+	for _, f := range r.Findings {
+		a.recordFinding(f)
+	}
+	if len(r.Findings) == 0 {
+		a.logPass(fmt.Sprintf("Serving certificate chains to the caBundle, covers %s.%s.svc and is valid", svcName, svcNS))
+	}
+
+	switch {
+	case r.Resync:
+		patch := caBundlePatch(len(bundles), getString(data, "ca"))
+		a.addFix("webhook-ca-resync", "CRITICAL",
+			fmt.Sprintf("Re-sync the caBundle of %s from secret %s/%s", name, a.Namespace, admissionSecretName),
+			fmt.Sprintf("kubectl patch validatingwebhookconfiguration %s --type json -p '%s'", name, patch),
+			func() error {
+				return runCmd("kubectl", "patch", "validatingwebhookconfiguration", name, "--type", "json", "-p", string(patch))
+			})
+	case r.Regenerate && a.HelmRelease != "" && !a.helmFixUnavailable("webhook-cert-regenerate"):
+		args := a.helmUpgradeArgs(nil)
+		dryRun := append(append([]string{}, args...), "--dry-run")
+		ns := a.Namespace
+		// The certgen create hook only issues a certificate when the secret
+		// is absent, so it is deleted, but only after a dry-run of the pinned
+		// upgrade passes, and restored if the upgrade still fails.
+		backup, _ := json.Marshal(map[string]interface{}{
+			"apiVersion": "v1", "kind": "Secret", "type": getString(secret, "type"), "data": data,
+			"metadata": map[string]interface{}{"name": admissionSecretName, "namespace": ns},
+		})
+		a.addFix("webhook-cert-regenerate", "CRITICAL",
+			"Regenerate the admission certificate and re-run the certgen create/patch jobs (Helm hooks)",
+			fmt.Sprintf("helm %s && kubectl delete secret %s -n %s && helm %s", shellJoin(dryRun), admissionSecretName, ns, shellJoin(args)),
+			func() error {
+				if err := runCmd("helm", dryRun...); err != nil {
+					return fmt.Errorf("helm upgrade dry-run failed, secret %s left in place: %w", admissionSecretName, err)
+				}
+				if err := runCmd("kubectl", "delete", "secret", admissionSecretName, "-n", ns); err != nil {
+					return err
+				}
+				if err := runCmd("helm", args...); err != nil {
+					if rerr := runCmdInput(string(backup), "kubectl", "create", "-f", "-"); rerr != nil {
+						return fmt.Errorf("helm upgrade failed (%v) and restoring secret %s failed: %w", err, admissionSecretName, rerr)
+					}
+					return fmt.Errorf("helm upgrade failed, secret %s restored: %w", admissionSecretName, err)
+				}
+				return nil
+			})
+	case r.Regenerate:
+		a.logInfo(fmt.Sprintf("Delete secret %s/%s and re-apply the controller manifests so the ingress-nginx-admission-create and -patch jobs run again",
+			a.Namespace, admissionSecretName))
+	}
+}
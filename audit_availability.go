@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ─────────────────────────────────────────────
+// PHASE 10 — Availability Audit
+// ─────────────────────────────────────────────
+
+func (a *AuditState) auditAvailability() {
+	a.printHeader("PHASE 10 — AVAILABILITY AUDIT")
+
+	a.printSection("Disruption Posture")
+	workload := a.controllerWorkload()
+	if len(workload) == 0 {
+		a.logWarn("Controller workload not found — skipping availability checks")
+		return
+	}
+	kind := getString(workload, "kind")
+	in := availabilityInput{
+		Kind:      kind,
+		Name:      a.ControllerName,
+		PodSpec:   a.controllerPodSpec(),
+		Container: a.controllerContainer(),
+		PodLabels: a.controllerPodLabels(),
+	}
+	if kind == "DaemonSet" {
+		in.Replicas, _ = toInt(getMap(workload, "status")["desiredNumberScheduled"])
+	} else if n, ok := toInt(getMap(workload, "spec")["replicas"]); ok {
+		in.Replicas = n
+	} else {
+		in.Replicas = 1
+	}
+	a.logInfo(fmt.Sprintf("%s replicas: %d", kind, in.Replicas))
+
+	a.logStep("Fetching PodDisruptionBudgets and HorizontalPodAutoscalers...")
+	pdbs, _ := kubectlJSON("get", "poddisruptionbudgets", "-n", a.Namespace)
+	in.PDBs = getSlice(pdbs, "items")
+	hpas, _ := kubectlJSON("get", "horizontalpodautoscalers", "-n", a.Namespace)
+	in.HPAs = getSlice(hpas, "items")
+
+	a.logStep("Checking actual pod placement...")
+	zoneOf := map[string]string{}
+	zones := map[string]bool{}
+	nodes, _ := kubectlJSON("get", "nodes")
+	for _, n := range getSlice(nodes, "items") {
+		nm, _ := n.(map[string]interface{})
+		meta := getMap(nm, "metadata")
+		if z := getStringMap(meta, "labels")[zoneTopologyKey]; z != "" {
+			zoneOf[getString(meta, "name")] = z
+			zones[z] = true
+		}
+	}
+	in.ClusterZones = len(zones)
+	pods, _ := kubectlJSON("get", "pods", "-n", a.Namespace, "-l", a.controllerPodSelector())
+	for _, p := range getSlice(pods, "items") {
+		pm, _ := p.(map[string]interface{})
+		node := getString(getMap(pm, "spec"), "nodeName")
+		if node == "" {
+			continue
+		}
+		pl := podPlacement{Name: getString(getMap(pm, "metadata"), "name"), Node: node, Zone: zoneOf[node]}
+		in.Pods = append(in.Pods, pl)
+		a.logInfo(fmt.Sprintf("Pod %s on node %s (zone %s)", pl.Name, pl.Node, orDefault(pl.Zone)))
+	}
+
+	// --shutdown-grace-period is given in seconds.
+	if n, err := strconv.Atoi(a.controllerArgs()["shutdown-grace-period"]); err == nil {
+		in.ShutdownGrace = time.Duration(n) * time.Second
+	}
+	wst, _ := effectiveConfig(a.controllerConfigMap(), "worker-shutdown-timeout", a.ControllerVersion)
+	in.WorkerTimeout, _ = time.ParseDuration(wst)
+
+	res := fmt.Sprintf("%s/%s/%s", a.controllerResType(), a.Namespace, a.ControllerName)
+	findings := evaluateAvailability(in, res)
+	for _, f := range findings {
+		a.recordFinding(f)
+	}
+	if len(findings) == 0 {
+		a.logPass("Controller is replicated, spread and protected against disruption")
+	}
+}
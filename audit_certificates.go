@@ -1,10 +1,8 @@
 package main
 
 import (
-	"bytes"
 	"encoding/base64"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
 )
@@ -24,52 +22,15 @@ func (a *AuditState) auditCertificates() {
 		a.logWarn("Admission webhook certificate secret not found")
 	} else {
 		a.logPass("Admission webhook certificate secret exists")
-
-		a.logStep("Decoding certificate...")
-		certB64, _ := kubectl("get", "secret", "-n", a.Namespace,
-			"ingress-nginx-admission", "-o", "jsonpath={.data.cert}")
-		if certB64 != "" {
-			certPEM, err := base64.StdEncoding.DecodeString(certB64)
-			if err == nil {
-				cmd := exec.Command("openssl", "x509", "-noout", "-enddate")
-				cmd.Stdin = bytes.NewReader(certPEM)
-				out, err := cmd.Output()
-				if err == nil {
-					expiry := strings.TrimPrefix(strings.TrimSpace(string(out)), "notAfter=")
-					a.logInfo(fmt.Sprintf("Certificate expiry: %s", expiry))
-					expiryTime, parseErr := time.Parse("Jan  2 15:04:05 2006 MST", expiry)
-					if parseErr == nil {
-						daysLeft := int(time.Until(expiryTime).Hours() / 24)
-						switch {
-						case daysLeft < 0:
-							a.logFail("Certificate has EXPIRED!")
-						case daysLeft < 30:
-							a.logWarn(fmt.Sprintf("Certificate expires in %d days — renewal needed soon", daysLeft))
-						default:
-							a.logPass(fmt.Sprintf("Certificate valid for %d days", daysLeft))
-						}
-					}
-				}
-			}
-		}
+		a.auditCertSecret(a.Namespace, "ingress-nginx-admission", "cert")
 	}
 
 	// ── Default SSL certificate ──────────────────────
 	a.printSection("Default SSL Certificate")
-	resType := strings.ToLower(a.DeploymentType)
-	if resType == "" {
-		resType = "deployment"
-	}
-
 	a.logStep("Checking default SSL certificate arg...")
-	args, _ := kubectl("get", resType, "-n", a.Namespace, a.ControllerName,
-		"-o", "jsonpath={.spec.template.spec.containers[0].args}")
-
-	defaultCert := "not-set"
-	for _, arg := range strings.Fields(args) {
-		if strings.HasPrefix(arg, "--default-ssl-certificate=") {
-			defaultCert = strings.TrimPrefix(arg, "--default-ssl-certificate=")
-		}
+	defaultCert := expandPodNamespace(a.controllerArgs()["default-ssl-certificate"], a.Namespace)
+	if defaultCert == "" {
+		defaultCert = "not-set"
 	}
 
 	if defaultCert != "not-set" {
@@ -78,6 +39,7 @@ func (a *AuditState) auditCertificates() {
 		if len(parts) == 2 {
 			if _, err := kubectl("get", "secret", "-n", parts[0], parts[1]); err == nil {
 				a.logPass("Default SSL certificate secret exists")
+				a.auditCertSecret(parts[0], parts[1], "tls.crt")
 			} else {
 				a.logFail(fmt.Sprintf("Default SSL certificate secret '%s' not found", defaultCert))
 			}
@@ -86,3 +48,37 @@ func (a *AuditState) auditCertificates() {
 		a.logInfo("No default SSL certificate configured (will use self-signed)")
 	}
 }
+
+// auditCertSecret parses the certificate chain stored under key in a Secret,
+// prints its details and records expiry, key and signature findings.
+func (a *AuditState) auditCertSecret(ns, name, key string) {
+	a.logStep(fmt.Sprintf("Decoding certificate %s/%s...", ns, name))
+	secret, err := kubectlJSON("get", "secret", "-n", ns, name)
+	if err != nil {
+		a.logWarn(fmt.Sprintf("Could not read secret %s/%s", ns, name))
+		return
+	}
+	pemData, err := base64.StdEncoding.DecodeString(getString(getMap(secret, "data"), key))
+	if err != nil || len(pemData) == 0 {
+		a.logWarn(fmt.Sprintf("Secret %s/%s has no %s", ns, name, key))
+		return
+	}
+	res := fmt.Sprintf("secret/%s/%s", ns, name)
+	info, err := describeCert(res, pemData)
+	if err != nil {
+		a.addFinding("cert-unparseable", "WARN", res, fmt.Sprintf("%s is not a valid certificate: %v", key, err),
+			"Store a PEM-encoded certificate chain in the secret")
+		return
+	}
+	a.Certificates = append(a.Certificates, info)
+	for _, line := range certSummary(info) {
+		a.writeln("    " + line)
+	}
+	findings := evaluateCert(info, time.Now(), res)
+	for _, f := range findings {
+		a.recordFinding(f)
+	}
+	if len(findings) == 0 {
+		a.logPass(fmt.Sprintf("Certificate valid for %d days", int(time.Until(info.NotAfter).Hours()/24)))
+	}
+}
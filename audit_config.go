@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
 )
 
@@ -31,25 +32,20 @@ func (a *AuditState) auditConfiguration() {
 			if a.AllowSnippets == "true" {
 				a.logFail("SECURITY RISK: allow-snippet-annotations is enabled")
 				a.logInfo("REMEDIATION: Disable snippet annotations unless absolutely required")
-				ns := a.Namespace
-				a.addFix("snippet-annotations", "CRITICAL",
+				a.logInfo("Phase 9 \"Snippet Conversion\" proposes annotations that replace existing snippets")
+				a.addConfigMapFix("snippet-annotations", "CRITICAL",
 					"Disable allow-snippet-annotations in ingress-nginx ConfigMap",
-					fmt.Sprintf(`kubectl patch cm ingress-nginx-controller -n %s --type merge -p '{"data":{"allow-snippet-annotations":"false"}}'`, ns),
-					func() error {
-						return runCmd("kubectl", "patch", "cm", a.ControllerName,
-							"-n", ns, "--type", "merge",
-							"-p", `{"data":{"allow-snippet-annotations":"false"}}`)
-					})
+					map[string]string{"allow-snippet-annotations": "false"})
 			} else {
 				a.logPass("Snippet annotations disabled (secure default)")
 			}
 
 			a.logStep("Checking SSL protocols...")
-			sslProto := fmt.Sprintf("%v", data["ssl-protocols"])
-			a.logInfo(fmt.Sprintf("SSL protocols: %s", sslProto))
-			if strings.Contains(sslProto, "TLSv1 ") {
-				a.logWarn("TLSv1 is enabled — consider disabling for better security")
+			sslProto, set := effectiveConfig(a.controllerConfigMap(), "ssl-protocols", a.ControllerVersion)
+			if !set {
+				sslProto += " (default)"
 			}
+			a.logInfo(fmt.Sprintf("SSL protocols: %s", sslProto))
 
 			a.logStep("Checking custom HTTP errors...")
 			customErr := fmt.Sprintf("%v", data["custom-http-errors"])
@@ -59,45 +55,335 @@ func (a *AuditState) auditConfiguration() {
 		a.logWarn("ConfigMap 'ingress-nginx-controller' not found")
 	}
 
+	// ── ConfigMap snippets ───────────────────────────
+	a.printSection("ConfigMap Snippets")
+	a.auditConfigMapSnippets()
+
+	// ── Hardening rules ──────────────────────────────
+	a.printSection("ConfigMap Hardening Rules")
+	a.auditConfigRules()
+
+	// ── TLS profile ──────────────────────────────────
+	a.printSection("TLS Profile Compliance")
+	a.auditTLSProfile()
+
+	// ── Controller arguments ─────────────────────────
+	a.printSection("Controller Arguments")
+	a.auditControllerArgs()
+
 	// ── Resource limits ──────────────────────────────
 	a.printSection("Resource Limits")
-	resType := strings.ToLower(a.DeploymentType)
-	if resType == "" {
-		resType = "deployment"
+	a.auditResources()
+}
+
+// auditConfigMapSnippets parses the global snippets of the controller
+// ConfigMap and records the dangerous directives they contain.
+func (a *AuditState) auditConfigMapSnippets() {
+	cm := a.controllerConfigMap()
+	res := fmt.Sprintf("configmap/%s/%s", a.Namespace, a.ControllerName)
+	parsed, clean := 0, true
+	for _, key := range configMapSnippetKeys {
+		if strings.TrimSpace(cm[key]) == "" {
+			continue
+		}
+		parsed++
+		for _, f := range inspectSnippet("ConfigMap "+key, cm[key], res) {
+			a.recordFinding(f)
+			clean = false
+		}
+	}
+	switch {
+	case parsed == 0:
+		a.logPass("No main-snippet, http-snippet or server-snippet set")
+	case clean:
+		a.logPass(fmt.Sprintf("%d ConfigMap snippets contain no dangerous directives", parsed))
+	}
+}
+
+// auditConfigRules evaluates configRules against the controller ConfigMap,
+// using version-specific defaults for keys that are not set.
+func (a *AuditState) auditConfigRules() {
+	cm := a.controllerConfigMap()
+	res := fmt.Sprintf("configmap/%s/%s", a.Namespace, a.ControllerName)
+	for _, r := range configRules {
+		level, msg, fix := r.Check(cm, a.ControllerVersion)
+		if level == "" {
+			a.logPass(fmt.Sprintf("%s: %s", r.ID, strings.Join(r.Keys, ", ")))
+			continue
+		}
+		a.recordFinding(Finding{
+			ID:          r.ID,
+			Level:       level,
+			Resource:    res,
+			Message:     msg,
+			Rationale:   r.Rationale,
+			Remediation: fix,
+		})
+	}
+}
+
+// auditControllerArgs checks the controller's command-line flags, including
+// the objects they reference.
+func (a *AuditState) auditControllerArgs() {
+	a.logStep("Parsing controller container args...")
+	args := a.controllerArgs()
+	if len(args) == 0 {
+		a.logWarn("Controller container args not found — skipping argument checks")
+		return
+	}
+	res := fmt.Sprintf("%s/%s/%s", a.controllerResType(), a.Namespace, a.ControllerName)
+	findings := evaluateControllerArgs(args, a.controllerConfigMap(), a.ControllerVersion, res)
+
+	for _, flagName := range []string{"publish-service", "default-backend-service"} {
+		ref := expandPodNamespace(args[flagName], a.Namespace)
+		if ref == "" {
+			continue
+		}
+		a.logStep(fmt.Sprintf("Checking --%s %s...", flagName, ref))
+		parts := strings.SplitN(ref, "/", 2)
+		if len(parts) != 2 {
+			findings = append(findings, Finding{ID: "args-" + flagName, Level: "WARN", Resource: res,
+				Message: fmt.Sprintf("--%s=%s is not in namespace/name form", flagName, ref)})
+		} else if _, err := kubectl("get", "service", "-n", parts[0], parts[1]); err != nil {
+			findings = append(findings, Finding{ID: "args-" + flagName, Level: "WARN", Resource: res,
+				Message:     fmt.Sprintf("--%s references missing Service %s", flagName, ref),
+				Remediation: "Create the Service or correct the flag"})
+		}
+	}
+
+	for _, proto := range []string{"TCP", "UDP"} {
+		flagName := strings.ToLower(proto) + "-services-configmap"
+		ref := expandPodNamespace(args[flagName], a.Namespace)
+		if ref == "" {
+			continue
+		}
+		a.logStep(fmt.Sprintf("Checking --%s %s...", flagName, ref))
+		ns, name, ok := strings.Cut(ref, "/")
+		if !ok {
+			ns, name = a.Namespace, ref
+		}
+		cm, err := kubectlJSON("get", "configmap", "-n", ns, name)
+		if err != nil {
+			a.logInfo(fmt.Sprintf("%s services ConfigMap %s not found — no %s ports forwarded", proto, ref, proto))
+			continue
+		}
+		findings = append(findings, evaluateStreamServices(proto, getStringMap(cm, "data"), "configmap/"+ns+"/"+name)...)
+	}
+
+	if argEnabled(args, "enable-metrics", true) {
+		for _, p := range a.NetPol.Ports {
+			if p.Name == "metrics" && (!p.Isolated || p.Anywhere) {
+				findings = append(findings, Finding{ID: "args-metrics-exposed", Level: "WARN", Resource: res,
+					Message:     fmt.Sprintf("Metrics are enabled and port %d is reachable from any source", p.Port),
+					Rationale:   "Metrics reveal every host, path and upstream the controller serves.",
+					Remediation: "Restrict the metrics port to the monitoring namespace with a NetworkPolicy, or set --enable-metrics=false"})
+			}
+		}
+	}
+
+	for _, f := range findings {
+		a.recordFinding(f)
+	}
+	if len(findings) == 0 {
+		a.logPass("Controller arguments follow hardening recommendations")
+	}
+}
+
+// auditTLSProfile grades the effective TLS settings against the Mozilla
+// profiles and offers a ConfigMap patch for the --tls-profile target.
+func (a *AuditState) auditTLSProfile() {
+	target, ok := findTLSProfile(a.TLSProfile)
+	if !ok {
+		a.logWarn(fmt.Sprintf("Unknown TLS profile %q — grading against intermediate", a.TLSProfile))
+		target, _ = findTLSProfile("intermediate")
 	}
+	a.logStep(fmt.Sprintf("Grading TLS settings against the Mozilla %s profile...", target.Name))
+	result, patch := evaluateTLSProfile(a.controllerConfigMap(), a.ControllerVersion, target)
+	a.TLSCompliance = result
+	for _, k := range tlsProfileKeys {
+		a.logInfo(fmt.Sprintf("%-26s %s", k+":", result.Effective[k]))
+	}
+	a.logInfo(fmt.Sprintf("Strictest Mozilla profile satisfied: %s", result.Satisfied))
+
+	if result.Compliant {
+		a.logPass(fmt.Sprintf("TLS settings comply with the Mozilla %s profile", target.Name))
+		return
+	}
+	res := fmt.Sprintf("configmap/%s/%s", a.Namespace, a.ControllerName)
+	for _, b := range result.Blockers {
+		a.recordFinding(Finding{
+			ID:          "tls-profile",
+			Level:       "WARN",
+			Resource:    res,
+			Message:     fmt.Sprintf("Mozilla %s profile: %s", target.Name, b),
+			Remediation: "Apply the tls-profile fix or set the listed ConfigMap keys",
+		})
+	}
+
+	a.addConfigMapFix("tls-profile", "WARNING",
+		fmt.Sprintf("Patch ConfigMap to satisfy the Mozilla %s TLS profile", target.Name), patch)
+}
 
+// auditResources compares the controller container's requests and limits
+// with observed usage and offers usage-based values as a fix.
+func (a *AuditState) auditResources() {
 	a.logStep("Fetching resource limits...")
-	a.CPURequest, _ = kubectl("get", resType, "-n", a.Namespace, a.ControllerName,
-		"-o", "jsonpath={.spec.template.spec.containers[0].resources.requests.cpu}")
-	a.CPULimit, _ = kubectl("get", resType, "-n", a.Namespace, a.ControllerName,
-		"-o", "jsonpath={.spec.template.spec.containers[0].resources.limits.cpu}")
-	a.MemoryRequest, _ = kubectl("get", resType, "-n", a.Namespace, a.ControllerName,
-		"-o", "jsonpath={.spec.template.spec.containers[0].resources.requests.memory}")
-	a.MemoryLimit, _ = kubectl("get", resType, "-n", a.Namespace, a.ControllerName,
-		"-o", "jsonpath={.spec.template.spec.containers[0].resources.limits.memory}")
+	res := getMap(a.controllerContainer(), "resources")
+	a.CPURequest = getString(getMap(res, "requests"), "cpu")
+	a.CPULimit = getString(getMap(res, "limits"), "cpu")
+	a.MemoryRequest = getString(getMap(res, "requests"), "memory")
+	a.MemoryLimit = getString(getMap(res, "limits"), "memory")
 
 	a.logInfo(fmt.Sprintf("CPU:    request=%s  limit=%s", orDefault(a.CPURequest), orDefault(a.CPULimit)))
 	a.logInfo(fmt.Sprintf("Memory: request=%s  limit=%s", orDefault(a.MemoryRequest), orDefault(a.MemoryLimit)))
 
-	if a.CPULimit == "" || a.MemoryLimit == "" {
-		if a.DeploymentType != "" {
-			a.logWarn("Resource limits not set — may impact cluster stability")
-			ns := a.Namespace
-			rt := strings.ToLower(a.DeploymentType)
-			if rt == "" {
-				rt = "deployment"
-			}
-			a.addFix("resource-limits", "WARNING",
-				"Set default resource limits on ingress-nginx-controller",
-				fmt.Sprintf("kubectl set resources %s ingress-nginx-controller -n %s --limits=cpu=200m,memory=256Mi --requests=cpu=100m,memory=128Mi", rt, ns),
-				func() error {
-					return runCmd("kubectl", "set", "resources", rt, a.ControllerName,
-						"-n", ns,
-						"--limits=cpu=200m,memory=256Mi",
-						"--requests=cpu=100m,memory=128Mi")
-				})
-		}
+	var cur resourceSpec
+	cur.CPURequest, _ = parseCPU(a.CPURequest)
+	cur.CPULimit, _ = parseCPU(a.CPULimit)
+	cur.MemRequest, _ = parseMemory(a.MemoryRequest)
+	cur.MemLimit, _ = parseMemory(a.MemoryLimit)
+
+	a.logStep("Reading controller usage from metrics.k8s.io...")
+	usage, haveMetrics := a.observeControllerUsage()
+	if haveMetrics {
+		a.logInfo(fmt.Sprintf("Current usage: cpu=%s  memory=%s", formatCPU(usage.CPU), formatMemory(usage.Mem)))
 	} else {
+		a.logWarn("metrics.k8s.io unavailable (metrics-server not installed?) — recommendations use minimum values only")
+	}
+	if usage.Restarts > 0 {
+		a.logInfo(fmt.Sprintf("Controller container restarts: %d", usage.Restarts))
+	}
+
+	resName := fmt.Sprintf("%s/%s/%s", a.controllerResType(), a.Namespace, a.ControllerName)
+	tight := false
+	if usage.OOMKilled {
+		tight = true
+		a.addFinding("resource-oomkilled", "FAIL", resName,
+			fmt.Sprintf("Controller was OOMKilled (memory limit %s)", orDefault(a.MemoryLimit)),
+			"Raise the memory limit; see the resource-limits fix")
+	}
+	if haveMetrics && nearLimit(usage.Mem, cur.MemLimit) {
+		tight = true
+		a.addFinding("resource-near-limit", "WARN", resName,
+			fmt.Sprintf("Memory usage %s is within 20%% of the limit %s", formatMemory(usage.Mem), a.MemoryLimit),
+			"Raise the memory limit before the next config reload pushes it over")
+	}
+	if haveMetrics && nearLimit(usage.CPU, cur.CPULimit) {
+		tight = true
+		a.addFinding("resource-near-limit", "WARN", resName,
+			fmt.Sprintf("CPU usage %s is within 20%% of the limit %s — close to the CPU limit; throttling likely under load", formatCPU(usage.CPU), a.CPULimit),
+			"Raise or remove the CPU limit")
+	}
+
+	missing := a.CPULimit == "" || a.MemoryLimit == ""
+	if !missing && !tight {
 		a.logPass("Resource limits configured")
+		return
+	}
+	if a.DeploymentType == "" {
+		return
+	}
+	if missing {
+		a.logWarn("Resource limits not set — may impact cluster stability")
+	}
+
+	rec := recommendResources(usage, cur)
+	a.logInfo(fmt.Sprintf("Recommended (indicative only, from a single usage sample): requests cpu=%s memory=%s, limits cpu=%s memory=%s",
+		formatCPU(rec.CPURequest), formatMemory(rec.MemRequest), formatCPU(rec.CPULimit), formatMemory(rec.MemLimit)))
+
+	ns, rt, name := a.Namespace, a.controllerResType(), a.ControllerName
+	container := getString(a.controllerContainer(), "name")
+	command := fmt.Sprintf("kubectl set resources %s %s -n %s -c %s --limits=cpu=%s,memory=%s --requests=cpu=%s,memory=%s",
+		rt, name, ns, container, formatCPU(rec.CPULimit), formatMemory(rec.MemLimit),
+		formatCPU(rec.CPURequest), formatMemory(rec.MemRequest))
+	resourceValues := func(cpuReq, cpuLim, memReq, memLim string) map[string]string {
+		return map[string]string{
+			"controller.resources.requests.cpu":    cpuReq,
+			"controller.resources.limits.cpu":      cpuLim,
+			"controller.resources.requests.memory": memReq,
+			"controller.resources.limits.memory":   memLim,
+		}
+	}
+	if a.helmFixUnavailable("resource-limits") {
+		return
+	}
+	if a.HelmRelease != "" {
+		command = "helm " + shellJoin(a.helmUpgradeArgs(resourceValues(formatCPU(rec.CPURequest),
+			formatCPU(rec.CPULimit), formatMemory(rec.MemRequest), formatMemory(rec.MemLimit))))
+	}
+	a.addFix("resource-limits", "WARNING",
+		"Set usage-based resource requests/limits on ingress-nginx-controller (values can be edited before applying)",
+		command,
+		func() error {
+			cpuReq := promptUser("CPU request", formatCPU(rec.CPURequest))
+			cpuLim := promptUser("CPU limit", formatCPU(rec.CPULimit))
+			memReq := promptUser("Memory request", formatMemory(rec.MemRequest))
+			memLim := promptUser("Memory limit", formatMemory(rec.MemLimit))
+			for _, q := range []string{cpuReq, cpuLim} {
+				if _, ok := parseCPU(q); !ok {
+					return fmt.Errorf("invalid CPU quantity %q", q)
+				}
+			}
+			for _, q := range []string{memReq, memLim} {
+				if _, ok := parseMemory(q); !ok {
+					return fmt.Errorf("invalid memory quantity %q", q)
+				}
+			}
+			if a.HelmRelease != "" {
+				return runCmd("helm", a.helmUpgradeArgs(resourceValues(cpuReq, cpuLim, memReq, memLim))...)
+			}
+			return runCmd("kubectl", "set", "resources", rt, name, "-n", ns, "-c", container,
+				"--limits=cpu="+cpuLim+",memory="+memLim,
+				"--requests=cpu="+cpuReq+",memory="+memReq)
+		})
+}
+
+// observeControllerUsage returns the highest current CPU/memory usage of the
+// controller container across pods from metrics.k8s.io, plus restart and
+// OOMKilled history from pod status. ok is false when no metrics were found.
+func (a *AuditState) observeControllerUsage() (u resourceUsage, ok bool) {
+	selector := a.controllerPodSelector()
+	container := getString(a.controllerContainer(), "name")
+
+	path := fmt.Sprintf("/apis/metrics.k8s.io/v1beta1/namespaces/%s/pods?labelSelector=%s",
+		a.Namespace, url.QueryEscape(selector))
+	if out, err := kubectl("get", "--raw", path); err == nil {
+		var metrics map[string]interface{}
+		if json.Unmarshal([]byte(out), &metrics) == nil {
+			for _, item := range getSlice(metrics, "items") {
+				im, _ := item.(map[string]interface{})
+				for _, c := range getSlice(im, "containers") {
+					cm, _ := c.(map[string]interface{})
+					if getString(cm, "name") != container {
+						continue
+					}
+					usage := getMap(cm, "usage")
+					if cpu, valid := parseCPU(getString(usage, "cpu")); valid {
+						u.CPU, ok = max(u.CPU, cpu), true
+					}
+					if mem, valid := parseMemory(getString(usage, "memory")); valid {
+						u.Mem, ok = max(u.Mem, mem), true
+					}
+				}
+			}
+		}
+	}
+
+	if pods, err := kubectlJSON("get", "pods", "-n", a.Namespace, "-l", selector); err == nil {
+		for _, p := range getSlice(pods, "items") {
+			pm, _ := p.(map[string]interface{})
+			for _, cs := range getSlice(getMap(pm, "status"), "containerStatuses") {
+				csm, _ := cs.(map[string]interface{})
+				if getString(csm, "name") != container {
+					continue
+				}
+				n, _ := toInt(csm["restartCount"])
+				u.Restarts += n
+				if getString(getMap(getMap(csm, "lastState"), "terminated"), "reason") == "OOMKilled" {
+					u.OOMKilled = true
+				}
+			}
+		}
 	}
+	return u, ok
 }
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // PHASE 9 -- Ingress Resources Audit
@@ -23,6 +25,7 @@ func (a *AuditState) auditIngressResources() {
 	ingressJSON, _ := kubectl("get", "ingress", "-A", "-o", "json")
 	nginxCount := 0
 	snippetNames := []string{}
+	var nginxIngresses []map[string]interface{}
 	tlsCount := 0
 
 	if ingressJSON != "" {
@@ -38,12 +41,14 @@ func (a *AuditState) auditIngressResources() {
 				annClass := fmt.Sprintf("%v", anns["kubernetes.io/ingress.class"])
 				if ingressClass == "nginx" || annClass == "nginx" {
 					nginxCount++
+					nginxIngresses = append(nginxIngresses, im)
 					ns := fmt.Sprintf("%v", meta["namespace"])
 					name := fmt.Sprintf("%v", meta["name"])
-					// check snippets
+					// check snippets (once per Ingress)
 					for k := range anns {
-						if strings.Contains(k, "snippet") {
+						if strings.HasPrefix(k, nginxAnnotationPrefix) && strings.HasSuffix(k, "-snippet") {
 							snippetNames = append(snippetNames, ns+"/"+name)
+							break
 						}
 					}
 					// check TLS
@@ -73,6 +78,18 @@ func (a *AuditState) auditIngressResources() {
 		a.logPass("No Ingress resources using snippet annotations")
 	}
 
+	// -- Snippet directives
+	a.printSection("Snippet Directive Inspection")
+	a.auditIngressSnippets(nginxIngresses)
+
+	// -- Snippet conversion
+	a.printSection("Snippet Conversion")
+	a.auditSnippetConversion(nginxIngresses)
+
+	// -- Annotation risk
+	a.printSection("Annotation Risk Analysis")
+	a.auditIngressAnnotations(nginxIngresses)
+
 	// -- TLS coverage
 	a.printSection("TLS Configuration")
 	a.logStep("Checking TLS coverage...")
@@ -83,4 +100,198 @@ func (a *AuditState) auditIngressResources() {
 	} else if nginxCount > 0 {
 		a.logPass("All Ingress resources configured with TLS")
 	}
+	a.auditIngressTLS(nginxIngresses)
+}
+
+// auditIngressTLS checks the TLS secret of every spec.tls entry and the
+// certificate coverage of every host. Secrets are fetched once each.
+func (a *AuditState) auditIngressTLS(ingresses []map[string]interface{}) {
+	a.logStep("Checking Ingress TLS secrets...")
+	secrets := map[string]map[string]interface{}{}
+	fetched := map[string]bool{}
+	for _, ing := range ingresses {
+		ns := getString(getMap(ing, "metadata"), "namespace")
+		for _, t := range getSlice(getMap(ing, "spec"), "tls") {
+			name := getString(asMap(t), "secretName")
+			if name == "" {
+				continue
+			}
+			if fetched[ns+"/"+name] {
+				continue
+			}
+			fetched[ns+"/"+name] = true
+			out, stderr, err := kubectlE("get", "secret", "-n", ns, name, "-o", "json")
+			if err != nil && strings.Contains(stderr, "NotFound") {
+				continue
+			}
+			var s map[string]interface{}
+			if err == nil {
+				err = json.Unmarshal([]byte(out), &s)
+			}
+			if err != nil {
+				a.logInfo(fmt.Sprintf("kubectl get secret %s/%s: %s", ns, name, orDefault(stderr)))
+				s = nil
+			}
+			secrets[ns+"/"+name] = s
+		}
+	}
+	if len(fetched) == 0 {
+		return
+	}
+	roots, _ := x509.SystemCertPool()
+	clean := true
+	for _, ing := range ingresses {
+		for _, f := range evaluateIngressTLS(ing, secrets, roots, time.Now()) {
+			a.recordFinding(f)
+			clean = false
+		}
+	}
+	if clean {
+		a.logPass(fmt.Sprintf("%d TLS secrets valid, matching their keys and covering every host", len(fetched)))
+	}
+}
+
+// auditIngressAnnotations classifies the ingress-nginx annotations of every
+// NGINX-class Ingress against the effective annotations-risk-level.
+func (a *AuditState) auditIngressAnnotations(ingresses []map[string]interface{}) {
+	riskLevel, _ := effectiveConfig(a.controllerConfigMap(), "annotations-risk-level", a.ControllerVersion)
+	if riskLevel != "" {
+		a.logInfo(fmt.Sprintf("annotations-risk-level: %s", riskLevel))
+	} else {
+		a.logInfo(fmt.Sprintf("annotations-risk-level: not enforced by %s", a.ControllerVersion))
+	}
+	counts := map[string]int{}
+	clean := true
+	for _, ing := range ingresses {
+		risky, findings := evaluateIngressAnnotations(ing, riskLevel)
+		for _, r := range risky {
+			counts[r.Risk]++
+		}
+		for _, f := range findings {
+			a.recordFinding(f)
+			clean = false
+		}
+	}
+	a.logInfo(fmt.Sprintf("Annotations by risk: Critical %d, High %d, Medium %d",
+		counts[riskCritical], counts[riskHigh], counts[riskMedium]))
+	if clean {
+		a.logPass("No high-risk, rejected or invalid annotations on NGINX Ingress resources")
+	}
+}
+
+// auditIngressSnippets parses the snippet annotations of every NGINX-class
+// Ingress and records the dangerous directives they contain.
+func (a *AuditState) auditIngressSnippets(ingresses []map[string]interface{}) {
+	a.logStep("Parsing snippet annotations as NGINX directives...")
+	parsed, clean := 0, true
+	for _, ing := range ingresses {
+		meta := getMap(ing, "metadata")
+		res := fmt.Sprintf("ingress/%s/%s", getString(meta, "namespace"), getString(meta, "name"))
+		for _, s := range ingressSnippets(getStringMap(meta, "annotations")) {
+			parsed++
+			for _, f := range inspectSnippet(s[0], s[1], res) {
+				a.recordFinding(f)
+				clean = false
+			}
+		}
+	}
+	switch {
+	case parsed == 0:
+		a.logInfo("No snippet annotations to inspect")
+	case clean:
+		a.logPass(fmt.Sprintf("%d snippet annotations contain no dangerous directives", parsed))
+	}
+}
+
+// auditSnippetConversion proposes first-class annotations and ConfigMaps
+// that replace the snippets of each Ingress, so allow-snippet-annotations
+// can be switched off. Ingresses converted completely get a fix that applies
+// the conversion and removes their snippets.
+func (a *AuditState) auditSnippetConversion(ingresses []map[string]interface{}) {
+	zones := limitReqZones(a.controllerConfigMap()["http-snippet"])
+	total, complete := 0, 0
+	allowed := map[string]bool{}
+	// The Ingress patches are registered after the ConfigMap fix that allows
+	// their custom headers, so they are applied in that order.
+	var conversions []func()
+	for _, ing := range ingresses {
+		meta := getMap(ing, "metadata")
+		ns, name := getString(meta, "namespace"), getString(meta, "name")
+		anns := getStringMap(meta, "annotations")
+		if len(ingressSnippets(anns)) == 0 {
+			continue
+		}
+		total++
+		conv := convertIngressSnippets(anns, zones)
+		a.writeln(fmt.Sprintf("    %s/%s:", ns, name))
+		for _, k := range conv.annotationNames() {
+			a.writeln(fmt.Sprintf("      %s%s: %q", nginxAnnotationPrefix, k, conv.Annotations[k]))
+		}
+		var manifest string
+		if len(conv.Headers) > 0 {
+			a.writeln(fmt.Sprintf("      %scustom-headers: %q", nginxAnnotationPrefix, ns+"/"+customHeadersConfigMap(name)))
+			manifest = renderHeadersConfigMap(ns, customHeadersConfigMap(name), conv.Headers)
+			for _, line := range strings.Split(strings.TrimRight(manifest, "\n"), "\n") {
+				a.writeln("      " + line)
+			}
+		}
+		if len(conv.UpstreamHeaders) > 0 {
+			a.logWarn(fmt.Sprintf("%s/%s: proxy_set_header needs the global proxy-set-headers ConfigMap (applies to every Ingress):", ns, name))
+			upstream := renderHeadersConfigMap(a.Namespace, "custom-upstream-headers", conv.UpstreamHeaders)
+			for _, line := range strings.Split(strings.TrimRight(upstream, "\n"), "\n") {
+				a.writeln("      " + line)
+			}
+			a.writeln(fmt.Sprintf("      # controller ConfigMap: proxy-set-headers: %s/custom-upstream-headers", a.Namespace))
+		}
+		for _, u := range conv.Unconverted {
+			a.writeln("      ✗ " + u)
+		}
+		if !conv.Complete() || len(conv.UpstreamHeaders) > 0 {
+			a.logInfo(fmt.Sprintf("%s/%s: %d snippet directives need manual migration", ns, name, len(conv.Unconverted)))
+			continue
+		}
+		complete++
+		for h := range conv.Headers {
+			allowed[h] = true
+		}
+		patch := conversionPatch(ns, name, conv, anns)
+		command := fmt.Sprintf("kubectl patch ingress %s -n %s --type merge -p '%s'", name, ns, patch)
+		if manifest != "" {
+			command = "kubectl apply -f - (custom-headers ConfigMap shown above) && " + command
+		}
+		conversions = append(conversions, func() {
+			a.addFix("snippet-convert", "WARNING",
+				fmt.Sprintf("Replace the snippets of Ingress %s/%s with annotations", ns, name), command,
+				func() error {
+					if manifest != "" {
+						if err := runCmdInput(manifest, "kubectl", "apply", "-f", "-"); err != nil {
+							return err
+						}
+					}
+					return runCmd("kubectl", "patch", "ingress", name, "-n", ns, "--type", "merge", "-p", patch)
+				})
+		})
+	}
+	if total == 0 {
+		a.logPass("No snippet annotations to convert")
+		return
+	}
+	a.logInfo(fmt.Sprintf("%d of %d Ingress resources with snippets convert completely to annotations", complete, total))
+	if len(allowed) > 0 && versionAtLeast(a.ControllerVersion, "v1.12.0") {
+		current := a.controllerConfigMap()["global-allowed-response-headers"]
+		for _, h := range strings.Split(current, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				allowed[h] = true
+			}
+		}
+		a.addConfigMapFix("snippet-convert-allowed-headers", "WARNING",
+			"Allow the converted custom-headers in global-allowed-response-headers",
+			map[string]string{"global-allowed-response-headers": strings.Join(sortedKeys(allowed), ",")})
+	}
+	for _, register := range conversions {
+		register()
+	}
+	if complete == total && a.AllowSnippets == "true" {
+		a.logInfo("Once the conversions are applied, allow-snippet-annotations can be set to false")
+	}
 }
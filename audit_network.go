@@ -1,8 +1,8 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 )
 
@@ -40,49 +40,474 @@ func (a *AuditState) auditNetworkSecurity() {
 		case "ClusterIP":
 			a.logWarn("Controller is ClusterIP — confirm external access is handled elsewhere")
 		}
+		if ctrlSvcType == "LoadBalancer" || ctrlSvcType == "NodePort" {
+			a.auditControllerService(ctrlSvcType)
+		}
 	}
 
 	// ── NetworkPolicy check ──────────────────────────
 	a.printSection("Network Policy Check")
 	a.logStep("Checking for NetworkPolicies...")
 
-	npOut, _ := kubectl("get", "networkpolicies", "-n", a.Namespace, "--no-headers")
-	npLines := nonEmpty(strings.Split(npOut, "\n"))
-	a.NpCount = len(npLines)
-	if a.NpCount > 0 {
-		a.logPass(fmt.Sprintf("Found %d NetworkPolicy resource(s)", a.NpCount))
-		for _, l := range npLines {
-			a.writeln(fmt.Sprintf("    %s", l))
-		}
+	npList, _ := kubectlJSON("get", "networkpolicies", "-n", a.Namespace)
+	policies := getSlice(npList, "items")
+	a.NpCount = len(policies)
+	a.logInfo(fmt.Sprintf("NetworkPolicies in namespace: %d", a.NpCount))
+
+	podLabels := a.controllerPodLabels()
+	if len(podLabels) == 0 {
+		a.logWarn("Could not read controller pod labels — NetworkPolicy selection not evaluated")
 	} else {
-		a.logWarn("No NetworkPolicies found — consider adding for defense-in-depth")
+		a.logStep("Evaluating which policies select the controller pods...")
+		acc := newPolicyAccumulator(controllerPolicyPorts(a.controllerContainerPorts()))
+		acc.addNetworkPolicies(policies, podLabels, a.Namespace)
+		cni := a.addCNIPolicies(acc, podLabels)
+		a.NetPol = acc.result()
+		a.NetPol.CNI = cni
+		a.reportNetworkPolicy()
+		if !a.NetPol.IngressIsolated {
+			a.offerControllerNetworkPolicy(podLabels)
+		}
 	}
 
 	// ── Cluster-wide external services ───────────────
 	a.printSection("All External Services (Cluster-wide)")
-	a.logStep("Scanning for LoadBalancer/NodePort services...")
+	a.auditExternalServices()
+}
+
+// reportNetworkPolicy logs the effective sources allowed to reach each
+// controller port according to a.NetPol.
+func (a *AuditState) reportNetworkPolicy() {
+	ev := a.NetPol
+	if len(ev.SelectingPolicies) == 0 {
+		switch {
+		case a.NpCount > 0:
+			a.logWarn(fmt.Sprintf("None of the %d NetworkPolicy resource(s) select the controller pods", a.NpCount))
+		case ev.CNI != "":
+			a.logWarn(fmt.Sprintf("No NetworkPolicy or %s policy selects the controller pods — consider adding for defense-in-depth", ev.CNI))
+		default:
+			a.logWarn("No NetworkPolicies found — consider adding for defense-in-depth")
+		}
+	} else {
+		a.logInfo(fmt.Sprintf("Policies selecting the controller: %s", strings.Join(ev.SelectingPolicies, ", ")))
+		if len(ev.Engines) > 0 {
+			a.logInfo(fmt.Sprintf("Ingress isolation supplied by: %s", strings.Join(ev.Engines, ", ")))
+		}
+	}
+
+	a.writeln("\n  Effective ingress sources per port:")
+	for _, p := range ev.Ports {
+		srcs := "none (denied)"
+		if len(p.Sources) > 0 {
+			srcs = strings.Join(p.Sources, "; ")
+		}
+		if len(p.Engines) > 0 && p.Isolated {
+			srcs += fmt.Sprintf("  [%s]", strings.Join(p.Engines, ", "))
+		}
+		a.writeln(fmt.Sprintf("    %-8s %-6d %s", p.Name, p.Port, srcs))
+	}
+	a.writeln("")
+
+	for _, p := range ev.Ports {
+		switch p.Name {
+		case "webhook":
+			switch {
+			case p.Anywhere:
+				a.logWarn(fmt.Sprintf("Webhook port %d is reachable from any source — restrict it to the API server", p.Port))
+			case p.ClusterWide:
+				a.logWarn(fmt.Sprintf("Webhook port %d is reachable from every pod in the cluster — restrict it to the API server", p.Port))
+			case p.Selectors:
+				a.logWarn(fmt.Sprintf("Webhook port %d admits workloads chosen by pod/namespace selectors (%s) — allow only the API server or node CIDRs",
+					p.Port, strings.Join(p.Sources, "; ")))
+			case len(p.Sources) == 0:
+				a.logWarn(fmt.Sprintf("Webhook port %d is denied to all sources — the API server may be unable to call the admission webhook", p.Port))
+			default:
+				a.logPass(fmt.Sprintf("Webhook port %d limited to: %s", p.Port, strings.Join(p.Sources, "; ")))
+			}
+		case "metrics":
+			switch {
+			case p.Anywhere:
+				a.logWarn(fmt.Sprintf("Metrics port %d is reachable from any source — restrict it to the monitoring namespace", p.Port))
+			case p.ClusterWide:
+				a.logWarn(fmt.Sprintf("Metrics port %d is reachable from every pod in the cluster — restrict it to the monitoring namespace", p.Port))
+			case len(p.Sources) == 0:
+				a.logInfo(fmt.Sprintf("Metrics port %d is denied to all sources (not scrapeable)", p.Port))
+			default:
+				a.logPass(fmt.Sprintf("Metrics port %d limited to: %s", p.Port, strings.Join(p.Sources, "; ")))
+			}
+		default:
+			if p.Isolated && len(p.Sources) == 0 {
+				a.logWarn(fmt.Sprintf("%s port %d is denied to all sources — ingress traffic will be dropped",
+					strings.ToUpper(p.Name), p.Port))
+			}
+		}
+	}
+
+	if ev.EgressRestricted {
+		a.logPass("Controller egress is restricted by NetworkPolicy")
+	} else {
+		a.logWarn("Controller egress is unrestricted — a compromised controller can reach any destination")
+	}
+}
+
+// controlPlaneCIDRs returns the CIDRs the API server calls webhooks from:
+// the --control-plane-cidr flag when set, otherwise one host route per
+// address of the default/kubernetes endpoints.
+func (a *AuditState) controlPlaneCIDRs() []string {
+	if a.ControlPlaneCIDR != "" {
+		var out []string
+		for _, c := range strings.Split(a.ControlPlaneCIDR, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+	ips, _ := kubectl("get", "endpoints", "kubernetes", "-n", "default",
+		"-o", "jsonpath={.subsets[*].addresses[*].ip}")
+	var out []string
+	for _, ip := range strings.Fields(ips) {
+		if strings.Contains(ip, ":") {
+			out = append(out, ip+"/128")
+		} else {
+			out = append(out, ip+"/32")
+		}
+	}
+	return out
+}
+
+// offerControllerNetworkPolicy prints a generated least-privilege
+// NetworkPolicy and registers a fix that applies it after a server-side
+// dry-run. The fix is only offered with an explicit --control-plane-cidr:
+// behind konnectivity or SNAT (GKE, AKS, ...) webhook calls do not come from
+// the kubernetes endpoint addresses, and no dry-run can tell.
+func (a *AuditState) offerControllerNetworkPolicy(podLabels map[string]string) {
+	a.printSection("Generated Least-Privilege NetworkPolicy")
+	ports := controllerPolicyPorts(a.controllerContainerPorts())
+	whPort := webhookPort
+	for _, p := range ports {
+		if p.name == "webhook" {
+			whPort = p.port
+		}
+	}
+	cidrs := a.controlPlaneCIDRs()
+	switch {
+	case len(cidrs) == 0:
+		a.logWarn("Could not detect the control-plane CIDR — set --control-plane-cidr to allow webhook traffic")
+	case a.ControlPlaneCIDR == "":
+		a.logWarn(fmt.Sprintf("Control-plane CIDR(s) for webhook port %d guessed from the kubernetes endpoints: %s — "+
+			"managed control planes (konnectivity, SNAT) call webhooks from other addresses", whPort, strings.Join(cidrs, ", ")))
+	default:
+		a.logInfo(fmt.Sprintf("Control-plane CIDR(s) for webhook port %d: %s", whPort, strings.Join(cidrs, ", ")))
+	}
+	a.logInfo(fmt.Sprintf("Metrics scrapers namespace: %s", orDefault(a.MonitoringNamespace)))
+
+	manifest := buildControllerNetworkPolicy(a.Namespace, podLabels, ports, cidrs, a.MonitoringNamespace)
+	for _, line := range strings.Split(strings.TrimRight(manifest, "\n"), "\n") {
+		a.writeln("    " + line)
+	}
+	if a.ControlPlaneCIDR == "" {
+		a.logInfo("Not offered as an auto-fix: pass --control-plane-cidr with the addresses the API server calls webhooks from; " +
+			"a wrong CIDR silently breaks admission")
+		return
+	}
+	a.addFix("controller-networkpolicy", "WARNING",
+		"Apply least-privilege NetworkPolicy for the ingress-nginx controller pods",
+		"kubectl apply --dry-run=server -f - && kubectl apply -f -  (generated NetworkPolicy shown above)",
+		func() error {
+			fmt.Printf("  %s→%s Server-side dry-run...\n", Cyan, Reset)
+			if err := runCmdInput(manifest, "kubectl", "apply", "--dry-run=server", "-f", "-"); err != nil {
+				return fmt.Errorf("server-side dry-run rejected the NetworkPolicy: %w", err)
+			}
+			return runCmdInput(manifest, "kubectl", "apply", "-f", "-")
+		})
+}
+
+// auditControllerService checks the hardening of an externally published
+// controller Service. Every problem is recorded as its own finding.
+func (a *AuditState) auditControllerService(svcType string) {
+	a.printSection("Controller Service Hardening")
+	svc, err := kubectlJSON("get", "svc", "-n", a.Namespace, a.ControllerName)
+	if err != nil {
+		a.logWarn("Could not read controller service spec")
+		return
+	}
+	spec := getMap(svc, "spec")
+	res := fmt.Sprintf("service/%s/%s", a.Namespace, a.ControllerName)
+	patch := fmt.Sprintf("kubectl patch svc %s -n %s -p ", a.ControllerName, a.Namespace)
+	cm := a.controllerConfigMap()
+
+	// ── Source ranges ──
+	if svcType == "LoadBalancer" {
+		a.logStep("Checking loadBalancerSourceRanges...")
+		var ranges []string
+		for _, r := range getSlice(spec, "loadBalancerSourceRanges") {
+			ranges = append(ranges, fmt.Sprintf("%v", r))
+		}
+		annRanges := getStringMap(getMap(svc, "metadata"), "annotations")["service.beta.kubernetes.io/load-balancer-source-ranges"]
+		switch {
+		case len(ranges) == 0 && annRanges == "":
+			a.addFinding("lb-source-ranges", "INFO", res,
+				"LoadBalancer accepts traffic from any source (no loadBalancerSourceRanges)",
+				"If this controller is not meant to be public, set spec.loadBalancerSourceRanges: "+
+					patch+`'{"spec":{"loadBalancerSourceRanges":["<cidr>"]}}'`)
+		default:
+			if annRanges != "" {
+				ranges = append(ranges, strings.Split(annRanges, ",")...)
+			}
+			a.logPass(fmt.Sprintf("LoadBalancer source ranges: %s", strings.Join(ranges, ", ")))
+		}
+	}
+
+	// ── Client IP preservation ──
+	a.logStep("Checking externalTrafficPolicy...")
+	etp := getString(spec, "externalTrafficPolicy")
+	if etp == "" {
+		etp = "Cluster"
+	}
+	a.logInfo(fmt.Sprintf("externalTrafficPolicy: %s", etp))
+	forwarded := cm["use-forwarded-headers"] == "true"
+	proxyProto := cm["use-proxy-protocol"] == "true"
+	realIPCIDR := cm["proxy-real-ip-cidr"]
+	switch {
+	case etp == "Cluster" && !forwarded && !proxyProto:
+		a.addFinding("svc-external-traffic-policy", "WARN", res,
+			"externalTrafficPolicy is Cluster — client source IPs are replaced by node IPs (logs, allow/deny lists and rate limits see the wrong address)",
+			patch+`'{"spec":{"externalTrafficPolicy":"Local"}}'`+
+				" — or enable use-forwarded-headers/use-proxy-protocol when an L7/PROXY-capable load balancer fronts the controller")
+	case etp == "Local" && forwarded && (realIPCIDR == "" || realIPCIDR == "0.0.0.0/0"):
+		a.addFinding("svc-forwarded-headers-spoofable", "WARN", res,
+			"externalTrafficPolicy is Local and use-forwarded-headers is true — clients reach the controller directly and can spoof X-Forwarded-For",
+			"Set use-forwarded-headers to \"false\", or restrict proxy-real-ip-cidr to the load balancer's addresses")
+	default:
+		a.logPass("Client source IP handling is consistent with the ConfigMap")
+	}
+
+	// ── Published ports ──
+	a.logStep("Checking published ports...")
+	declared := a.controllerContainerPorts()
+	numToName := map[int]string{}
+	for name, n := range declared {
+		numToName[n] = name
+	}
+	extra := 0
+	for _, p := range getSlice(spec, "ports") {
+		pm, _ := p.(map[string]interface{})
+		port, _ := toInt(pm["port"])
+		name := getString(pm, "name")
+		target := targetPortName(pm, numToName)
 
-	svcJSON, _ := kubectl("get", "svc", "-A", "-o", "json")
-	if svcJSON != "" {
-		var svcList map[string]interface{}
-		if json.Unmarshal([]byte(svcJSON), &svcList) == nil {
-			items, _ := svcList["items"].([]interface{})
-			found := 0
-			for _, item := range items {
-				im, _ := item.(map[string]interface{})
-				meta := getMap(im, "metadata")
-				spec := getMap(im, "spec")
-				t := fmt.Sprintf("%v", spec["type"])
-				if t == "LoadBalancer" || t == "NodePort" {
-					ns := fmt.Sprintf("%v", meta["namespace"])
-					name := fmt.Sprintf("%v", meta["name"])
-					a.logInfo(fmt.Sprintf("  %s/%s (%s)", ns, name, t))
-					found++
-				}
+		if name == "http" || name == "https" || port == 80 || port == 443 {
+			if target != "" && target != "http" && target != "https" {
+				a.addFinding("svc-port-target", "WARN", res,
+					fmt.Sprintf("Service port %d (%s) targets container port %q, not http/https", port, name, target),
+					"Point the http/https service ports at the controller's http/https container ports")
 			}
-			if found == 0 {
-				a.logInfo("No LoadBalancer or NodePort services found")
+		} else {
+			extra++
+			label := fmt.Sprintf("%d/%s", port, orDefault(name))
+			switch {
+			case target == "webhook" || port == webhookPort:
+				a.addFinding("svc-extra-port-webhook", "FAIL", res,
+					fmt.Sprintf("Admission webhook port %s is published on the %s service", label, svcType),
+					fmt.Sprintf("Remove port %s from the service; the webhook must only be reachable through the ClusterIP admission service", label))
+			case target == "metrics" || port == metricsPort:
+				a.addFinding("svc-extra-port-metrics", "WARN", res,
+					fmt.Sprintf("Metrics/healthz port %s is published on the %s service", label, svcType),
+					"Remove the port and scrape metrics through a separate ClusterIP metrics service")
+			default:
+				a.addFinding("svc-extra-port", "WARN", res,
+					fmt.Sprintf("Additional port %s is published on the %s service", label, svcType),
+					"Publish only 80/443 unless TCP/UDP services are intentionally exposed")
 			}
 		}
+
+		if target == "" && len(declared) > 0 {
+			a.addFinding("svc-port-mismatch", "WARN", res,
+				fmt.Sprintf("Service port %d (%s) targets %v, which is not a controller container port",
+					port, orDefault(name), pm["targetPort"]),
+				"Align the service targetPort with a port declared on the controller container")
+		}
+	}
+	if extra == 0 {
+		a.logPass("Only HTTP/HTTPS ports are published")
+	}
+
+	if svcType == "LoadBalancer" {
+		a.auditLBAnnotations(getStringMap(getMap(svc, "metadata"), "annotations"), getString(getMap(svc, "spec"), "loadBalancerClass"), res)
+	}
+
+	// ── Node ports ──
+	if svcType == "LoadBalancer" {
+		a.logStep("Checking allocateLoadBalancerNodePorts...")
+		if alloc, ok := spec["allocateLoadBalancerNodePorts"].(bool); ok && !alloc {
+			a.logPass("LoadBalancer does not allocate NodePorts")
+		} else {
+			a.addFinding("lb-node-ports", "INFO", res,
+				"LoadBalancer allocates NodePorts — the controller is also reachable on every node's IP",
+				"If the load balancer routes to pod IPs directly, set "+
+					patch+`'{"spec":{"allocateLoadBalancerNodePorts":false}}'`)
+		}
+	}
+}
+
+// targetPortName resolves a service port's targetPort to the name of the
+// controller container port it selects. It returns "" when no container
+// port matches.
+func targetPortName(svcPort map[string]interface{}, numToName map[int]string) string {
+	switch v := svcPort["targetPort"].(type) {
+	case string:
+		for _, name := range numToName {
+			if name == v {
+				return v
+			}
+		}
+		return ""
+	case nil:
+		port, _ := toInt(svcPort["port"])
+		return numToName[port]
+	default:
+		n, _ := toInt(v)
+		return numToName[n]
+	}
+}
+
+// auditLBAnnotations detects the cloud provider and checks the controller
+// Service's load balancer annotations against it and the ConfigMap.
+func (a *AuditState) auditLBAnnotations(ann map[string]string, lbClass, res string) {
+	a.logStep("Detecting cloud provider from node providerID...")
+	ids, _ := kubectl("get", "nodes", "-o", "jsonpath={.items[*].spec.providerID}")
+	a.CloudProvider = detectCloudProvider(strings.Fields(ids))
+	if a.CloudProvider == "" {
+		a.logInfo("Cloud provider not detected — load balancer annotations not audited")
+		return
+	}
+	a.logInfo(fmt.Sprintf("Cloud provider: %s", a.CloudProvider))
+
+	wantInternal := wantsInternalLB(a.LBScheme, a.ControllerName, a.Namespace)
+	scheme, findings := evaluateLBAnnotations(a.CloudProvider, ann, lbClass, a.controllerConfigMap(), wantInternal, a.LBScheme == "", res)
+	intent := "internet-facing"
+	if wantInternal {
+		intent = "internal"
+	}
+	a.logInfo(fmt.Sprintf("Load balancer scheme: %s (expected: %s)", scheme, intent))
+	for _, f := range findings {
+		a.recordFinding(f)
+	}
+	if len(findings) == 0 {
+		a.logPass("Load balancer annotations are consistent with the provider and ConfigMap")
+	}
+}
+
+// auditExternalServices builds the cluster-wide inventory of LoadBalancer and
+// NodePort services and checks it against the --service-allowlist file.
+func (a *AuditState) auditExternalServices() {
+	var patterns []string
+	if a.ServiceAllowlistFile != "" {
+		content, err := os.ReadFile(a.ServiceAllowlistFile)
+		if err != nil {
+			a.logWarn(fmt.Sprintf("Cannot read service allowlist %s: %v", a.ServiceAllowlistFile, err))
+		} else {
+			patterns = parseAllowlist(string(content))
+			a.logInfo(fmt.Sprintf("Loaded %d allowlist entries from %s", len(patterns), a.ServiceAllowlistFile))
+		}
+	}
+	// The controller's own service is expected to be external.
+	patterns = append(patterns, a.Namespace+"/"+a.ControllerName)
+
+	a.logStep("Scanning for LoadBalancer/NodePort services...")
+	svcList, err := kubectlJSON("get", "svc", "-A")
+	if err != nil {
+		a.logWarn("Could not list services cluster-wide")
+		return
+	}
+	a.ExternalServices = nil
+	for _, item := range getSlice(svcList, "items") {
+		im, _ := item.(map[string]interface{})
+		if es, ok := buildExternalService(im, patterns); ok {
+			a.ExternalServices = append(a.ExternalServices, es)
+		}
+	}
+	if len(a.ExternalServices) == 0 {
+		a.logInfo("No LoadBalancer or NodePort services found")
+		return
+	}
+
+	a.writeln(fmt.Sprintf("\n    %-40s %-12s %-22s %s", "NAMESPACE/NAME", "TYPE", "ADDRESSES", "PORTS"))
+	for _, es := range a.ExternalServices {
+		addrs := strings.Join(es.Addresses, ",")
+		if addrs == "" {
+			addrs = "<nodes>"
+		}
+		a.writeln(fmt.Sprintf("    %-40s %-12s %-22s %s", es.Namespace+"/"+es.Name, es.Type, addrs, strings.Join(es.Ports, " ")))
+	}
+	a.writeln("")
+
+	unlisted := 0
+	for _, es := range a.ExternalServices {
+		res := fmt.Sprintf("service/%s/%s", es.Namespace, es.Name)
+		switch {
+		case es.SensitivePortOnly && !es.Allowlisted:
+			a.addFinding("external-service-sensitive-port", "WARN", res,
+				fmt.Sprintf("%s exposes the default port of %s via %s — verify the workload behind it", es.Namespace+"/"+es.Name, es.SensitiveTarget, es.Type),
+				"If it is that workload, change the service to ClusterIP; otherwise add it to the service allowlist")
+		case es.SensitiveTarget != "" && !es.Allowlisted:
+			a.addFinding("external-service-sensitive", "FAIL", res,
+				fmt.Sprintf("%s exposes %s via %s", es.Namespace+"/"+es.Name, es.SensitiveTarget, es.Type),
+				"Change the service to ClusterIP and reach it through a VPN, port-forward or authenticated proxy")
+		case es.SensitiveTarget != "":
+			a.logInfo(fmt.Sprintf("%s/%s exposes %s (allowlisted)", es.Namespace, es.Name, es.SensitiveTarget))
+		case !es.Allowlisted && a.ServiceAllowlistFile != "":
+			unlisted++
+			a.addFinding("external-service-unlisted", "WARN", res,
+				fmt.Sprintf("%s/%s (%s) is not on the service allowlist", es.Namespace, es.Name, es.Type),
+				fmt.Sprintf("Add %s/%s to %s if the exposure is intended, otherwise change it to ClusterIP",
+					es.Namespace, es.Name, a.ServiceAllowlistFile))
+		}
+	}
+	if a.ServiceAllowlistFile == "" {
+		a.logInfo(fmt.Sprintf("%d external service(s) found — pass --service-allowlist to flag unexpected ones", len(a.ExternalServices)))
+	} else if unlisted == 0 {
+		a.logPass("All external services are on the allowlist")
+	}
+}
+
+// addCNIPolicies detects Cilium or Calico and feeds their policy CRDs that
+// select the controller pods into acc. It returns the detected CNI.
+func (a *AuditState) addCNIPolicies(acc *policyAccumulator, podLabels map[string]string) string {
+	a.logStep("Detecting CNI policy engine...")
+	dsNames, _ := kubectl("get", "daemonsets", "-A", "-o", "jsonpath={.items[*].metadata.name}")
+	cni := detectCNI(strings.Fields(dsNames))
+	if cni == "" {
+		a.logInfo("No Cilium or Calico installation detected — only core NetworkPolicies evaluated")
+		return ""
+	}
+	a.logInfo(fmt.Sprintf("CNI policy engine: %s", cni))
+
+	switch cni {
+	case "cilium":
+		cnp, err1 := kubectlJSON("get", "ciliumnetworkpolicies.cilium.io", "-n", a.Namespace)
+		ccnp, err2 := kubectlJSON("get", "ciliumclusterwidenetworkpolicies.cilium.io")
+		if err1 != nil || err2 != nil {
+			a.logWarn("Could not read Cilium policies — check RBAC for cilium.io resources")
+		}
+		acc.addCiliumPolicies(getSlice(cnp, "items"), podLabels, a.Namespace, false)
+		acc.addCiliumPolicies(getSlice(ccnp, "items"), podLabels, a.Namespace, true)
+		a.logInfo(fmt.Sprintf("Cilium policies: %d namespaced, %d cluster-wide",
+			len(getSlice(cnp, "items")), len(getSlice(ccnp, "items"))))
+	case "calico":
+		np, err1 := kubectlJSON("get", "networkpolicies.crd.projectcalico.org", "-n", a.Namespace)
+		gnp, err2 := kubectlJSON("get", "globalnetworkpolicies.crd.projectcalico.org")
+		if err1 != nil || err2 != nil {
+			a.logWarn("Could not read Calico policies — check RBAC for crd.projectcalico.org resources")
+		}
+		skipped := acc.addCalicoPolicies(getSlice(np, "items"), podLabels, a.namespaceLabels(), a.Namespace, false)
+		skipped = append(skipped, acc.addCalicoPolicies(getSlice(gnp, "items"), podLabels, a.namespaceLabels(), a.Namespace, true)...)
+		a.logInfo(fmt.Sprintf("Calico policies: %d namespaced, %d global",
+			len(getSlice(np, "items")), len(getSlice(gnp, "items"))))
+		for _, name := range skipped {
+			a.logWarn(fmt.Sprintf("Selector of %s could not be evaluated — verify manually", name))
+		}
 	}
+	return cni
 }
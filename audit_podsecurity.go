@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -14,61 +15,237 @@ func (a *AuditState) auditPodSecurity() {
 
 	// ── Running pods ─────────────────────────────────
 	a.printSection("Running Pods")
-	a.logStep("Listing ingress-nginx pods...")
+	a.auditPodHealth()
+
+	// ── Security context ─────────────────────────────
+	a.printSection("Security Context")
+	a.auditSecurityContexts()
+
+	// ── Pod Security Standards ───────────────────────
+	a.printSection("Pod Security Standards")
+	a.auditPodSecurityStandards()
+
+	// ── ServiceAccount RBAC ──────────────────────────
+	a.printSection("ServiceAccount RBAC")
+	a.auditRBAC()
+}
+
+// auditPodHealth analyses the controller pods from their status objects and
+// prints a per-pod table.
+func (a *AuditState) auditPodHealth() {
+	a.logStep("Listing ingress-nginx controller pods...")
+	selector := a.controllerPodSelector()
+	if selector == "" {
+		selector = "app.kubernetes.io/name=ingress-nginx"
+	}
+	pods, _ := kubectlJSON("get", "pods", "-n", a.Namespace, "-l", selector)
+	rows, findings := evaluatePodHealth(getSlice(pods, "items"), a.Namespace, a.controllerResType(), a.ControllerName)
+	a.PodHealth = rows
 
-	podOut, _ := kubectl("get", "pods", "-n", a.Namespace,
-		"-l", "app.kubernetes.io/name=ingress-nginx", "--no-headers")
-	podLines := nonEmpty(strings.Split(podOut, "\n"))
-	podCount := len(podLines)
 	readyCount := 0
-	for _, l := range podLines {
-		if strings.Contains(l, "1/1") || strings.Contains(l, "Running") {
+	for _, h := range rows {
+		if h.PodReady {
 			readyCount++
 		}
 	}
-
-	a.logInfo(fmt.Sprintf("Total pods: %d", podCount))
+	a.logInfo(fmt.Sprintf("Total pods: %d", len(rows)))
 	a.logInfo(fmt.Sprintf("Ready pods: %d", readyCount))
-	switch {
-	case podCount > 0 && podCount == readyCount:
-		a.logPass("All ingress-nginx pods are ready")
-	case podCount > 0:
-		a.logWarn(fmt.Sprintf("Some pods not ready (%d/%d)", readyCount, podCount))
-	default:
+	if len(rows) == 0 {
 		a.logFail("No ingress-nginx pods found")
+		return
 	}
 
-	// ── Security context ─────────────────────────────
-	a.printSection("Security Context")
-	resType := strings.ToLower(a.DeploymentType)
-	if resType == "" {
-		resType = "deployment"
+	a.writeln(fmt.Sprintf("\n    %-48s %-7s %-18s %-9s %s", "POD", "READY", "STATUS", "RESTARTS", "NODE"))
+	for _, h := range rows {
+		a.writeln(fmt.Sprintf("    %-48s %-7s %-18s %-9d %s", h.Name, h.Ready, h.Status, h.Restarts, orDefault(h.Node)))
+		if h.LastTermination != "" {
+			a.writeln(fmt.Sprintf("      last termination: %s", h.LastTermination))
+		}
+	}
+	a.writeln("")
+
+	if len(findings) == 0 && readyCount == len(rows) {
+		a.logPass("All ingress-nginx pods are ready")
+	}
+	for _, f := range findings {
+		a.recordFinding(f)
+	}
+}
+
+// auditSecurityContexts checks every container of the controller pod,
+// including init containers, and offers a hardened securityContext for the
+// controller container.
+func (a *AuditState) auditSecurityContexts() {
+	podSpec := a.controllerPodSpec()
+	if len(getSlice(podSpec, "containers")) == 0 {
+		a.logWarn("Controller pod template not found — skipping securityContext checks")
+		return
+	}
+	res := fmt.Sprintf("%s/%s/%s", a.controllerResType(), a.Namespace, a.ControllerName)
+	controllerNeedsFix := false
+	for _, cs := range evaluateContainerSecurity(podSpec, a.ControllerVersion, res) {
+		label := cs.Name
+		if cs.Init {
+			label += " (init)"
+		}
+		a.logStep(fmt.Sprintf("Container %s...", label))
+		if len(cs.Findings) == 0 {
+			a.logPass(fmt.Sprintf("Container %s securityContext is hardened", label))
+			continue
+		}
+		for _, f := range cs.Findings {
+			a.recordFinding(f)
+			if !cs.Init && cs.Name == getString(a.controllerContainer(), "name") && f.ID != "sc-readonly-rootfs" && f.Level != "INFO" {
+				controllerNeedsFix = true
+			}
+		}
+	}
+	if !controllerNeedsFix || a.DeploymentType == "" {
+		return
 	}
 
-	a.logStep("Checking runAsNonRoot...")
-	runAsNonRoot, _ := kubectl("get", resType, "-n", a.Namespace, a.ControllerName,
-		"-o", "jsonpath={.spec.template.spec.securityContext.runAsNonRoot}")
-	runAsUser, _ := kubectl("get", resType, "-n", a.Namespace, a.ControllerName,
-		"-o", "jsonpath={.spec.template.spec.securityContext.runAsUser}")
+	desc := "Harden the controller container securityContext (non-root, drop ALL, RuntimeDefault seccomp)"
+	if a.helmFixUnavailable("container-security-context") {
+		return
+	}
+	if a.HelmRelease != "" {
+		args := a.helmUpgradeArgs(hardenedControllerHelmValues(a.ControllerVersion))
+		a.addFix("container-security-context", "WARNING", desc+" (Helm values)", "helm "+shellJoin(args),
+			func() error { return runCmd("helm", args...) })
+		return
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{
+				"name":            getString(a.controllerContainer(), "name"),
+				"securityContext": hardenedControllerSecurityContext(a.ControllerVersion),
+			}},
+		}}},
+	})
+	rt, ns, name := a.controllerResType(), a.Namespace, a.ControllerName
+	a.addFix("container-security-context", "WARNING", desc,
+		fmt.Sprintf("kubectl patch %s %s -n %s --type strategic -p '%s'", rt, name, ns, patch),
+		func() error {
+			return runCmd("kubectl", "patch", rt, name, "-n", ns, "--type", "strategic", "-p", string(patch))
+		})
+}
 
-	a.logInfo(fmt.Sprintf("runAsNonRoot: %s", orDefault(runAsNonRoot)))
-	a.logInfo(fmt.Sprintf("runAsUser:    %s", orDefault(runAsUser)))
+// auditPodSecurityStandards evaluates the controller pod template against the
+// baseline and restricted levels offline and compares the result with the
+// namespace's pod-security.kubernetes.io labels.
+func (a *AuditState) auditPodSecurityStandards() {
+	podSpec := a.controllerPodSpec()
+	if len(getSlice(podSpec, "containers")) == 0 {
+		a.logWarn("Controller pod template not found — skipping Pod Security Standards")
+		return
+	}
+	tmplMeta := getMap(getMap(getMap(a.controllerWorkload(), "spec"), "template"), "metadata")
+	r := evaluatePSS(podSpec, getStringMap(tmplMeta, "annotations"))
+	r.NamespaceLevels = namespacePSSLevels(a.namespaceLabels())
+	a.PSS = r
 
-	switch {
-	case runAsNonRoot == "true":
-		a.logPass("Running as non-root user")
-	case runAsUser != "" && runAsUser != "0":
-		a.logPass(fmt.Sprintf("Running as user %s (non-root)", runAsUser))
+	for _, mode := range []string{"enforce", "audit", "warn"} {
+		a.logInfo(fmt.Sprintf("Namespace %-8s %s", mode+":", orDefault(r.NamespaceLevels[mode])))
+	}
+	switch r.Met {
+	case pssRestricted:
+		a.logPass("Controller pod template meets the restricted Pod Security Standard")
+	case pssBaseline:
+		a.logInfo("Controller pod template meets baseline; blocking restricted:")
 	default:
-		a.logWarn("Security context should enforce non-root execution")
+		a.logWarn("Controller pod template does not meet baseline; blocking baseline:")
+	}
+	blocking := r.RestrictedViolations
+	if r.Met == pssPrivileged {
+		blocking = r.BaselineViolations
 	}
+	for _, v := range blocking {
+		a.writeln(fmt.Sprintf("      - %s", v))
+	}
+
+	findings := evaluatePSSNamespace(r, a.Namespace)
+	for _, f := range findings {
+		a.recordFinding(f)
+		if (f.ID == "pss-enforce-missing" || f.ID == "pss-enforce-below-met") && r.Met != pssPrivileged {
+			ns, level := a.Namespace, r.Met
+			label := "pod-security.kubernetes.io/enforce=" + level
+			a.addFix("pss-enforce-label", "WARNING",
+				fmt.Sprintf("Enforce the %s Pod Security Standard on namespace %s (applies to every pod in it)", level, ns),
+				fmt.Sprintf("kubectl label ns %s %s --overwrite", ns, label),
+				func() error {
+					// A server-side dry run warns about existing pods that would violate the level.
+					_, stderr, err := kubectlE("label", "ns", ns, label, "--overwrite", "--dry-run=server")
+					if err != nil {
+						return fmt.Errorf("server-side dry-run failed: %s", orDefault(stderr))
+					}
+					if v := pssDryRunViolations(stderr); len(v) > 0 {
+						return fmt.Errorf("label not applied, existing pods would violate %s:\n      %s", level, strings.Join(v, "\n      "))
+					}
+					return runCmd("kubectl", "label", "ns", ns, label, "--overwrite")
+				})
+		}
+	}
+}
 
-	a.logStep("Checking for privileged containers...")
-	privileged, _ := kubectl("get", resType, "-n", a.Namespace, a.ControllerName,
-		"-o", "jsonpath={.spec.template.spec.containers[*].securityContext.privileged}")
-	if privileged == "true" {
-		a.logWarn("Container running in privileged mode")
+// auditRBAC resolves the controller ServiceAccount's bindings, compares the
+// effective rules with the minimum for the --watch-namespace scope and looks
+// for other pods that mount the same token.
+func (a *AuditState) auditRBAC() {
+	podSpec := a.controllerPodSpec()
+	if len(podSpec) == 0 {
+		a.logWarn("Controller pod template not found — skipping RBAC checks")
+		return
+	}
+	sa := getString(podSpec, "serviceAccountName")
+	if sa == "" {
+		sa = "default"
+	}
+	a.logInfo(fmt.Sprintf("Controller ServiceAccount: %s/%s", a.Namespace, sa))
+
+	a.logStep("Resolving RoleBindings and ClusterRoleBindings...")
+	crbs, err := kubectlJSON("get", "clusterrolebindings")
+	if err != nil {
+		a.logWarn("Cannot list ClusterRoleBindings — skipping RBAC checks")
+		return
+	}
+	rbs, _ := kubectlJSON("get", "rolebindings", "-A")
+	crs, _ := kubectlJSON("get", "clusterroles")
+	roles, _ := kubectlJSON("get", "roles", "-A")
+	grants := resolveRBACGrants(sa, a.Namespace, getSlice(crbs, "items"), getSlice(rbs, "items"),
+		getSlice(crs, "items"), getSlice(roles, "items"))
+
+	bindings := map[string]bool{}
+	for _, g := range grants {
+		bindings[g.Role+" via "+g.Binding] = true
+	}
+	for _, b := range sortedKeys(bindings) {
+		a.logInfo(b)
+	}
+	scope := watchScope(a.controllerArgs())
+	if len(scope) > 0 {
+		a.logInfo(fmt.Sprintf("Watch scope: %s", strings.Join(scope, ",")))
 	} else {
-		a.logPass("Container not running in privileged mode")
+		a.logInfo("Watch scope: all namespaces")
+	}
+
+	findings := evaluateRBAC(grants, scope, a.ControllerVersion, a.Namespace+"/"+sa)
+	if len(findings) == 0 {
+		a.logPass("Controller permissions match the ingress-nginx minimum")
+	}
+	for _, f := range findings {
+		a.recordFinding(f)
+	}
+
+	a.logStep("Checking other pods that mount the controller ServiceAccount token...")
+	saObj, _ := kubectlJSON("get", "serviceaccount", sa, "-n", a.Namespace)
+	saAutomount := saObj["automountServiceAccountToken"] != false
+	pods, _ := kubectlJSON("get", "pods", "-n", a.Namespace)
+	selector := getMap(getMap(a.controllerWorkload(), "spec"), "selector")
+	shared := evaluateSharedServiceAccount(getSlice(pods, "items"), sa, saAutomount, selector, a.Namespace)
+	if len(shared) == 0 {
+		a.logPass("No other pod mounts the controller ServiceAccount token")
+	}
+	for _, f := range shared {
+		a.recordFinding(f)
 	}
 }
@@ -66,7 +66,7 @@ func (a *AuditState) auditVersion() {
 
 	if strings.Contains(a.ControllerImage, "@sha256:") {
 		parts := strings.SplitN(a.ControllerImage, "@", 2)
-		a.logInfo(fmt.Sprintf("Image SHA: %s...", truncate(parts[1], 20)))
+		a.logInfo(fmt.Sprintf("Image digest: %s", parts[1]))
 	}
 
 	const latestVersion = "v1.14.3"
@@ -94,24 +94,34 @@ func (a *AuditState) auditVersion() {
 
 	// ── Helm chart ──────────────────────────────────
 	a.printSection("Helm Chart Information")
-	a.logStep(fmt.Sprintf("Querying Helm releases in namespace %s...", a.Namespace))
+	a.HelmRelease, a.HelmReleaseNS = helmReleaseOf(getMap(a.controllerWorkload(), "metadata"))
+	release, releaseNS := a.HelmRelease, a.HelmReleaseNS
+	if release == "" {
+		release, releaseNS = "ingress-nginx", a.Namespace
+	}
+	a.logStep(fmt.Sprintf("Querying Helm releases in namespace %s...", releaseNS))
 
-	helmJSON, err := helmCmd("list", "-n", a.Namespace, "-o", "json")
+	helmJSON, err := helmCmd("list", "-n", releaseNS, "-o", "json")
 	if err == nil && helmJSON != "" {
 		var releases []map[string]interface{}
 		if json.Unmarshal([]byte(helmJSON), &releases) == nil {
 			for _, r := range releases {
-				if r["name"] == "ingress-nginx" {
+				if r["name"] == release {
+					a.HelmRelease, a.HelmReleaseNS = release, releaseNS
 					a.HelmChart = fmt.Sprintf("%v", r["chart"])
 					a.HelmStatus = fmt.Sprintf("%v", r["status"])
 					a.HelmRevision = fmt.Sprintf("%v", r["revision"])
-					a.HelmChartVersion = extractVersion(a.HelmChart)
+					a.HelmChartVersion = helmChartVersion(a.HelmChart)
+					a.logInfo(fmt.Sprintf("Helm release:  %s/%s", releaseNS, release))
 					a.logInfo(fmt.Sprintf("Helm chart:    %s", a.HelmChart))
 					a.logInfo(fmt.Sprintf("Status:        %s", a.HelmStatus))
 					a.logInfo(fmt.Sprintf("Revision:      %s", a.HelmRevision))
-					if a.HelmChartVersion == "4.14.3" {
+					switch a.HelmChartVersion {
+					case "":
+						a.logWarn(fmt.Sprintf("Could not read the chart version from %q — Helm-routed fixes are not offered", a.HelmChart))
+					case "4.14.3":
 						a.logPass("Running latest Helm chart version 4.14.3")
-					} else {
+					default:
 						a.logWarn(fmt.Sprintf("Chart version %s may be outdated (latest: 4.14.3)", a.HelmChartVersion))
 					}
 				}
@@ -121,6 +131,13 @@ func (a *AuditState) auditVersion() {
 		a.logWarn("Could not query Helm releases — not installed via Helm or Helm not available")
 	}
 
+	if a.HelmRelease != "" && a.HelmChart != "" {
+		a.printSection("Helm Drift")
+		a.auditHelmDrift()
+	} else {
+		a.HelmRelease = ""
+	}
+
 	// ── Update config ────────────────────────────────
 	a.printSection("Update Configuration")
 	resType := strings.ToLower(a.DeploymentType)
@@ -146,6 +163,10 @@ func (a *AuditState) auditVersion() {
 	}
 	a.logInfo(fmt.Sprintf("Update strategy: %s", a.UpdateStrategy))
 
+	// ── Image policy ─────────────────────────────────
+	a.printSection("Image Policy")
+	a.auditImagePolicy()
+
 	// ── Lifecycle warning ────────────────────────────
 	a.printSection("Project Lifecycle Status")
 	a.logWarn("⚠️  IMPORTANT: Ingress-NGINX community project is retiring in March 2026")
@@ -158,3 +179,95 @@ func (a *AuditState) auditVersion() {
 	a.writeln("    3. F5 NGINX Ingress   — Commercial, actively maintained")
 	a.writeln("    4. Istio              — Service mesh with ingress capabilities")
 }
+
+// auditHelmDrift compares the rendered release manifest with the live
+// Deployment/DaemonSet, ConfigMaps and Services and reports each drifted
+// field with the chart value that controls it.
+func (a *AuditState) auditHelmDrift() {
+	rel, ns := a.HelmRelease, a.HelmReleaseNS
+	a.logStep(fmt.Sprintf("Rendering manifest of release %s/%s...", ns, rel))
+	var values map[string]interface{}
+	if out, err := helmCmd("get", "values", rel, "-n", ns, "-o", "json"); err == nil {
+		_ = json.Unmarshal([]byte(out), &values)
+	}
+	manifest, err := helmCmd("get", "manifest", rel, "-n", ns)
+	if err != nil {
+		a.logWarn("Could not read the release manifest — drift not checked")
+		return
+	}
+	out, err := kubectlInput(filterManifest(manifest, driftKinds), "create", "--dry-run=client", "-f", "-", "-o", "json")
+	if err != nil || out == "" {
+		a.logWarn("Could not decode the release manifest — drift not checked")
+		return
+	}
+	var decoded map[string]interface{}
+	if json.Unmarshal([]byte(out), &decoded) != nil {
+		a.logWarn("Could not decode the release manifest — drift not checked")
+		return
+	}
+	objects := getSlice(decoded, "items")
+	if getString(decoded, "kind") != "List" {
+		objects = []interface{}{decoded}
+	}
+
+	drifted := 0
+	for _, o := range objects {
+		desired, _ := o.(map[string]interface{})
+		kind := getString(desired, "kind")
+		meta := getMap(desired, "metadata")
+		name, objNS := getString(meta, "name"), getString(meta, "namespace")
+		if objNS == "" {
+			objNS = ns
+		}
+		res := fmt.Sprintf("%s/%s/%s", strings.ToLower(kind), objNS, name)
+		live, err := kubectlJSON("get", kind, name, "-n", objNS)
+		if err != nil {
+			a.addFinding("helm-drift-missing", "WARN", res,
+				"Object rendered by the release does not exist in the cluster",
+				"Re-run helm upgrade to recreate it")
+			drifted++
+			continue
+		}
+		var entries []driftEntry
+		diffObjects(desired, live, "", &entries)
+		for _, d := range entries {
+			a.addFinding("helm-drift", "WARN", res,
+				fmt.Sprintf("%s: release=%s live=%s", d.Path, orDefault(d.Release), orDefault(d.Live)),
+				driftRemediation(kind, name, d, values))
+		}
+		drifted += len(entries)
+	}
+	if drifted == 0 {
+		a.logPass(fmt.Sprintf("Live objects match release %s (%d object(s) compared)", rel, len(objects)))
+	} else {
+		a.logInfo("Fixes for this controller are applied through Helm values so helm upgrade does not revert them")
+	}
+}
+
+// auditImagePolicy applies the image policy to every container of the
+// controller pod template and compares it with what the pods actually run.
+func (a *AuditState) auditImagePolicy() {
+	podSpec := a.controllerPodSpec()
+	if len(getSlice(podSpec, "containers")) == 0 {
+		a.logWarn("Controller pod template not found — skipping image policy")
+		return
+	}
+	var allowlist []string
+	for _, p := range strings.Split(a.ImageAllowlist, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			allowlist = append(allowlist, p)
+		}
+	}
+	a.logInfo(fmt.Sprintf("Allowed images: %s", strings.Join(allowlist, ", ")))
+
+	a.logStep("Reading image IDs from controller pod statuses...")
+	pods, _ := kubectlJSON("get", "pods", "-n", a.Namespace, "-l", a.controllerPodSelector())
+	res := fmt.Sprintf("%s/%s/%s", a.controllerResType(), a.Namespace, a.ControllerName)
+	findings := evaluateImagePolicy(podSpec, getSlice(pods, "items"), allowlist, res)
+	if len(findings) == 0 {
+		a.logPass("All controller images are allowlisted, pinned by digest and consistent across pods")
+	}
+	for _, f := range findings {
+		a.recordFinding(f)
+	}
+}
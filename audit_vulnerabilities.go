@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
 )
 
@@ -33,6 +34,10 @@ func (a *AuditState) auditVulnerabilities() {
 		a.logWarn(fmt.Sprintf("Unknown version %s — cannot verify CVE status", a.ControllerVersion))
 	}
 
+	// -- Image scan
+	a.printSection("Controller Image Scan")
+	a.auditImageScan()
+
 	// -- AbuseBSI compliance
 	a.printSection("AbuseBSI Report Compliance")
 	a.logStep("Checking CB-Report#20260218-10009947 specific vulnerability...")
@@ -40,11 +45,142 @@ func (a *AuditState) auditVulnerabilities() {
 	case a.DeploymentType == "":
 		a.logInfo(fmt.Sprintf(
 			"No ingress-nginx controller in namespace '%s' — AbuseBSI check not applicable", a.Namespace))
-	case a.AdmissionSvcType == "ClusterIP":
+	case a.abuseBSICompliant():
 		a.logPass(fmt.Sprintf("Admission controller not publicly exposed for %s (compliant)", a.Domain))
+	case a.AdmissionSvcType == "ClusterIP" && a.HostExposure.WebhookOnHost:
+		a.logFail(fmt.Sprintf("Admission webhook bound on the node network via hostNetwork/hostPort (NON-COMPLIANT for %s)", a.Domain))
+		a.logInfo("This is the specific vulnerability reported by AbuseBSI")
 	default:
 		a.logFail(fmt.Sprintf("Admission controller publicly exposed via %s (NON-COMPLIANT for %s)",
 			a.AdmissionSvcType, a.Domain))
 		a.logInfo("This is the specific vulnerability reported by AbuseBSI")
 	}
 }
+
+// grypeOfflineEnv stops grype from downloading or rejecting its database.
+var grypeOfflineEnv = []string{"GRYPE_DB_AUTO_UPDATE=false", "GRYPE_DB_VALIDATE_AGE=false"}
+
+// runningControllerImage returns the controller image reference and the
+// digest a running pod reports for it.
+func (a *AuditState) runningControllerImage() (image, digest string) {
+	image = getString(a.controllerContainer(), "image")
+	if image == "" {
+		image = a.ControllerImage
+	}
+	name := getString(a.controllerContainer(), "name")
+	pods, _ := kubectlJSON("get", "pods", "-n", a.Namespace, "-l", a.controllerPodSelector())
+	for _, p := range getSlice(pods, "items") {
+		for _, s := range getSlice(getMap(asMap(p), "status"), "containerStatuses") {
+			if sm := asMap(s); getString(sm, "name") == name {
+				if d := imageIDDigest(getString(sm, "imageID")); strings.HasPrefix(d, "sha256:") {
+					return image, d
+				}
+			}
+		}
+	}
+	return image, ""
+}
+
+// auditImageScan merges scanner results for the running controller image:
+// a report given with --image-scan-report, or a local trivy/grype run
+// against its offline database.
+func (a *AuditState) auditImageScan() {
+	image, digest := a.runningControllerImage()
+	if image == "" {
+		a.logWarn("Controller image unknown — skipping image scan")
+		return
+	}
+	target := image
+	if ref := parseImageRef(image); ref.Digest == "" && digest != "" {
+		target = ref.Name() + "@" + digest
+	}
+
+	var name string
+	var args, env []string
+	switch {
+	case a.ImageScanReport != "":
+		data, err := os.ReadFile(a.ImageScanReport)
+		if err != nil {
+			a.logWarn(fmt.Sprintf("Cannot read %s: %v", a.ImageScanReport, err))
+			return
+		}
+		r, sbomOnly, err := parseScanReport(data)
+		if err != nil {
+			a.logWarn(fmt.Sprintf("%s: %v", a.ImageScanReport, err))
+			return
+		}
+		if !sbomOnly {
+			a.logInfo(fmt.Sprintf("Scan report: %s (%s)", a.ImageScanReport, r.Scanner))
+			if !scanReportMatchesImage(r.Image, image, digest) {
+				a.addFinding("image-scan-mismatch", "WARN", a.ImageScanReport,
+					fmt.Sprintf("Scan report is for %s but the controller runs %s", r.Image, target),
+					"Scan the image the pods actually run")
+			}
+			a.reportImageScan(r)
+			return
+		}
+		if !cmdExists("grype") {
+			a.logWarn("SBOM carries no vulnerability data and grype is not installed to match it")
+			return
+		}
+		name, args, env = "grype", []string{"sbom:" + a.ImageScanReport, "-o", "json", "-q"}, grypeOfflineEnv
+	case cmdExists("trivy"):
+		name, args = "trivy", []string{"image", "--format", "json", "--quiet",
+			"--skip-db-update", "--skip-java-db-update", "--offline-scan", target}
+	case cmdExists("grype"):
+		name, args, env = "grype", []string{target, "-o", "json", "-q"}, grypeOfflineEnv
+	default:
+		a.logInfo("Neither trivy nor grype is on PATH and no --image-scan-report given — image scan skipped")
+		return
+	}
+
+	a.logStep(fmt.Sprintf("Scanning %s with %s (offline database)...", target, name))
+	out, err := commandEnv(env, name, args...)
+	if err != nil {
+		a.logWarn(fmt.Sprintf("%s failed: %v — is its vulnerability database downloaded?", name, err))
+		return
+	}
+	r, _, err := parseScanReport(out)
+	if err != nil {
+		a.logWarn(fmt.Sprintf("Cannot parse %s output: %v", name, err))
+		return
+	}
+	r.Scanner = name
+	a.reportImageScan(r)
+}
+
+// reportImageScan prints severity counts and the CRITICAL/HIGH
+// vulnerabilities with their fixed versions.
+func (a *AuditState) reportImageScan(r ImageScanResult) {
+	a.ImageScan = &r
+	a.writeln(fmt.Sprintf("\n    %-9s %9s %6s %7s %5s %8s", "CLASS", "CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"))
+	for _, class := range []string{"os", "library"} {
+		c := r.Counts
+		a.writeln(fmt.Sprintf("    %-9s %9d %6d %7d %5d %8d", class, c[class+"/CRITICAL"], c[class+"/HIGH"],
+			c[class+"/MEDIUM"], c[class+"/LOW"], c[class+"/UNKNOWN"]))
+	}
+	a.writeln("")
+	listed := false
+	for _, v := range r.Vulns {
+		if v.Severity != "CRITICAL" && v.Severity != "HIGH" {
+			continue
+		}
+		if !listed {
+			a.writeln(fmt.Sprintf("    %-20s %-9s %-28s %-20s %s", "ID", "SEVERITY", "PACKAGE", "INSTALLED", "FIXED"))
+			listed = true
+		}
+		a.writeln(fmt.Sprintf("    %-20s %-9s %-28s %-20s %s", v.ID, v.Severity, truncate(v.Package, 28), truncate(v.Installed, 20), orDefault(v.Fixed)))
+	}
+	if listed {
+		a.writeln("")
+	}
+
+	res := fmt.Sprintf("%s/%s/%s", a.controllerResType(), a.Namespace, a.ControllerName)
+	findings := evaluateImageScan(r, res)
+	if len(findings) == 0 {
+		a.logPass("No CRITICAL or HIGH vulnerabilities in the controller image")
+	}
+	for _, f := range findings {
+		a.recordFinding(f)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ─────────────────────────────────────────────
+// High-availability and disruption posture
+// ─────────────────────────────────────────────
+
+// Well-known node topology labels.
+const (
+	hostnameTopologyKey = "kubernetes.io/hostname"
+	zoneTopologyKey     = "topology.kubernetes.io/zone"
+)
+
+// podPlacement is where one controller pod is running.
+type podPlacement struct {
+	Name string
+	Node string
+	Zone string
+}
+
+// availabilityInput is everything evaluateAvailability needs, gathered from
+// the cluster by auditAvailability.
+type availabilityInput struct {
+	Kind          string // "Deployment" or "DaemonSet"
+	Name          string
+	Replicas      int // spec.replicas (Deployment) or desired pods (DaemonSet)
+	PodSpec       map[string]interface{}
+	Container     map[string]interface{}
+	PodLabels     map[string]string
+	PDBs          []interface{} // policy/v1 PodDisruptionBudgets in the namespace
+	HPAs          []interface{} // autoscaling/v2 HorizontalPodAutoscalers in the namespace
+	Pods          []podPlacement
+	ClusterZones  int
+	ShutdownGrace time.Duration // --shutdown-grace-period
+	WorkerTimeout time.Duration // worker-shutdown-timeout
+}
+
+// scaledCount resolves an int-or-percent PDB field against total pods,
+// rounding up like the disruption controller does.
+func scaledCount(v interface{}, total int) (int, bool) {
+	if n, ok := toInt(v); ok {
+		return n, true
+	}
+	s, ok := v.(string)
+	if !ok || !strings.HasSuffix(s, "%") {
+		return 0, false
+	}
+	pct, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+	if err != nil {
+		return 0, false
+	}
+	return int(math.Ceil(float64(pct) * float64(total) / 100)), true
+}
+
+// spreadsAcross reports whether the pod spec asks the scheduler to spread
+// matching pods across topologyKey, via pod anti-affinity or a topology
+// spread constraint.
+func spreadsAcross(podSpec map[string]interface{}, podLabels map[string]string, topologyKey string) bool {
+	anti := getMap(getMap(podSpec, "affinity"), "podAntiAffinity")
+	var terms []map[string]interface{}
+	for _, t := range getSlice(anti, "requiredDuringSchedulingIgnoredDuringExecution") {
+		tm, _ := t.(map[string]interface{})
+		terms = append(terms, tm)
+	}
+	for _, t := range getSlice(anti, "preferredDuringSchedulingIgnoredDuringExecution") {
+		tm, _ := t.(map[string]interface{})
+		terms = append(terms, getMap(tm, "podAffinityTerm"))
+	}
+	for _, t := range terms {
+		if getString(t, "topologyKey") == topologyKey && labelSelectorMatches(getMap(t, "labelSelector"), podLabels) {
+			return true
+		}
+	}
+	for _, c := range getSlice(podSpec, "topologySpreadConstraints") {
+		cm, _ := c.(map[string]interface{})
+		if getString(cm, "topologyKey") == topologyKey && labelSelectorMatches(getMap(cm, "labelSelector"), podLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+// distinct counts the distinct non-empty values returned by key.
+func distinct(pods []podPlacement, key func(podPlacement) string) int {
+	seen := map[string]bool{}
+	for _, p := range pods {
+		if v := key(p); v != "" {
+			seen[v] = true
+		}
+	}
+	return len(seen)
+}
+
+// evaluateAvailability checks whether the controller survives node drains,
+// zone failures and rolling restarts without dropping traffic.
+func evaluateAvailability(in availabilityInput, res string) []Finding {
+	var findings []Finding
+	add := func(id, level, msg, rationale, fix string) {
+		findings = append(findings, Finding{ID: id, Level: level, Resource: res,
+			Message: msg, Rationale: rationale, Remediation: fix})
+	}
+
+	// ── Autoscaler ──
+	var hpa map[string]interface{}
+	for _, h := range in.HPAs {
+		hm, _ := h.(map[string]interface{})
+		ref := getMap(getMap(hm, "spec"), "scaleTargetRef")
+		if getString(ref, "kind") == in.Kind && getString(ref, "name") == in.Name {
+			hpa = hm
+			break
+		}
+	}
+	minReplicas := in.Replicas
+	if in.Kind == "Deployment" {
+		if hpa == nil {
+			add("ha-hpa-missing", "INFO", "No HorizontalPodAutoscaler targets the controller",
+				"", "Add an HPA (controller.autoscaling.enabled in the Helm chart) with minReplicas ≥ 2")
+		} else {
+			spec := getMap(hpa, "spec")
+			lo, ok := toInt(spec["minReplicas"])
+			if !ok {
+				lo = 1
+			}
+			hi, _ := toInt(spec["maxReplicas"])
+			minReplicas = lo
+			if lo < 2 {
+				add("ha-hpa-min", "WARN", fmt.Sprintf("HPA %s allows scaling down to %d replica", getString(getMap(hpa, "metadata"), "name"), lo),
+					"At the HPA floor a single pod serves all traffic and a node drain causes an outage.",
+					"Set minReplicas: 2 or higher")
+			}
+			if hi <= lo {
+				add("ha-hpa-bounds", "WARN", fmt.Sprintf("HPA maxReplicas %d does not exceed minReplicas %d", hi, lo),
+					"", "Raise maxReplicas so the controller can absorb traffic spikes")
+			}
+		}
+	}
+
+	// ── Replicas ──
+	if minReplicas < 2 {
+		add("ha-replicas", "FAIL", fmt.Sprintf("Controller runs %d replica(s)", minReplicas),
+			"A single controller pod means every node drain, eviction or crash drops all ingress traffic.",
+			fmt.Sprintf("kubectl scale %s %s --replicas=2 (or controller.replicaCount in Helm values)", strings.ToLower(in.Kind), in.Name))
+	}
+
+	// ── PodDisruptionBudget ──
+	var pdbs []string
+	for _, p := range in.PDBs {
+		pm, _ := p.(map[string]interface{})
+		spec := getMap(pm, "spec")
+		if _, has := spec["selector"]; !has || !labelSelectorMatches(getMap(spec, "selector"), in.PodLabels) {
+			continue
+		}
+		name := getString(getMap(pm, "metadata"), "name")
+		pdbs = append(pdbs, name)
+		blocks := false
+		if n, ok := scaledCount(spec["minAvailable"], minReplicas); ok && n >= minReplicas {
+			blocks = true
+		}
+		if n, ok := scaledCount(spec["maxUnavailable"], minReplicas); ok && n == 0 {
+			blocks = true
+		}
+		if blocks {
+			add("ha-pdb-blocks-drain", "WARN", fmt.Sprintf("PodDisruptionBudget %s allows no disruption with %d replica(s)", name, minReplicas),
+				"A PDB that never allows an eviction blocks node drains and cluster upgrades.",
+				"Use maxUnavailable: 1 with at least 2 replicas")
+		}
+	}
+	switch {
+	case len(pdbs) == 0:
+		add("ha-pdb-missing", "WARN", "No PodDisruptionBudget selects the controller pods",
+			"Without a PDB a node drain may evict every controller pod at once.",
+			"Create a PDB with maxUnavailable: 1 (controller.minAvailable in Helm values)")
+	case len(pdbs) > 1:
+		sort.Strings(pdbs)
+		add("ha-pdb-overlap", "WARN", fmt.Sprintf("Multiple PodDisruptionBudgets select the controller pods: %s", strings.Join(pdbs, ", ")),
+			"The eviction API refuses to evict pods covered by more than one PDB.",
+			"Keep a single PDB for the controller")
+	}
+
+	// ── Spreading (configured and actual) ──
+	if in.Kind == "Deployment" {
+		if !spreadsAcross(in.PodSpec, in.PodLabels, hostnameTopologyKey) {
+			add("ha-spread-nodes", "WARN", "No pod anti-affinity or topology spread constraint across nodes",
+				"Replicas may be scheduled onto the same node, which a single drain takes down together.",
+				"Add a topologySpreadConstraint on kubernetes.io/hostname for the controller pods")
+		}
+		if in.ClusterZones > 1 && !spreadsAcross(in.PodSpec, in.PodLabels, zoneTopologyKey) {
+			add("ha-spread-zones", "INFO", fmt.Sprintf("Cluster spans %d zones but controller pods are not spread across zones", in.ClusterZones),
+				"", "Add a topologySpreadConstraint on topology.kubernetes.io/zone")
+		}
+	}
+	if len(in.Pods) > 1 {
+		if distinct(in.Pods, func(p podPlacement) string { return p.Node }) == 1 {
+			add("ha-placement-node", "FAIL", fmt.Sprintf("All %d controller pods run on node %s", len(in.Pods), in.Pods[0].Node),
+				"Draining or losing that node takes the whole ingress down.",
+				"Add node anti-affinity/spread constraints and restart the rollout")
+		} else if in.ClusterZones > 1 && distinct(in.Pods, func(p podPlacement) string { return p.Zone }) == 1 {
+			add("ha-placement-zone", "WARN", fmt.Sprintf("All controller pods run in zone %s", in.Pods[0].Zone),
+				"A zone outage takes the whole ingress down.",
+				"Add a zone topologySpreadConstraint")
+		}
+	}
+
+	// ── Probes, priority and shutdown ──
+	if _, ok := in.Container["readinessProbe"].(map[string]interface{}); !ok {
+		add("ha-readiness-probe", "WARN", "Controller container has no readinessProbe",
+			"Pods receive traffic before NGINX has loaded its configuration.",
+			"Add a readinessProbe on /healthz port 10254")
+	}
+	if _, ok := in.Container["livenessProbe"].(map[string]interface{}); !ok {
+		add("ha-liveness-probe", "WARN", "Controller container has no livenessProbe",
+			"A wedged controller is never restarted.",
+			"Add a livenessProbe on /healthz port 10254")
+	}
+	if getString(in.PodSpec, "priorityClassName") == "" {
+		add("ha-priority-class", "WARN", "Controller pods have no priorityClassName",
+			"Under node pressure the controller can be preempted or evicted before ordinary workloads.",
+			"Set priorityClassName (e.g. a dedicated high-priority class, or system-cluster-critical)")
+	}
+
+	grace := 30 * time.Second
+	if n, ok := toInt(in.PodSpec["terminationGracePeriodSeconds"]); ok {
+		grace = time.Duration(n) * time.Second
+	}
+	if need := in.ShutdownGrace + in.WorkerTimeout; grace < need {
+		add("ha-termination-grace", "WARN",
+			fmt.Sprintf("terminationGracePeriodSeconds %s is shorter than shutdown-grace-period %s + worker-shutdown-timeout %s",
+				grace, in.ShutdownGrace, in.WorkerTimeout),
+			"The kubelet kills NGINX before in-flight requests and long-lived connections drain.",
+			fmt.Sprintf("Set terminationGracePeriodSeconds to at least %d", int(need.Seconds())))
+	}
+	return findings
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// haInput returns a fully hardened two-replica Deployment; tests remove
+// pieces of it to trigger individual findings.
+func haInput(t *testing.T) availabilityInput {
+	t.Helper()
+	labels := map[string]string{"app.kubernetes.io/name": "ingress-nginx"}
+	sel := map[string]interface{}{"matchLabels": map[string]interface{}{"app.kubernetes.io/name": "ingress-nginx"}}
+	return availabilityInput{
+		Kind:     "Deployment",
+		Name:     "ingress-nginx-controller",
+		Replicas: 2,
+		PodSpec: map[string]interface{}{
+			"priorityClassName":             "ingress-critical",
+			"terminationGracePeriodSeconds": float64(300),
+			"topologySpreadConstraints": []interface{}{
+				map[string]interface{}{"topologyKey": hostnameTopologyKey, "labelSelector": sel},
+				map[string]interface{}{"topologyKey": zoneTopologyKey, "labelSelector": sel},
+			},
+		},
+		Container: map[string]interface{}{
+			"readinessProbe": map[string]interface{}{},
+			"livenessProbe":  map[string]interface{}{},
+		},
+		PodLabels: labels,
+		PDBs: []interface{}{map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "ingress-nginx-controller"},
+			"spec":     map[string]interface{}{"selector": sel, "maxUnavailable": float64(1)},
+		}},
+		HPAs: []interface{}{map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "ingress-nginx-controller"},
+			"spec": map[string]interface{}{
+				"scaleTargetRef": map[string]interface{}{"kind": "Deployment", "name": "ingress-nginx-controller"},
+				"minReplicas":    float64(2),
+				"maxReplicas":    float64(6),
+			},
+		}},
+		Pods: []podPlacement{
+			{Name: "a", Node: "n1", Zone: "z1"},
+			{Name: "b", Node: "n2", Zone: "z2"},
+		},
+		ClusterZones:  2,
+		WorkerTimeout: 240 * time.Second,
+	}
+}
+
+func TestEvaluateAvailabilityHardened(t *testing.T) {
+	if fs := evaluateAvailability(haInput(t), "deployment/x"); len(fs) != 0 {
+		t.Errorf("hardened controller produced findings: %+v", fs)
+	}
+}
+
+func TestEvaluateAvailabilitySingleReplica(t *testing.T) {
+	in := haInput(t)
+	in.Replicas = 1
+	in.HPAs = nil
+	pdb := in.PDBs[0].(map[string]interface{})["spec"].(map[string]interface{})
+	delete(pdb, "maxUnavailable")
+	pdb["minAvailable"] = float64(1)
+	fs := evaluateAvailability(in, "deployment/x")
+	for _, id := range []string{"ha-replicas", "ha-hpa-missing", "ha-pdb-blocks-drain"} {
+		if !hasFinding(fs, id) {
+			t.Errorf("missing %s in %+v", id, fs)
+		}
+	}
+}
+
+func TestEvaluateAvailabilityHPAFloor(t *testing.T) {
+	in := haInput(t)
+	spec := in.HPAs[0].(map[string]interface{})["spec"].(map[string]interface{})
+	spec["minReplicas"] = float64(1)
+	spec["maxReplicas"] = float64(1)
+	fs := evaluateAvailability(in, "deployment/x")
+	for _, id := range []string{"ha-hpa-min", "ha-hpa-bounds", "ha-replicas"} {
+		if !hasFinding(fs, id) {
+			t.Errorf("missing %s", id)
+		}
+	}
+}
+
+func TestEvaluateAvailabilityPlacement(t *testing.T) {
+	in := haInput(t)
+	in.Pods[1].Node = "n1"
+	if fs := evaluateAvailability(in, "deployment/x"); !hasFinding(fs, "ha-placement-node") {
+		t.Error("pods on one node should be reported")
+	}
+	in = haInput(t)
+	in.Pods[1].Zone = "z1"
+	if fs := evaluateAvailability(in, "deployment/x"); !hasFinding(fs, "ha-placement-zone") {
+		t.Error("pods in one zone should be reported")
+	}
+}
+
+func TestEvaluateAvailabilityMissingSpecPieces(t *testing.T) {
+	in := haInput(t)
+	in.PodSpec = map[string]interface{}{}
+	in.Container = map[string]interface{}{}
+	in.PDBs = nil
+	in.ShutdownGrace = 10 * time.Second
+	fs := evaluateAvailability(in, "deployment/x")
+	for _, id := range []string{"ha-pdb-missing", "ha-spread-nodes", "ha-spread-zones",
+		"ha-readiness-probe", "ha-liveness-probe", "ha-priority-class", "ha-termination-grace"} {
+		if !hasFinding(fs, id) {
+			t.Errorf("missing %s", id)
+		}
+	}
+}
+
+func TestScaledCount(t *testing.T) {
+	cases := []struct {
+		v     interface{}
+		total int
+		want  int
+		ok    bool
+	}{
+		{float64(1), 3, 1, true},
+		{"50%", 3, 2, true},
+		{"100%", 2, 2, true},
+		{"x%", 2, 0, false},
+		{nil, 2, 0, false},
+	}
+	for _, c := range cases {
+		got, ok := scaledCount(c.v, c.total)
+		if got != c.want || ok != c.ok {
+			t.Errorf("scaledCount(%v, %d) = %d, %v; want %d, %v", c.v, c.total, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ─────────────────────────────────────────────
+// X.509 certificate parsing
+// ─────────────────────────────────────────────
+
+// certExpiryWarnDays is the remaining validity below which a certificate is
+// flagged for renewal.
+const certExpiryWarnDays = 30
+
+// CertInfo describes the leaf certificate of a PEM chain.
+type CertInfo struct {
+	Source             string    `json:"source"` // "secret/ns/name"
+	Subject            string    `json:"subject"`
+	SANs               []string  `json:"sans"`
+	Issuer             string    `json:"issuer"`
+	Serial             string    `json:"serial"`
+	KeyType            string    `json:"key_type"`
+	KeyBits            int       `json:"key_bits"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	ChainLength        int       `json:"chain_length"`
+
+	chain []*x509.Certificate
+}
+
+// parseCertChain parses every CERTIFICATE block of a PEM bundle, leaf first.
+func parseCertChain(data []byte) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", len(chain)+1, err)
+		}
+		chain = append(chain, c)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+	return chain, nil
+}
+
+// publicKeyInfo returns the key type and size of a certificate's public key.
+func publicKeyInfo(c *x509.Certificate) (string, int) {
+	switch k := c.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", k.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ECDSA", k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", 256
+	}
+	return c.PublicKeyAlgorithm.String(), 0
+}
+
+// certSANs lists the DNS, IP, e-mail and URI subject alternative names.
+func certSANs(c *x509.Certificate) []string {
+	sans := append([]string{}, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, c.EmailAddresses...)
+	for _, u := range c.URIs {
+		sans = append(sans, u.String())
+	}
+	return sans
+}
+
+// describeCert parses a PEM chain into a CertInfo for its leaf.
+func describeCert(source string, data []byte) (CertInfo, error) {
+	chain, err := parseCertChain(data)
+	if err != nil {
+		return CertInfo{Source: source}, err
+	}
+	leaf := chain[0]
+	keyType, keyBits := publicKeyInfo(leaf)
+	return CertInfo{
+		Source:             source,
+		Subject:            leaf.Subject.String(),
+		SANs:               certSANs(leaf),
+		Issuer:             leaf.Issuer.String(),
+		Serial:             fmt.Sprintf("%X", leaf.SerialNumber),
+		KeyType:            keyType,
+		KeyBits:            keyBits,
+		SignatureAlgorithm: leaf.SignatureAlgorithm.String(),
+		NotBefore:          leaf.NotBefore,
+		NotAfter:           leaf.NotAfter,
+		ChainLength:        len(chain),
+		chain:              chain,
+	}, nil
+}
+
+// weakKey reports why a key is too small, or "".
+func weakKey(keyType string, bits int) string {
+	switch {
+	case keyType == "RSA" && bits < 2048:
+		return fmt.Sprintf("RSA %d-bit key (minimum 2048)", bits)
+	case keyType == "ECDSA" && bits < 256:
+		return fmt.Sprintf("ECDSA %d-bit key (minimum 256)", bits)
+	}
+	return ""
+}
+
+// weakSignature reports whether a signature algorithm uses SHA-1 or MD5.
+func weakSignature(alg x509.SignatureAlgorithm) bool {
+	switch alg {
+	case x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1, x509.MD5WithRSA, x509.MD2WithRSA:
+		return true
+	}
+	return false
+}
+
+// evaluateCert checks validity dates, key size and signatures. Self-signed
+// roots included in the chain are not checked for their signature, which
+// clients never verify.
+func evaluateCert(info CertInfo, now time.Time, res string) []Finding {
+	var findings []Finding
+	add := func(id, level, msg, fix string) {
+		findings = append(findings, Finding{ID: id, Level: level, Resource: res, Message: msg, Remediation: fix})
+	}
+	daysLeft := int(info.NotAfter.Sub(now).Hours() / 24)
+	switch {
+	case now.After(info.NotAfter):
+		add("cert-expired", "FAIL", fmt.Sprintf("Certificate %s expired on %s", info.Subject, info.NotAfter.Format("2006-01-02")),
+			"Renew the certificate")
+	case now.Before(info.NotBefore):
+		add("cert-not-yet-valid", "FAIL", fmt.Sprintf("Certificate %s is not valid before %s", info.Subject, info.NotBefore.Format("2006-01-02")),
+			"Check the issuer's clock, or wait until the certificate becomes valid")
+	case daysLeft < certExpiryWarnDays:
+		add("cert-expiring", "WARN", fmt.Sprintf("Certificate %s expires in %d days", info.Subject, daysLeft),
+			"Renew the certificate")
+	}
+	if why := weakKey(info.KeyType, info.KeyBits); why != "" {
+		add("cert-weak-key", "FAIL", fmt.Sprintf("Certificate %s uses a weak %s", info.Subject, why),
+			"Reissue the certificate with an RSA 2048+ or ECDSA P-256+ key")
+	}
+	for i, c := range info.chain {
+		if i > 0 && bytes.Equal(c.RawSubject, c.RawIssuer) {
+			continue
+		}
+		if weakSignature(c.SignatureAlgorithm) {
+			which := "Certificate"
+			if i > 0 {
+				which = fmt.Sprintf("Chain certificate %d (%s)", i+1, c.Subject.CommonName)
+			}
+			add("cert-weak-signature", "FAIL", fmt.Sprintf("%s is signed with %s", which, c.SignatureAlgorithm),
+				"Reissue the certificate with a SHA-256 or stronger signature")
+		}
+	}
+	return findings
+}
+
+// certSummary returns the display lines for a certificate.
+func certSummary(info CertInfo) []string {
+	sans := strings.Join(info.SANs, ", ")
+	if sans == "" {
+		sans = "none"
+	}
+	return []string{
+		fmt.Sprintf("Subject:     %s", info.Subject),
+		fmt.Sprintf("SANs:        %s", sans),
+		fmt.Sprintf("Issuer:      %s", info.Issuer),
+		fmt.Sprintf("Serial:      %s", info.Serial),
+		fmt.Sprintf("Key:         %s %d", info.KeyType, info.KeyBits),
+		fmt.Sprintf("Signature:   %s", info.SignatureAlgorithm),
+		fmt.Sprintf("Valid:       %s → %s", info.NotBefore.UTC().Format(time.RFC3339), info.NotAfter.UTC().Format(time.RFC3339)),
+		fmt.Sprintf("Chain:       %d certificate(s)", info.ChainLength),
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert returns a PEM certificate for key, signed by parent (self-signed
+// when parent is nil).
+func testCert(t *testing.T, cn string, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer,
+	sigAlg x509.SignatureAlgorithm, notAfter time.Time) ([]byte, *x509.Certificate) {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(0x1234),
+		Subject:               pkix.Name{CommonName: cn},
+		DNSNames:              []string{cn},
+		IPAddresses:           []net.IP{net.ParseIP("10.0.0.1")},
+		NotBefore:             notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		SignatureAlgorithm:    sigAlg,
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := x509.ParseCertificate(der)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), c
+}
+
+func ecKey(t *testing.T) *ecdsa.PrivateKey {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+// ─── describeCert ────────────────────────────────────────────────────────────
+
+func TestDescribeCertChain(t *testing.T) {
+	now := time.Now()
+	caKey, leafKey := ecKey(t), ecKey(t)
+	caPEM, ca := testCert(t, "Test CA", caKey, nil, nil, x509.ECDSAWithSHA256, now.Add(365*24*time.Hour))
+	leafPEM, _ := testCert(t, "web.example.com", leafKey, ca, caKey, x509.ECDSAWithSHA256, now.Add(90*24*time.Hour))
+
+	info, err := describeCert("secret/team/web", append(leafPEM, caPEM...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Subject != "CN=web.example.com" || info.Issuer != "CN=Test CA" || info.Serial != "1234" {
+		t.Errorf("info = %+v", info)
+	}
+	if info.KeyType != "ECDSA" || info.KeyBits != 256 || info.SignatureAlgorithm != "ECDSA-SHA256" || info.ChainLength != 2 {
+		t.Errorf("info = %+v", info)
+	}
+	if strings.Join(info.SANs, ",") != "web.example.com,10.0.0.1" {
+		t.Errorf("SANs = %v", info.SANs)
+	}
+	if fs := evaluateCert(info, now, info.Source); len(fs) != 0 {
+		t.Errorf("findings = %+v", fs)
+	}
+}
+
+func TestDescribeCertInvalid(t *testing.T) {
+	if _, err := describeCert("x", []byte("not a certificate")); err == nil {
+		t.Error("expected an error without PEM data")
+	}
+	bad := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{0x30, 0x00}})
+	if _, err := describeCert("x", bad); err == nil {
+		t.Error("expected an error for malformed DER")
+	}
+}
+
+// ─── evaluateCert ────────────────────────────────────────────────────────────
+
+func TestEvaluateCertDates(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		notBefore, notAfter time.Time
+		want                string
+	}{
+		{now.AddDate(0, -3, 0), now.AddDate(0, 0, -1), "cert-expired"},
+		{now.AddDate(0, 0, 1), now.AddDate(1, 0, 0), "cert-not-yet-valid"},
+		{now.AddDate(0, -3, 0), now.AddDate(0, 0, 10), "cert-expiring"},
+		{now.AddDate(0, -3, 0), now.AddDate(0, 0, 60), ""},
+	}
+	for _, c := range cases {
+		info := CertInfo{Subject: "CN=x", KeyType: "ECDSA", KeyBits: 256, NotBefore: c.notBefore, NotAfter: c.notAfter}
+		fs := evaluateCert(info, now, "secret/ns/x")
+		switch {
+		case c.want == "" && len(fs) != 0:
+			t.Errorf("unexpected findings %+v", fs)
+		case c.want != "" && (len(fs) != 1 || fs[0].ID != c.want):
+			t.Errorf("findings = %+v, want %s", fs, c.want)
+		}
+	}
+}
+
+func TestEvaluateCertWeakKeyAndSignature(t *testing.T) {
+	now := time.Now()
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caKey := ecKey(t)
+	caPEM, ca := testCert(t, "Legacy CA", caKey, nil, nil, x509.ECDSAWithSHA1, now.Add(365*24*time.Hour))
+	leafPEM, _ := testCert(t, "old.example.com", weak, ca, caKey, x509.ECDSAWithSHA1, now.Add(90*24*time.Hour))
+	info, err := describeCert("secret/team/old", append(leafPEM, caPEM...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := evaluateCert(info, now, info.Source)
+	if f, ok := findingByID(fs, "cert-weak-key"); !ok || !strings.Contains(f.Message, "RSA 1024-bit") {
+		t.Errorf("findings = %+v", fs)
+	}
+	// The self-signed root's SHA-1 signature is not reported.
+	var sigs int
+	for _, f := range fs {
+		if f.ID == "cert-weak-signature" {
+			sigs++
+		}
+	}
+	if sigs != 1 {
+		t.Errorf("weak signature findings = %d, want 1: %+v", sigs, fs)
+	}
+}
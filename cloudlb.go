@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Cloud load-balancer annotation rules
+// ─────────────────────────────────────────────
+
+// Service annotations understood by the supported cloud providers.
+const (
+	awsLBScheme       = "service.beta.kubernetes.io/aws-load-balancer-scheme"
+	awsLBInternal     = "service.beta.kubernetes.io/aws-load-balancer-internal"
+	awsLBType         = "service.beta.kubernetes.io/aws-load-balancer-type"
+	awsLBProxyProto   = "service.beta.kubernetes.io/aws-load-balancer-proxy-protocol"
+	awsLBSSLCert      = "service.beta.kubernetes.io/aws-load-balancer-ssl-cert"
+	awsLBSSLPorts     = "service.beta.kubernetes.io/aws-load-balancer-ssl-ports"
+	awsLBSSLPolicy    = "service.beta.kubernetes.io/aws-load-balancer-ssl-negotiation-policy"
+	gkeLBType         = "networking.gke.io/load-balancer-type"
+	gkeLBTypeLegacy   = "cloud.google.com/load-balancer-type"
+	azureLBInternal   = "service.beta.kubernetes.io/azure-load-balancer-internal"
+	awsAnnPrefix      = "service.beta.kubernetes.io/aws-load-balancer-"
+	awsLBCClass       = "service.k8s.aws/nlb"
+	azureAnnPrefix    = "service.beta.kubernetes.io/azure-"
+	gkeAnnPrefix      = "networking.gke.io/"
+	gkeAnnPrefixOlder = "cloud.google.com/"
+)
+
+// detectCloudProvider derives the cloud provider ("aws", "gcp", "azure", or
+// the raw providerID scheme) from node spec.providerID values.
+func detectCloudProvider(providerIDs []string) string {
+	for _, id := range providerIDs {
+		scheme, _, ok := strings.Cut(id, "://")
+		if !ok || scheme == "" {
+			continue
+		}
+		switch scheme {
+		case "aws":
+			return "aws"
+		case "gce":
+			return "gcp"
+		case "azure":
+			return "azure"
+		default:
+			return scheme
+		}
+	}
+	return ""
+}
+
+// wantsInternalLB guesses whether a controller is meant to sit behind an
+// internal load balancer from its name and namespace. An explicit
+// --lb-scheme overrides the guess.
+func wantsInternalLB(override, name, ns string) bool {
+	switch override {
+	case "internal":
+		return true
+	case "internet-facing":
+		return false
+	}
+	s := strings.ToLower(name + " " + ns)
+	return strings.Contains(s, "internal") || strings.Contains(s, "private")
+}
+
+// awsLBControllerManaged reports whether the AWS Load Balancer Controller,
+// rather than the in-tree cloud provider, provisions the Service's load
+// balancer. Only the former honours the aws-load-balancer-scheme annotation.
+func awsLBControllerManaged(ann map[string]string, lbClass string) bool {
+	return ann[awsLBType] == "external" || ann[awsLBType] == "nlb-ip" || lbClass == awsLBCClass
+}
+
+// evaluateLBAnnotations checks the controller Service's provider annotations
+// and spec.loadBalancerClass for the intended scheme and for consistency
+// with the ingress-nginx ConfigMap. guessed marks wantInternal as derived
+// from names rather than --lb-scheme; a mismatch is then only a warning.
+// It returns the effective scheme ("internal", "internet-facing" or
+// "unknown") and the resulting findings.
+func evaluateLBAnnotations(provider string, ann map[string]string, lbClass string, cm map[string]string, wantInternal, guessed bool, res string) (string, []Finding) {
+	var findings []Finding
+	add := func(id, level, msg, fix string) {
+		findings = append(findings, Finding{ID: id, Level: level, Resource: res, Message: msg, Remediation: fix})
+	}
+	proxyProto := cm["use-proxy-protocol"] == "true"
+
+	// Annotations for a different provider are silently ignored by the cloud.
+	foreign := map[string][]string{
+		"aws":   {azureAnnPrefix, gkeAnnPrefix, gkeAnnPrefixOlder},
+		"gcp":   {awsAnnPrefix, azureAnnPrefix},
+		"azure": {awsAnnPrefix, gkeAnnPrefix, gkeAnnPrefixOlder},
+	}
+	var keys []string
+	for k := range ann {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, prefix := range foreign[provider] {
+			if strings.HasPrefix(k, prefix) {
+				add("lb-foreign-annotation", "WARN",
+					fmt.Sprintf("Annotation %s has no effect on %s", k, strings.ToUpper(provider)),
+					"Replace it with the equivalent annotation for this provider")
+			}
+		}
+	}
+
+	scheme := "unknown"
+	var internalFix string
+	switch provider {
+	case "aws":
+		if awsLBControllerManaged(ann, lbClass) {
+			internalFix = fmt.Sprintf("Set %s: internal", awsLBScheme)
+			// The AWS Load Balancer Controller defaults to internal.
+			scheme = "internal"
+			if ann[awsLBScheme] != "" {
+				scheme = ann[awsLBScheme]
+			}
+		} else {
+			internalFix = fmt.Sprintf(`Set %s: "true"`, awsLBInternal)
+			scheme = "internet-facing"
+			if ann[awsLBInternal] == "true" || ann[awsLBInternal] == "0.0.0.0/0" {
+				scheme = "internal"
+			}
+			if ann[awsLBScheme] != "" {
+				add("lb-aws-scheme-ignored", "WARN",
+					fmt.Sprintf("%s: %s is ignored by the in-tree AWS cloud provider — the load balancer is %s",
+						awsLBScheme, ann[awsLBScheme], scheme),
+					fmt.Sprintf(`Set %s: "true", or hand the Service to the AWS Load Balancer Controller (%s: external)`,
+						awsLBInternal, awsLBType))
+			}
+		}
+
+		lbProxy := ann[awsLBProxyProto] == "*"
+		switch {
+		case lbProxy && !proxyProto:
+			add("lb-proxy-protocol-mismatch", "FAIL",
+				"Load balancer sends PROXY protocol but use-proxy-protocol is not enabled — requests will fail to parse",
+				`Set use-proxy-protocol: "true" in the controller ConfigMap`)
+		case !lbProxy && proxyProto:
+			add("lb-proxy-protocol-mismatch", "FAIL",
+				"use-proxy-protocol is enabled but the load balancer does not send PROXY protocol — every connection will be rejected",
+				fmt.Sprintf(`Add %s: "*" or set use-proxy-protocol: "false"`, awsLBProxyProto))
+		}
+
+		if ann[awsLBSSLCert] != "" {
+			if ann[awsLBSSLPorts] == "" {
+				add("lb-tls-ports", "WARN",
+					"TLS certificate set without ssl-ports — every listener (including port 80) terminates TLS",
+					fmt.Sprintf(`Set %s: "https" (or "443")`, awsLBSSLPorts))
+			}
+			policy := ann[awsLBSSLPolicy]
+			switch {
+			case policy == "":
+				add("lb-tls-policy", "WARN",
+					"TLS listener uses the default negotiation policy, which still allows TLS 1.0/1.1",
+					fmt.Sprintf("Set %s: ELBSecurityPolicy-TLS13-1-2-2021-06", awsLBSSLPolicy))
+			case strings.Contains(policy, "2016-08") || strings.Contains(policy, "2015") ||
+				strings.Contains(policy, "TLS-1-0") || strings.Contains(policy, "TLS-1-1"):
+				add("lb-tls-policy", "WARN",
+					fmt.Sprintf("TLS negotiation policy %s allows legacy protocol versions", policy),
+					fmt.Sprintf("Set %s: ELBSecurityPolicy-TLS13-1-2-2021-06", awsLBSSLPolicy))
+			}
+		}
+
+	case "gcp":
+		internalFix = fmt.Sprintf(`Set %s: "Internal"`, gkeLBType)
+		t := ann[gkeLBType]
+		if t == "" {
+			t = ann[gkeLBTypeLegacy]
+		}
+		scheme = "internet-facing"
+		if strings.EqualFold(t, "Internal") {
+			scheme = "internal"
+		}
+		if proxyProto {
+			add("lb-proxy-protocol-mismatch", "FAIL",
+				"use-proxy-protocol is enabled but GCP passthrough load balancers do not send PROXY protocol",
+				`Set use-proxy-protocol: "false" in the controller ConfigMap`)
+		}
+
+	case "azure":
+		internalFix = fmt.Sprintf(`Set %s: "true"`, azureLBInternal)
+		scheme = "internet-facing"
+		if ann[azureLBInternal] == "true" {
+			scheme = "internal"
+		}
+		if proxyProto {
+			add("lb-proxy-protocol-mismatch", "WARN",
+				"use-proxy-protocol is enabled but Azure load balancers only send PROXY protocol through Private Link Service",
+				`Verify the traffic path or set use-proxy-protocol: "false"`)
+		}
+	}
+
+	switch {
+	case wantInternal && scheme == "internet-facing" && guessed:
+		// Only the controller or namespace name suggests an internal LB.
+		add("lb-internet-facing", "WARN",
+			"Controller appears to be internal (by its name) but its load balancer is internet-facing",
+			internalFix+", or pass --lb-scheme internet-facing if that is intended")
+	case wantInternal && scheme == "internet-facing":
+		add("lb-internet-facing", "FAIL",
+			"Controller is meant to be internal (--lb-scheme internal) but its load balancer is internet-facing",
+			internalFix)
+	}
+	return scheme, findings
+}
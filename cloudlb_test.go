@@ -0,0 +1,122 @@
+package main
+
+import "testing"
+
+func hasFinding(fs []Finding, id string) bool {
+	for _, f := range fs {
+		if f.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── detectCloudProvider ─────────────────────────────────────────────────────
+
+func TestDetectCloudProvider(t *testing.T) {
+	cases := []struct {
+		ids  []string
+		want string
+	}{
+		{[]string{"aws:///eu-central-1a/i-0abc"}, "aws"},
+		{[]string{"gce://proj/europe-west1-b/node-1"}, "gcp"},
+		{[]string{"azure:///subscriptions/x/resourceGroups/y"}, "azure"},
+		{[]string{"kind://docker/kind/kind-control-plane"}, "kind"},
+		{[]string{"", "no-scheme"}, ""},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := detectCloudProvider(c.ids); got != c.want {
+			t.Errorf("detectCloudProvider(%v) = %q, want %q", c.ids, got, c.want)
+		}
+	}
+}
+
+// ─── wantsInternalLB ─────────────────────────────────────────────────────────
+
+func TestWantsInternalLB(t *testing.T) {
+	if !wantsInternalLB("", "ingress-nginx-internal-controller", "ingress-nginx") {
+		t.Error("name containing 'internal' should be treated as internal")
+	}
+	if wantsInternalLB("internet-facing", "internal-controller", "private") {
+		t.Error("explicit override must win over the name heuristic")
+	}
+	if wantsInternalLB("", "ingress-nginx-controller", "ingress-nginx") {
+		t.Error("plain names should be treated as internet-facing")
+	}
+}
+
+// ─── evaluateLBAnnotations ───────────────────────────────────────────────────
+
+func TestEvaluateLBAnnotations_awsInternalMissingAnnotation(t *testing.T) {
+	scheme, fs := evaluateLBAnnotations("aws", map[string]string{}, "", nil, true, false, "svc")
+	if scheme != "internet-facing" {
+		t.Errorf("scheme = %q, want internet-facing", scheme)
+	}
+	if f, ok := findingByID(fs, "lb-internet-facing"); !ok || f.Level != "FAIL" {
+		t.Errorf("expected lb-internet-facing FAIL, got %+v", fs)
+	}
+	// An intent guessed from names is only a warning.
+	_, fs = evaluateLBAnnotations("aws", map[string]string{}, "", nil, true, true, "svc")
+	if f, ok := findingByID(fs, "lb-internet-facing"); !ok || f.Level != "WARN" {
+		t.Errorf("expected lb-internet-facing WARN, got %+v", fs)
+	}
+}
+
+func TestEvaluateLBAnnotations_awsProxyProtocolMismatch(t *testing.T) {
+	ann := map[string]string{awsLBType: "external", awsLBScheme: "internal", awsLBProxyProto: "*"}
+	scheme, fs := evaluateLBAnnotations("aws", ann, "", map[string]string{}, true, false, "svc")
+	if scheme != "internal" || hasFinding(fs, "lb-internet-facing") {
+		t.Errorf("internal scheme not recognised: %q %+v", scheme, fs)
+	}
+	if !hasFinding(fs, "lb-proxy-protocol-mismatch") {
+		t.Error("PROXY protocol on the LB without use-proxy-protocol should be flagged")
+	}
+	_, fs = evaluateLBAnnotations("aws", ann, "", map[string]string{"use-proxy-protocol": "true"}, true, false, "svc")
+	if hasFinding(fs, "lb-proxy-protocol-mismatch") {
+		t.Error("consistent PROXY protocol settings should not be flagged")
+	}
+}
+
+func TestEvaluateLBAnnotations_awsSchemeNeedsLBController(t *testing.T) {
+	ann := map[string]string{awsLBScheme: "internal"}
+	scheme, fs := evaluateLBAnnotations("aws", ann, "", nil, true, false, "svc")
+	if scheme != "internet-facing" || !hasFinding(fs, "lb-aws-scheme-ignored") || !hasFinding(fs, "lb-internet-facing") {
+		t.Errorf("in-tree provider must ignore the scheme annotation: %q %+v", scheme, fs)
+	}
+	scheme, fs = evaluateLBAnnotations("aws", ann, awsLBCClass, nil, true, false, "svc")
+	if scheme != "internal" || len(fs) != 0 {
+		t.Errorf("loadBalancerClass %s honours the scheme: %q %+v", awsLBCClass, scheme, fs)
+	}
+	ann = map[string]string{awsLBType: "nlb-ip", awsLBScheme: "internet-facing"}
+	if scheme, _ := evaluateLBAnnotations("aws", ann, "", nil, false, false, "svc"); scheme != "internet-facing" {
+		t.Errorf("scheme = %q, want internet-facing", scheme)
+	}
+	ann = map[string]string{awsLBInternal: "true"}
+	if scheme, _ := evaluateLBAnnotations("aws", ann, "", nil, true, false, "svc"); scheme != "internal" {
+		t.Errorf("scheme = %q, want internal", scheme)
+	}
+}
+
+func TestEvaluateLBAnnotations_awsTLSPolicy(t *testing.T) {
+	ann := map[string]string{
+		awsLBSSLCert:   "arn:aws:acm:eu-central-1:123:certificate/abc",
+		awsLBSSLPorts:  "https",
+		awsLBSSLPolicy: "ELBSecurityPolicy-2016-08",
+	}
+	_, fs := evaluateLBAnnotations("aws", ann, "", nil, false, false, "svc")
+	if !hasFinding(fs, "lb-tls-policy") || hasFinding(fs, "lb-tls-ports") {
+		t.Errorf("unexpected TLS findings: %+v", fs)
+	}
+}
+
+func TestEvaluateLBAnnotations_gcpAndForeign(t *testing.T) {
+	ann := map[string]string{gkeLBType: "Internal", awsLBScheme: "internal"}
+	scheme, fs := evaluateLBAnnotations("gcp", ann, "", nil, true, false, "svc")
+	if scheme != "internal" {
+		t.Errorf("scheme = %q, want internal", scheme)
+	}
+	if !hasFinding(fs, "lb-foreign-annotation") {
+		t.Error("AWS annotation on GKE should be reported as having no effect")
+	}
+}
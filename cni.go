@@ -0,0 +1,418 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// CNI-specific policy engines (Cilium, Calico)
+// ─────────────────────────────────────────────
+
+// Policy engine names reported per port in NetPolEvaluation.
+const (
+	engineCiliumNP   = "CiliumNetworkPolicy"
+	engineCiliumCCNP = "CiliumClusterwideNetworkPolicy"
+	engineCalicoNP   = "Calico NetworkPolicy"
+	engineCalicoGNP  = "Calico GlobalNetworkPolicy"
+)
+
+// detectCNI identifies the policy-capable CNI from the names of the
+// DaemonSets running in the cluster. It returns "cilium", "calico" or "".
+func detectCNI(daemonSets []string) string {
+	for _, ds := range daemonSets {
+		switch {
+		case ds == "cilium" || strings.HasPrefix(ds, "cilium-") && !strings.Contains(ds, "operator"):
+			return "cilium"
+		case ds == "calico-node":
+			return "calico"
+		}
+	}
+	return ""
+}
+
+// ── Cilium ──────────────────────────────────────
+
+// ciliumSelector rewrites a Cilium endpoint selector into a plain label
+// selector by dropping the "k8s:"/"any:" source prefixes from its keys.
+func ciliumSelector(sel map[string]interface{}) map[string]interface{} {
+	strip := func(k string) string {
+		for _, p := range []string{"k8s:", "any:"} {
+			k = strings.TrimPrefix(k, p)
+		}
+		return k
+	}
+	labels := map[string]interface{}{}
+	for k, v := range getMap(sel, "matchLabels") {
+		labels[strip(k)] = v
+	}
+	var exprs []interface{}
+	for _, e := range getSlice(sel, "matchExpressions") {
+		em, _ := e.(map[string]interface{})
+		cp := map[string]interface{}{}
+		for k, v := range em {
+			cp[k] = v
+		}
+		cp["key"] = strip(getString(em, "key"))
+		exprs = append(exprs, cp)
+	}
+	return map[string]interface{}{"matchLabels": labels, "matchExpressions": exprs}
+}
+
+// ciliumSpecs returns the rule specs of a Cilium policy (spec and/or specs).
+func ciliumSpecs(policy map[string]interface{}) []map[string]interface{} {
+	var out []map[string]interface{}
+	if spec, ok := policy["spec"].(map[string]interface{}); ok {
+		out = append(out, spec)
+	}
+	for _, s := range getSlice(policy, "specs") {
+		if sm, ok := s.(map[string]interface{}); ok {
+			out = append(out, sm)
+		}
+	}
+	return out
+}
+
+// ciliumRuleCoversPort reports whether the toPorts of a Cilium rule include p.
+func ciliumRuleCoversPort(rule map[string]interface{}, p namedPort) bool {
+	toPorts := getSlice(rule, "toPorts")
+	if len(toPorts) == 0 {
+		return true
+	}
+	for _, tp := range toPorts {
+		tpm, _ := tp.(map[string]interface{})
+		for _, port := range getSlice(tpm, "ports") {
+			pm, _ := port.(map[string]interface{})
+			proto := strings.ToUpper(getString(pm, "protocol"))
+			if proto != "" && proto != "TCP" && proto != "ANY" {
+				continue
+			}
+			if pm["port"] == nil {
+				return true
+			}
+			val := fmt.Sprintf("%v", pm["port"])
+			if val == "0" || val == p.name {
+				return true
+			}
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				continue
+			}
+			end, hasEnd := toInt(pm["endPort"])
+			if n == p.port || (hasEnd && p.port >= n && p.port <= end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ciliumPeers describes the L3 sources of a Cilium ingress rule and returns
+// the broadest scope among them.
+func ciliumPeers(rule map[string]interface{}, ns string, clusterwide bool) ([]string, peerScope) {
+	var out []string
+	scope := scopeCIDR
+	const nsKey = "io.kubernetes.pod.namespace"
+	for _, ep := range getSlice(rule, "fromEndpoints") {
+		epm, _ := ep.(map[string]interface{})
+		sel := ciliumSelector(epm)
+		// A namespaced policy confines endpoint selectors to its namespace
+		// unless they name the namespace label themselves.
+		where := " in " + ns
+		if clusterwide || strings.Contains(describeSelector(sel), nsKey) {
+			where = ""
+		}
+		if where == "" && selectsAll(sel, nsKey) {
+			scope = max(scope, scopeCluster)
+		} else {
+			scope = max(scope, scopeSelector)
+		}
+		out = append(out, fmt.Sprintf("endpoints{%s}%s", describeSelector(sel), where))
+	}
+	for _, e := range getSlice(rule, "fromEntities") {
+		entity := fmt.Sprintf("%v", e)
+		switch entity {
+		case "world", "all":
+			scope = scopeAnywhere
+		case "cluster":
+			scope = max(scope, scopeCluster)
+		case "kube-apiserver", "host", "remote-node":
+		default:
+			scope = max(scope, scopeSelector)
+		}
+		out = append(out, "entity "+entity)
+	}
+	for _, c := range getSlice(rule, "fromCIDR") {
+		cidr := fmt.Sprintf("%v", c)
+		if cidr == "0.0.0.0/0" || cidr == "::/0" {
+			scope = scopeAnywhere
+		}
+		out = append(out, "cidr "+cidr)
+	}
+	for _, c := range getSlice(rule, "fromCIDRSet") {
+		cm, _ := c.(map[string]interface{})
+		desc, s := describePeer(map[string]interface{}{"ipBlock": map[string]interface{}{
+			"cidr": cm["cidr"], "except": cm["except"],
+		}}, ns)
+		scope = max(scope, s)
+		out = append(out, strings.Replace(desc, "ipBlock", "cidr", 1))
+	}
+	if len(out) == 0 {
+		return []string{"anywhere"}, scopeAnywhere
+	}
+	return out, scope
+}
+
+// addCiliumPolicies feeds CiliumNetworkPolicies (namespaced) or
+// CiliumClusterwideNetworkPolicies into acc.
+func (acc *policyAccumulator) addCiliumPolicies(policies []interface{}, podLabels map[string]string, ns string, clusterwide bool) {
+	engine := engineCiliumNP
+	if clusterwide {
+		engine = engineCiliumCCNP
+	}
+	labels := map[string]string{"io.kubernetes.pod.namespace": ns}
+	for k, v := range podLabels {
+		labels[k] = v
+	}
+	for _, p := range policies {
+		pm, _ := p.(map[string]interface{})
+		name := engine + "/" + getString(getMap(pm, "metadata"), "name")
+		for _, spec := range ciliumSpecs(pm) {
+			sel, ok := spec["endpointSelector"].(map[string]interface{})
+			if !ok || !labelSelectorMatches(ciliumSelector(sel), labels) {
+				continue
+			}
+			_, hasIngress := spec["ingress"]
+			_, hasIngressDeny := spec["ingressDeny"]
+			_, hasEgress := spec["egress"]
+			_, hasEgressDeny := spec["egressDeny"]
+			acc.selectPolicy(name, engine, hasIngress || hasIngressDeny, hasEgress || hasEgressDeny)
+			for _, r := range getSlice(spec, "ingress") {
+				rule, _ := r.(map[string]interface{})
+				srcs, scope := ciliumPeers(rule, ns, clusterwide)
+				acc.grant(engine, func(p namedPort) bool { return ciliumRuleCoversPort(rule, p) }, srcs, scope)
+			}
+			for _, r := range getSlice(spec, "egress") {
+				rule, _ := r.(map[string]interface{})
+				if len(getSlice(rule, "toPorts")) > 0 {
+					continue
+				}
+				for _, e := range getSlice(rule, "toEntities") {
+					if e == "world" || e == "all" {
+						acc.egressAllowAll = true
+					}
+				}
+				for _, c := range getSlice(rule, "toCIDR") {
+					if c == "0.0.0.0/0" || c == "::/0" {
+						acc.egressAllowAll = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// ── Calico ──────────────────────────────────────
+
+// calicoSelectorMatches evaluates a Calico selector expression against
+// labels. It supports all(), has(k), !has(k), k == 'v', k != 'v',
+// k in {...} and k not in {...}, combined with && and ||. The second return
+// value is false when the expression uses syntax that is not supported.
+func calicoSelectorMatches(expr string, labels map[string]string) (bool, bool) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return true, true
+	}
+	if strings.ContainsAny(expr, "()") {
+		// Only the function-call forms all()/has() may use parentheses.
+		stripped := strings.NewReplacer("all()", "", "has(", "", ")", "").Replace(expr)
+		if strings.ContainsAny(stripped, "()") {
+			return false, false
+		}
+	}
+	for _, alt := range strings.Split(expr, "||") {
+		all := true
+		for _, term := range strings.Split(alt, "&&") {
+			ok, supported := calicoTermMatches(strings.TrimSpace(term), labels)
+			if !supported {
+				return false, false
+			}
+			if !ok {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// calicoTermMatches evaluates a single comparison of a Calico selector.
+func calicoTermMatches(term string, labels map[string]string) (bool, bool) {
+	unquote := func(s string) string { return strings.Trim(strings.TrimSpace(s), `'"`) }
+	switch {
+	case term == "all()" || term == "global()":
+		return true, true
+	case strings.HasPrefix(term, "!has(") && strings.HasSuffix(term, ")"):
+		_, has := labels[strings.TrimSpace(term[5:len(term)-1])]
+		return !has, true
+	case strings.HasPrefix(term, "has(") && strings.HasSuffix(term, ")"):
+		_, has := labels[strings.TrimSpace(term[4:len(term)-1])]
+		return has, true
+	case strings.Contains(term, "=="):
+		k, v, _ := strings.Cut(term, "==")
+		return labels[strings.TrimSpace(k)] == unquote(v), true
+	case strings.Contains(term, "!="):
+		k, v, _ := strings.Cut(term, "!=")
+		return labels[strings.TrimSpace(k)] != unquote(v), true
+	case strings.Contains(term, " not in "), strings.Contains(term, " in "):
+		negate := strings.Contains(term, " not in ")
+		sep := " in "
+		if negate {
+			sep = " not in "
+		}
+		k, set, _ := strings.Cut(term, sep)
+		val, has := labels[strings.TrimSpace(k)]
+		found := false
+		for _, item := range strings.Split(strings.Trim(strings.TrimSpace(set), "{}"), ",") {
+			if has && unquote(item) == val {
+				found = true
+			}
+		}
+		return found != negate, true
+	}
+	return false, false
+}
+
+// calicoRuleCoversPort reports whether a Calico rule's destination ports
+// include p. Entries may be numbers, "from:to" ranges or named ports.
+func calicoRuleCoversPort(rule map[string]interface{}, p namedPort) bool {
+	if proto, set := rule["protocol"]; set {
+		if p := strings.ToUpper(fmt.Sprintf("%v", proto)); p != "TCP" && p != "6" {
+			return false
+		}
+	}
+	ports := getSlice(getMap(rule, "destination"), "ports")
+	if len(ports) == 0 {
+		return true
+	}
+	for _, port := range ports {
+		val := fmt.Sprintf("%v", port)
+		if val == p.name {
+			return true
+		}
+		if from, to, isRange := strings.Cut(val, ":"); isRange {
+			lo, err1 := strconv.Atoi(from)
+			hi, err2 := strconv.Atoi(to)
+			if err1 == nil && err2 == nil && p.port >= lo && p.port <= hi {
+				return true
+			}
+			continue
+		}
+		if n, ok := toInt(port); ok && n == p.port {
+			return true
+		}
+		if n, err := strconv.Atoi(val); err == nil && n == p.port {
+			return true
+		}
+	}
+	return false
+}
+
+// calicoAll reports whether a Calico selector expression is all().
+func calicoAll(expr string) bool {
+	return strings.ReplaceAll(expr, " ", "") == "all()"
+}
+
+// calicoSources describes the source of a Calico rule and returns the
+// broadest scope among its criteria. A namespaceSelector of all(), or a
+// selector of all() in a GlobalNetworkPolicy, spans the whole cluster.
+func calicoSources(rule map[string]interface{}, global bool) ([]string, peerScope) {
+	src := getMap(rule, "source")
+	var out []string
+	scope := scopeCIDR
+	for _, n := range getSlice(src, "nets") {
+		cidr := fmt.Sprintf("%v", n)
+		if cidr == "0.0.0.0/0" || cidr == "::/0" {
+			scope = scopeAnywhere
+		}
+		out = append(out, "nets "+cidr)
+	}
+	sel, nsSel := getString(src, "selector"), getString(src, "namespaceSelector")
+	switch {
+	case calicoAll(nsSel) && (sel == "" || calicoAll(sel)), global && nsSel == "" && calicoAll(sel):
+		scope = max(scope, scopeCluster)
+	case sel != "" || nsSel != "":
+		scope = max(scope, scopeSelector)
+	}
+	if sel != "" {
+		out = append(out, fmt.Sprintf("selector(%s)", sel))
+	}
+	if nsSel != "" {
+		out = append(out, fmt.Sprintf("namespaceSelector(%s)", nsSel))
+	}
+	if sa := getMap(src, "serviceAccounts"); len(sa) > 0 {
+		scope = max(scope, scopeSelector)
+		out = append(out, fmt.Sprintf("serviceAccounts(%v)", sa["names"]))
+	}
+	if len(out) == 0 {
+		return []string{"anywhere"}, scopeAnywhere
+	}
+	return out, scope
+}
+
+// addCalicoPolicies feeds Calico NetworkPolicies (namespaced) or
+// GlobalNetworkPolicies into acc. It returns the names of policies whose
+// selectors could not be evaluated.
+func (acc *policyAccumulator) addCalicoPolicies(policies []interface{}, podLabels, nsLabels map[string]string, ns string, global bool) []string {
+	engine := engineCalicoNP
+	if global {
+		engine = engineCalicoGNP
+	}
+	labels := map[string]string{"projectcalico.org/namespace": ns}
+	for k, v := range podLabels {
+		labels[k] = v
+	}
+	var unsupported []string
+	for _, p := range policies {
+		pm, _ := p.(map[string]interface{})
+		name := getString(getMap(pm, "metadata"), "name")
+		spec := getMap(pm, "spec")
+		match, ok := calicoSelectorMatches(getString(spec, "selector"), labels)
+		if global && ok && match {
+			match, ok = calicoSelectorMatches(getString(spec, "namespaceSelector"), nsLabels)
+		}
+		if !ok {
+			unsupported = append(unsupported, engine+"/"+name)
+			continue
+		}
+		if !match {
+			continue
+		}
+		types := getSlice(spec, "types")
+		ingress, egress := len(types) == 0, len(types) == 0 && spec["egress"] != nil
+		for _, t := range types {
+			ingress = ingress || t == "Ingress"
+			egress = egress || t == "Egress"
+		}
+		acc.selectPolicy(engine+"/"+name, engine, ingress, egress)
+		for _, r := range getSlice(spec, "ingress") {
+			rule, _ := r.(map[string]interface{})
+			if getString(rule, "action") != "Allow" {
+				continue
+			}
+			srcs, scope := calicoSources(rule, global)
+			acc.grant(engine, func(p namedPort) bool { return calicoRuleCoversPort(rule, p) }, srcs, scope)
+		}
+		for _, r := range getSlice(spec, "egress") {
+			rule, _ := r.(map[string]interface{})
+			if getString(rule, "action") == "Allow" && len(getMap(rule, "destination")) == 0 {
+				acc.egressAllowAll = true
+			}
+		}
+	}
+	return unsupported
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// ─── detectCNI ───────────────────────────────────────────────────────────────
+
+func TestDetectCNI(t *testing.T) {
+	cases := []struct {
+		ds   []string
+		want string
+	}{
+		{[]string{"kube-proxy", "cilium", "cilium-envoy"}, "cilium"},
+		{[]string{"calico-node", "csi-node"}, "calico"},
+		{[]string{"kube-proxy", "aws-node"}, ""},
+	}
+	for _, c := range cases {
+		if got := detectCNI(c.ds); got != c.want {
+			t.Errorf("detectCNI(%v) = %q, want %q", c.ds, got, c.want)
+		}
+	}
+}
+
+// ─── calicoSelectorMatches ───────────────────────────────────────────────────
+
+func TestCalicoSelectorMatches(t *testing.T) {
+	labels := map[string]string{"app.kubernetes.io/name": "ingress-nginx", "tier": "edge"}
+	cases := []struct {
+		expr        string
+		want, valid bool
+	}{
+		{"", true, true},
+		{"all()", true, true},
+		{"app.kubernetes.io/name == 'ingress-nginx'", true, true},
+		{"app.kubernetes.io/name == 'web'", false, true},
+		{"has(tier) && tier != 'db'", true, true},
+		{"!has(tier)", false, true},
+		{"tier in {'edge', 'lb'}", true, true},
+		{"tier not in {'edge'}", false, true},
+		{"tier == 'x' || has(app.kubernetes.io/name)", true, true},
+		{"(tier == 'edge')", false, false},
+	}
+	for _, c := range cases {
+		got, valid := calicoSelectorMatches(c.expr, labels)
+		if got != c.want || valid != c.valid {
+			t.Errorf("calicoSelectorMatches(%q) = %v,%v want %v,%v", c.expr, got, valid, c.want, c.valid)
+		}
+	}
+}
+
+// ─── policyAccumulator with CNI policies ─────────────────────────────────────
+
+func TestAddCiliumPolicies_protectsWebhook(t *testing.T) {
+	policies := decodePolicies(t, `[{
+		"metadata":{"name":"ingress-nginx"},
+		"spec":{
+			"endpointSelector":{"matchLabels":{"k8s:app.kubernetes.io/name":"ingress-nginx"}},
+			"ingress":[
+				{"fromEntities":["world"],"toPorts":[{"ports":[{"port":"443","protocol":"TCP"}]}]},
+				{"fromEntities":["kube-apiserver"],"toPorts":[{"ports":[{"port":"8443","protocol":"TCP"}]}]}
+			]
+		}
+	}]`)
+	acc := newPolicyAccumulator(controllerPolicyPorts(nil))
+	acc.addCiliumPolicies(policies, ctrlLabels, "ingress-nginx", false)
+	ev := acc.result()
+	if !ev.IngressIsolated || len(ev.Engines) != 1 || ev.Engines[0] != engineCiliumNP {
+		t.Fatalf("expected isolation by %s, got %+v", engineCiliumNP, ev)
+	}
+	wh := findPort(ev, "webhook")
+	if wh.Anywhere || len(wh.Sources) != 1 || wh.Sources[0] != "entity kube-apiserver" {
+		t.Errorf("webhook = %+v", wh)
+	}
+	if !findPort(ev, "https").Anywhere {
+		t.Error("https should be open to world")
+	}
+	if m := findPort(ev, "metrics"); m.Anywhere || len(m.Sources) != 0 {
+		t.Errorf("metrics should be denied, got %+v", m)
+	}
+}
+
+func TestAddCiliumPolicies_egressToWorldCIDR(t *testing.T) {
+	policies := decodePolicies(t, `[{
+		"metadata":{"name":"egress"},
+		"spec":{
+			"endpointSelector":{},
+			"egress":[{"toCIDR":["0.0.0.0/0"]}]
+		}
+	}]`)
+	acc := newPolicyAccumulator(controllerPolicyPorts(nil))
+	acc.addCiliumPolicies(policies, ctrlLabels, "ingress-nginx", false)
+	if acc.result().EgressRestricted {
+		t.Error("egress toCIDR 0.0.0.0/0 must not count as restricted")
+	}
+}
+
+func TestAddCalicoPolicies_globalPolicy(t *testing.T) {
+	policies := decodePolicies(t, `[{
+		"metadata":{"name":"protect-ingress"},
+		"spec":{
+			"selector":"app.kubernetes.io/name == 'ingress-nginx'",
+			"namespaceSelector":"kubernetes.io/metadata.name == 'ingress-nginx'",
+			"types":["Ingress"],
+			"ingress":[
+				{"action":"Allow","protocol":"TCP","destination":{"ports":[80,443]}},
+				{"action":"Allow","protocol":"TCP","source":{"nets":["10.0.0.0/24"]},"destination":{"ports":["8000:9000"]}},
+				{"action":"Deny"}
+			]
+		}
+	}]`)
+	acc := newPolicyAccumulator(controllerPolicyPorts(nil))
+	skipped := acc.addCalicoPolicies(policies, ctrlLabels,
+		map[string]string{"kubernetes.io/metadata.name": "ingress-nginx"}, "ingress-nginx", true)
+	ev := acc.result()
+	if len(skipped) != 0 || !ev.IngressIsolated {
+		t.Fatalf("policy should select the controller: skipped=%v ev=%+v", skipped, ev)
+	}
+	wh := findPort(ev, "webhook")
+	if wh.Anywhere || len(wh.Sources) != 1 || wh.Sources[0] != "nets 10.0.0.0/24" || wh.Engines[0] != engineCalicoGNP {
+		t.Errorf("webhook = %+v", wh)
+	}
+}
+
+func TestCNISourcesClusterWide(t *testing.T) {
+	cilium := []struct {
+		rule        string
+		clusterwide bool
+		want        peerScope
+	}{
+		{`{"fromEntities":["cluster"]}`, false, scopeCluster},
+		{`{"fromEntities":["kube-apiserver","remote-node"]}`, false, scopeCIDR},
+		{`{"fromEndpoints":[{}]}`, true, scopeCluster},
+		{`{"fromEndpoints":[{}]}`, false, scopeSelector},
+		{`{"fromEndpoints":[{"matchExpressions":[{"key":"k8s:io.kubernetes.pod.namespace","operator":"Exists"}]}]}`, false, scopeCluster},
+		{`{"fromEndpoints":[{"matchLabels":{"app":"prometheus"}}]}`, true, scopeSelector},
+	}
+	for _, c := range cilium {
+		var rule map[string]interface{}
+		_ = json.Unmarshal([]byte(c.rule), &rule)
+		if _, got := ciliumPeers(rule, "ingress-nginx", c.clusterwide); got != c.want {
+			t.Errorf("ciliumPeers(%s, clusterwide=%v) scope = %d, want %d", c.rule, c.clusterwide, got, c.want)
+		}
+	}
+
+	calico := []struct {
+		source string
+		global bool
+		want   peerScope
+	}{
+		{`{"selector":"all()"}`, true, scopeCluster},
+		{`{"selector":"all()"}`, false, scopeSelector},
+		{`{"namespaceSelector":"all()"}`, false, scopeCluster},
+		{`{"selector":"all()","namespaceSelector":"all()"}`, false, scopeCluster},
+		{`{"selector":"app == 'prometheus'","namespaceSelector":"all()"}`, false, scopeSelector},
+		{`{"nets":["10.0.0.0/24"]}`, true, scopeCIDR},
+	}
+	for _, c := range calico {
+		var src map[string]interface{}
+		_ = json.Unmarshal([]byte(c.source), &src)
+		if _, got := calicoSources(map[string]interface{}{"source": src}, c.global); got != c.want {
+			t.Errorf("calicoSources(%s, global=%v) scope = %d, want %d", c.source, c.global, got, c.want)
+		}
+	}
+}
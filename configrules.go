@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// ConfigMap hardening rules
+// ─────────────────────────────────────────────
+
+// defaultSSLCiphers is the controller's built-in ssl-ciphers value.
+const defaultSSLCiphers = "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:" +
+	"ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384:" +
+	"ECDHE-ECDSA-CHACHA20-POLY1305:ECDHE-RSA-CHACHA20-POLY1305:" +
+	"DHE-RSA-AES128-GCM-SHA256:DHE-RSA-AES256-GCM-SHA384"
+
+// legacyWordBlocklist is the annotation-value-word-blocklist default shipped
+// before v1.9.0.
+const legacyWordBlocklist = "load_module,lua_package,_by_lua,location,root,proxy_pass,serviceaccount,{,},',\""
+
+// controllerDefault returns the value the controller uses for a ConfigMap
+// key when it is unset, for the given controller version. ok is false when
+// the key is not supported by that version.
+func controllerDefault(key, version string) (value string, ok bool) {
+	switch key {
+	case "allow-snippet-annotations":
+		if versionAtLeast(version, "v1.9.0") {
+			return "false", true
+		}
+		return "true", true
+	case "annotations-risk-level":
+		switch {
+		case versionAtLeast(version, "v1.12.0"):
+			return "High", true
+		case versionAtLeast(version, "v1.10.0"):
+			return "Critical", true
+		}
+		return "", false
+	case "annotation-value-word-blocklist":
+		if versionAtLeast(version, "v1.9.0") {
+			return "", true
+		}
+		return legacyWordBlocklist, true
+	case "strict-validate-path-type":
+		switch {
+		case versionAtLeast(version, "v1.12.0"):
+			return "true", true
+		case versionAtLeast(version, "v1.8.0"):
+			return "false", true
+		}
+		return "", false
+	case "allow-cross-namespace-resources":
+		if versionAtLeast(version, "v1.12.0") {
+			return "false", true
+		}
+		return "", false
+	case "hsts", "hsts-include-subdomains":
+		return "true", true
+	case "hsts-max-age":
+		return "31536000", true
+	case "hsts-preload", "server-tokens", "enable-underscores-in-headers",
+		"use-forwarded-headers", "compute-full-forwarded-for", "ssl-session-tickets",
+		"use-proxy-protocol":
+		return "false", true
+	case "ssl-prefer-server-ciphers":
+		return "true", true
+	case "proxy-real-ip-cidr":
+		return "0.0.0.0/0", true
+	case "ssl-protocols":
+		return "TLSv1.2 TLSv1.3", true
+	case "ssl-ciphers":
+		return defaultSSLCiphers, true
+	case "ssl-ecdh-curve":
+		return "auto", true
+	case "worker-shutdown-timeout":
+		return "240s", true
+	case "hide-headers":
+		return "", true
+	}
+	return "", false
+}
+
+// effectiveConfig returns the value of key in cm, falling back to the
+// controller default for version. set reports whether the key was present.
+func effectiveConfig(cm map[string]string, key, version string) (value string, set bool) {
+	if v, ok := cm[key]; ok {
+		return v, true
+	}
+	v, _ := controllerDefault(key, version)
+	return v, false
+}
+
+// configRule is one hardening check over the controller ConfigMap.
+type configRule struct {
+	ID        string
+	Keys      []string
+	Rationale string
+	// Check returns "" when the rule passes, otherwise the finding level
+	// ("FAIL", "WARN", "INFO"), message and remediation.
+	Check func(cm map[string]string, version string) (level, msg, remediation string)
+}
+
+// weakCipherMarkers identify OpenSSL cipher suite names that must not be offered.
+var weakCipherMarkers = []string{"RC4", "DES", "MD5", "NULL", "EXPORT", "EXP-", "PSK", "SRP", "IDEA", "SEED", "CAMELLIA"}
+
+// weakCiphers returns the enabled entries of an OpenSSL cipher string that
+// match weakCipherMarkers. Entries prefixed with "!" or "-" are exclusions.
+func weakCiphers(ciphers string) []string {
+	var weak []string
+	for _, c := range strings.Split(ciphers, ":") {
+		c = strings.TrimSpace(c)
+		if c == "" || strings.HasPrefix(c, "!") || strings.HasPrefix(c, "-") {
+			continue
+		}
+		for _, m := range weakCipherMarkers {
+			if strings.Contains(strings.ToUpper(c), m) {
+				weak = append(weak, c)
+				break
+			}
+		}
+	}
+	return weak
+}
+
+// isCipherKeyword reports whether an entry of an OpenSSL cipher string is a
+// keyword (HIGH, aNULL, ECDHE+AESGCM, @STRENGTH, ...) rather than a suite name.
+// Suite names always contain "-" or "_"; keywords and their "+" combinations
+// never do.
+func isCipherKeyword(entry string) bool {
+	entry = strings.TrimLeft(entry, "!-+")
+	return strings.HasPrefix(entry, "@") || strings.Contains(entry, "+") ||
+		!strings.ContainsAny(entry, "-_")
+}
+
+// configRules is the ConfigMap hardening rule set, evaluated in order.
+var configRules = []configRule{
+	{
+		ID:        "cm-annotations-risk-level",
+		Keys:      []string{"annotations-risk-level"},
+		Rationale: "Annotations above this risk level are rejected; Critical admits snippet and other injection-prone annotations.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			level, _ := effectiveConfig(cm, "annotations-risk-level", v)
+			switch {
+			case level == "":
+				return "INFO", fmt.Sprintf("annotations-risk-level is not supported by %s", v),
+					"Upgrade to v1.10+ to gate annotations by risk level (v1.12+ defaults to High)"
+			case strings.EqualFold(level, "Critical"):
+				return "WARN", "annotations-risk-level is Critical — every annotation, including snippets, is accepted",
+					`Set annotations-risk-level: "High" (or "Medium" if no High-risk annotations are needed)`
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-annotation-value-word-blocklist",
+		Keys:      []string{"annotation-value-word-blocklist", "allow-snippet-annotations"},
+		Rationale: "With snippets enabled, the blocklist is the only filter against NGINX directive injection through annotation values.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			snippets, _ := effectiveConfig(cm, "allow-snippet-annotations", v)
+			blocklist, _ := effectiveConfig(cm, "annotation-value-word-blocklist", v)
+			if snippets == "true" && strings.TrimSpace(blocklist) == "" {
+				return "WARN", "Snippet annotations are allowed with an empty annotation-value-word-blocklist",
+					"Set annotation-value-word-blocklist: " + legacyWordBlocklist
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-strict-validate-path-type",
+		Keys:      []string{"strict-validate-path-type"},
+		Rationale: "Without strict validation, Exact/Prefix paths may contain regex characters that are injected into the NGINX configuration.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			val, _ := effectiveConfig(cm, "strict-validate-path-type", v)
+			switch val {
+			case "":
+				return "INFO", fmt.Sprintf("strict-validate-path-type is not supported by %s", v),
+					"Upgrade to v1.8+ and enable strict-validate-path-type"
+			case "false":
+				return "WARN", "strict-validate-path-type is disabled",
+					`Set strict-validate-path-type: "true"`
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-allow-cross-namespace-resources",
+		Keys:      []string{"allow-cross-namespace-resources"},
+		Rationale: "Cross-namespace references let an Ingress author read secrets and ConfigMaps from namespaces they do not control.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			val, _ := effectiveConfig(cm, "allow-cross-namespace-resources", v)
+			switch val {
+			case "":
+				return "INFO", fmt.Sprintf("%s always allows cross-namespace resource references", v),
+					"Upgrade to v1.12+ to disable cross-namespace references"
+			case "true":
+				return "WARN", "allow-cross-namespace-resources is enabled",
+					`Set allow-cross-namespace-resources: "false"`
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-server-tokens",
+		Keys:      []string{"server-tokens"},
+		Rationale: "The NGINX version in the Server header and error pages helps attackers pick exploits.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			if val, _ := effectiveConfig(cm, "server-tokens", v); val == "true" {
+				return "WARN", "server-tokens is enabled — NGINX version is disclosed", `Set server-tokens: "false"`
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-hide-headers",
+		Keys:      []string{"hide-headers"},
+		Rationale: "Upstream headers such as X-Powered-By disclose backend frameworks and versions.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			if val, _ := effectiveConfig(cm, "hide-headers", v); strings.TrimSpace(val) == "" {
+				return "INFO", "hide-headers is empty — upstream technology headers are passed to clients",
+					`Set hide-headers: "X-Powered-By,X-AspNet-Version,X-AspNetMvc-Version"`
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-hsts",
+		Keys:      []string{"hsts", "hsts-max-age", "hsts-include-subdomains", "hsts-preload"},
+		Rationale: "HSTS prevents protocol downgrade and cookie hijacking; a short max-age weakens it.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			if val, _ := effectiveConfig(cm, "hsts", v); val != "true" {
+				return "WARN", "HSTS is disabled", `Set hsts: "true"`
+			}
+			age, _ := effectiveConfig(cm, "hsts-max-age", v)
+			if n, err := strconv.Atoi(age); err != nil || n < 15768000 {
+				return "WARN", fmt.Sprintf("hsts-max-age %s is shorter than 6 months", age),
+					`Set hsts-max-age: "31536000" (or "63072000")`
+			}
+			if val, _ := effectiveConfig(cm, "hsts-include-subdomains", v); val != "true" {
+				return "INFO", "HSTS does not include subdomains", `Set hsts-include-subdomains: "true"`
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-enable-underscores-in-headers",
+		Keys:      []string{"enable-underscores-in-headers"},
+		Rationale: "Headers with underscores are normalised inconsistently by proxies and backends, enabling header smuggling.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			if val, _ := effectiveConfig(cm, "enable-underscores-in-headers", v); val == "true" {
+				return "WARN", "enable-underscores-in-headers is enabled", `Set enable-underscores-in-headers: "false"`
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-forwarded-headers",
+		Keys:      []string{"use-forwarded-headers", "compute-full-forwarded-for", "proxy-real-ip-cidr"},
+		Rationale: "Trusting X-Forwarded-* from any source lets clients spoof their IP past allowlists, rate limits and logs.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			fwd, _ := effectiveConfig(cm, "use-forwarded-headers", v)
+			full, _ := effectiveConfig(cm, "compute-full-forwarded-for", v)
+			cidr, _ := effectiveConfig(cm, "proxy-real-ip-cidr", v)
+			switch {
+			case fwd == "true" && (cidr == "0.0.0.0/0" || cidr == ""):
+				return "WARN", "use-forwarded-headers trusts X-Forwarded-For from any source (proxy-real-ip-cidr is 0.0.0.0/0)",
+					"Set proxy-real-ip-cidr to the CIDR(s) of the fronting load balancer/proxy"
+			case full == "true" && fwd != "true":
+				return "INFO", "compute-full-forwarded-for has no effect without use-forwarded-headers",
+					`Remove compute-full-forwarded-for or set use-forwarded-headers: "true"`
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-ssl-protocols",
+		Keys:      []string{"ssl-protocols"},
+		Rationale: "SSLv3, TLS 1.0 and TLS 1.1 are deprecated (RFC 8996) and vulnerable to downgrade attacks.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			val, _ := effectiveConfig(cm, "ssl-protocols", v)
+			var legacy []string
+			for _, p := range strings.Fields(val) {
+				if p == "SSLv2" || p == "SSLv3" || p == "TLSv1" || p == "TLSv1.1" {
+					legacy = append(legacy, p)
+				}
+			}
+			if len(legacy) > 0 {
+				return "WARN", fmt.Sprintf("ssl-protocols enables legacy protocol(s): %s", strings.Join(legacy, " ")),
+					`Set ssl-protocols: "TLSv1.2 TLSv1.3"`
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-ssl-ciphers",
+		Keys:      []string{"ssl-ciphers"},
+		Rationale: "RC4, DES/3DES, MD5, NULL and export-grade suites are broken or provide no forward secrecy.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			val, _ := effectiveConfig(cm, "ssl-ciphers", v)
+			if weak := weakCiphers(val); len(weak) > 0 {
+				return "WARN", fmt.Sprintf("ssl-ciphers enables weak suite(s): %s", strings.Join(weak, ", ")),
+					"Remove the ssl-ciphers key to use the controller default, or use the Mozilla intermediate list"
+			}
+			return "", "", ""
+		},
+	},
+	{
+		ID:        "cm-ssl-session-tickets",
+		Keys:      []string{"ssl-session-tickets"},
+		Rationale: "Session ticket keys are rarely rotated, which undermines forward secrecy of resumed sessions.",
+		Check: func(cm map[string]string, v string) (string, string, string) {
+			if val, _ := effectiveConfig(cm, "ssl-session-tickets", v); val == "true" {
+				return "WARN", "ssl-session-tickets is enabled", `Set ssl-session-tickets: "false"`
+			}
+			return "", "", ""
+		},
+	},
+}
@@ -0,0 +1,140 @@
+package main
+
+import "testing"
+
+// ruleLevel runs the rule with the given ID and returns its level ("" = pass).
+func ruleLevel(t *testing.T, id string, cm map[string]string, version string) string {
+	t.Helper()
+	for _, r := range configRules {
+		if r.ID == id {
+			level, _, _ := r.Check(cm, version)
+			return level
+		}
+	}
+	t.Fatalf("rule %s not found", id)
+	return ""
+}
+
+// ─── controllerDefault ───────────────────────────────────────────────────────
+
+func TestControllerDefaultVersionAware(t *testing.T) {
+	cases := []struct {
+		key, version, want string
+		ok                 bool
+	}{
+		{"annotations-risk-level", "v1.9.6", "", false},
+		{"annotations-risk-level", "v1.11.3", "Critical", true},
+		{"annotations-risk-level", "v1.12.0", "High", true},
+		{"strict-validate-path-type", "v1.7.1", "", false},
+		{"strict-validate-path-type", "v1.11.0", "false", true},
+		{"strict-validate-path-type", "v1.12.1", "true", true},
+		{"allow-snippet-annotations", "v1.8.4", "true", true},
+		{"allow-snippet-annotations", "v1.9.0", "false", true},
+		{"allow-cross-namespace-resources", "v1.12.0", "false", true},
+		{"ssl-prefer-server-ciphers", "v1.12.0", "true", true},
+		{"no-such-key", "v1.12.0", "", false},
+	}
+	for _, c := range cases {
+		got, ok := controllerDefault(c.key, c.version)
+		if got != c.want || ok != c.ok {
+			t.Errorf("controllerDefault(%q, %q) = %q, %v; want %q, %v", c.key, c.version, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestEffectiveConfigPrefersConfigMap(t *testing.T) {
+	cm := map[string]string{"hsts": "false"}
+	if v, set := effectiveConfig(cm, "hsts", "v1.12.0"); v != "false" || !set {
+		t.Errorf("got %q, %v; want configured value", v, set)
+	}
+	if v, set := effectiveConfig(cm, "server-tokens", "v1.12.0"); v != "false" || set {
+		t.Errorf("got %q, %v; want default", v, set)
+	}
+}
+
+// ─── weakCiphers ─────────────────────────────────────────────────────────────
+
+func TestWeakCiphers(t *testing.T) {
+	if w := weakCiphers(defaultSSLCiphers); len(w) != 0 {
+		t.Errorf("default ciphers flagged as weak: %v", w)
+	}
+	w := weakCiphers("ECDHE-RSA-AES128-GCM-SHA256:RC4-SHA:!MD5:DES-CBC3-SHA:-NULL")
+	if len(w) != 2 || w[0] != "RC4-SHA" || w[1] != "DES-CBC3-SHA" {
+		t.Errorf("weakCiphers = %v, want [RC4-SHA DES-CBC3-SHA]", w)
+	}
+}
+
+// ─── configRules ─────────────────────────────────────────────────────────────
+
+func TestConfigRulesDefaultsPass(t *testing.T) {
+	for _, r := range configRules {
+		if r.ID == "cm-hide-headers" {
+			continue
+		}
+		if level, msg, _ := r.Check(map[string]string{}, "v1.12.1"); level != "" {
+			t.Errorf("%s: v1.12.1 defaults should pass, got %s: %s", r.ID, level, msg)
+		}
+	}
+}
+
+func TestConfigRulesVersionDefaults(t *testing.T) {
+	empty := map[string]string{}
+	if got := ruleLevel(t, "cm-annotations-risk-level", empty, "v1.11.0"); got != "WARN" {
+		t.Errorf("v1.11 risk level default Critical: got %q, want WARN", got)
+	}
+	if got := ruleLevel(t, "cm-annotations-risk-level", empty, "v1.9.6"); got != "INFO" {
+		t.Errorf("pre-v1.10 risk level unsupported: got %q, want INFO", got)
+	}
+	if got := ruleLevel(t, "cm-strict-validate-path-type", empty, "v1.10.0"); got != "WARN" {
+		t.Errorf("v1.10 strict-validate-path-type default false: got %q, want WARN", got)
+	}
+	if got := ruleLevel(t, "cm-allow-cross-namespace-resources", empty, "v1.11.0"); got != "INFO" {
+		t.Errorf("pre-v1.12 cross-namespace: got %q, want INFO", got)
+	}
+}
+
+func TestConfigRulesSSLProtocols(t *testing.T) {
+	cases := []struct {
+		protocols, want string
+	}{
+		{"TLSv1.2 TLSv1.3", ""},
+		{"TLSv1 TLSv1.2", "WARN"},
+		{"TLSv1.2 TLSv1", "WARN"}, // trailing token missed by a substring check
+		{"TLSv1.1 TLSv1.2", "WARN"},
+		{"TLSv1.3", ""},
+	}
+	for _, c := range cases {
+		cm := map[string]string{"ssl-protocols": c.protocols}
+		if got := ruleLevel(t, "cm-ssl-protocols", cm, "v1.12.0"); got != c.want {
+			t.Errorf("ssl-protocols %q: got %q, want %q", c.protocols, got, c.want)
+		}
+	}
+}
+
+func TestConfigRulesForwardedHeaders(t *testing.T) {
+	cm := map[string]string{"use-forwarded-headers": "true"}
+	if got := ruleLevel(t, "cm-forwarded-headers", cm, "v1.12.0"); got != "WARN" {
+		t.Errorf("untrusted forwarded headers: got %q, want WARN", got)
+	}
+	cm["proxy-real-ip-cidr"] = "10.0.0.0/8"
+	if got := ruleLevel(t, "cm-forwarded-headers", cm, "v1.12.0"); got != "" {
+		t.Errorf("restricted proxy-real-ip-cidr: got %q, want pass", got)
+	}
+}
+
+func TestConfigRulesHSTS(t *testing.T) {
+	cm := map[string]string{"hsts-max-age": "3600"}
+	if got := ruleLevel(t, "cm-hsts", cm, "v1.12.0"); got != "WARN" {
+		t.Errorf("short hsts-max-age: got %q, want WARN", got)
+	}
+}
+
+func TestConfigRulesWordBlocklist(t *testing.T) {
+	cm := map[string]string{"allow-snippet-annotations": "true"}
+	if got := ruleLevel(t, "cm-annotation-value-word-blocklist", cm, "v1.11.0"); got != "WARN" {
+		t.Errorf("snippets with empty blocklist: got %q, want WARN", got)
+	}
+	if got := ruleLevel(t, "cm-annotation-value-word-blocklist", cm, "v1.8.0"); got != "" {
+		t.Errorf("pre-v1.9 built-in blocklist: got %q, want pass", got)
+	}
+}
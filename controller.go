@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Controller workload lookups
+// ─────────────────────────────────────────────
+
+// controllerResType returns the kubectl resource type of the controller
+// workload, defaulting to "deployment" when discovery did not find one.
+func (a *AuditState) controllerResType() string {
+	if a.DeploymentType == "" {
+		return "deployment"
+	}
+	return strings.ToLower(a.DeploymentType)
+}
+
+// controllerWorkload returns the controller Deployment/DaemonSet object.
+// It is fetched once per audit; an empty map is returned when unavailable.
+func (a *AuditState) controllerWorkload() map[string]interface{} {
+	if a.workload == nil {
+		a.workload, _ = kubectlJSON("get", a.controllerResType(), "-n", a.Namespace, a.ControllerName)
+		if a.workload == nil {
+			a.workload = map[string]interface{}{}
+		}
+	}
+	return a.workload
+}
+
+// controllerPodSpec returns spec.template.spec of the controller workload.
+func (a *AuditState) controllerPodSpec() map[string]interface{} {
+	return getMap(getMap(getMap(a.controllerWorkload(), "spec"), "template"), "spec")
+}
+
+// controllerPodLabels returns the labels stamped onto controller pods.
+func (a *AuditState) controllerPodLabels() map[string]string {
+	tmpl := getMap(getMap(a.controllerWorkload(), "spec"), "template")
+	return getStringMap(getMap(tmpl, "metadata"), "labels")
+}
+
+// controllerPodSelector returns the workload's matchLabels as a kubectl
+// label selector ("k1=v1,k2=v2").
+func (a *AuditState) controllerPodSelector() string {
+	match := getStringMap(getMap(getMap(a.controllerWorkload(), "spec"), "selector"), "matchLabels")
+	var sel []string
+	for k, v := range match {
+		sel = append(sel, k+"="+v)
+	}
+	sort.Strings(sel)
+	return strings.Join(sel, ",")
+}
+
+// controllerContainer returns the container named "controller", falling
+// back to the first container of the pod template.
+func (a *AuditState) controllerContainer() map[string]interface{} {
+	containers := getSlice(a.controllerPodSpec(), "containers")
+	for _, c := range containers {
+		cm, _ := c.(map[string]interface{})
+		if getString(cm, "name") == "controller" {
+			return cm
+		}
+	}
+	if len(containers) > 0 {
+		if cm, ok := containers[0].(map[string]interface{}); ok {
+			return cm
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// controllerContainerPorts maps the named container ports of the controller
+// container (http, https, webhook, metrics, ...) to their numbers.
+func (a *AuditState) controllerContainerPorts() map[string]int {
+	ports := map[string]int{}
+	for _, p := range getSlice(a.controllerContainer(), "ports") {
+		pm, _ := p.(map[string]interface{})
+		if n, ok := toInt(pm["containerPort"]); ok && getString(pm, "name") != "" {
+			ports[getString(pm, "name")] = n
+		}
+	}
+	return ports
+}
+
+// controllerArgs returns the parsed command line of the controller
+// container, fetched once per audit.
+func (a *AuditState) controllerArgs() map[string]string {
+	if a.args == nil {
+		c := a.controllerContainer()
+		var argv []string
+		for _, v := range append(getSlice(c, "command"), getSlice(c, "args")...) {
+			argv = append(argv, fmt.Sprintf("%v", v))
+		}
+		a.args = parseControllerArgs(argv)
+	}
+	return a.args
+}
+
+// controllerConfigMap returns the data of the controller ConfigMap, fetched
+// once per audit. An empty map is returned when it does not exist.
+func (a *AuditState) controllerConfigMap() map[string]string {
+	if a.configMap == nil {
+		cm, _ := kubectlJSON("get", "configmap", "-n", a.Namespace, a.ControllerName)
+		a.configMap = getStringMap(cm, "data")
+	}
+	return a.configMap
+}
+
+// namespaceLabels returns the labels of the audited namespace, fetched once
+// per audit.
+func (a *AuditState) namespaceLabels() map[string]string {
+	if a.nsLabels == nil {
+		ns, _ := kubectlJSON("get", "namespace", a.Namespace)
+		a.nsLabels = getStringMap(getMap(ns, "metadata"), "labels")
+	}
+	return a.nsLabels
+}
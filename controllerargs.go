@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Controller command-line arguments
+// ─────────────────────────────────────────────
+
+// boolControllerFlags are controller flags that take no separate value
+// argument ("--flag" alone means true).
+var boolControllerFlags = map[string]bool{
+	"enable-ssl-passthrough":          true,
+	"enable-metrics":                  true,
+	"enable-annotation-validation":    true,
+	"watch-ingress-without-class":     true,
+	"update-status":                   true,
+	"enable-ssl-chain-completion":     true,
+	"enable-topology-aware-routing":   true,
+	"disable-catch-all":               true,
+	"report-node-internal-ip-address": true,
+}
+
+// parseControllerArgs parses the controller command line into a flag map.
+// Both "--flag=value" and "--flag value" are accepted; boolean flags given
+// without a value map to "true". Non-flag tokens (the binary path) are
+// ignored and a repeated flag keeps its last value.
+func parseControllerArgs(argv []string) map[string]string {
+	args := map[string]string{}
+	for i := 0; i < len(argv); i++ {
+		tok := strings.TrimSpace(argv[i])
+		if !strings.HasPrefix(tok, "-") {
+			continue
+		}
+		name := strings.TrimLeft(tok, "-")
+		if name == "" {
+			continue
+		}
+		if k, v, ok := strings.Cut(name, "="); ok {
+			args[k] = v
+			continue
+		}
+		if !boolControllerFlags[name] && i+1 < len(argv) && !strings.HasPrefix(argv[i+1], "-") {
+			args[name] = argv[i+1]
+			i++
+			continue
+		}
+		args[name] = "true"
+	}
+	return args
+}
+
+// expandPodNamespace substitutes the $(POD_NAMESPACE) reference used by the
+// Helm chart with the controller namespace.
+func expandPodNamespace(v, ns string) string {
+	return strings.ReplaceAll(v, "$(POD_NAMESPACE)", ns)
+}
+
+// argEnabled reports whether a boolean controller flag is on, using def
+// when it is not set.
+func argEnabled(args map[string]string, name string, def bool) bool {
+	v, ok := args[name]
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
+
+// evaluateControllerArgs checks the security-relevant controller flags and
+// their combination with the ConfigMap. Checks that need the cluster
+// (referenced Services and ConfigMaps, NetworkPolicy exposure) are done by
+// auditControllerArgs.
+func evaluateControllerArgs(args, cm map[string]string, version, res string) []Finding {
+	var findings []Finding
+	add := func(id, level, msg, rationale, fix string) {
+		findings = append(findings, Finding{ID: id, Level: level, Resource: res,
+			Message: msg, Rationale: rationale, Remediation: fix})
+	}
+	snippets, _ := effectiveConfig(cm, "allow-snippet-annotations", version)
+
+	if argEnabled(args, "enable-ssl-passthrough", false) {
+		add("args-ssl-passthrough", "WARN",
+			"--enable-ssl-passthrough is set",
+			"Passthrough hosts are proxied at TCP level before NGINX, so auth, allowlist, rate-limit and WAF annotations do not apply to them.",
+			"Remove --enable-ssl-passthrough unless a backend must terminate TLS itself")
+	}
+
+	if args["watch-namespace"] == "" && args["watch-namespace-selector"] == "" {
+		if snippets == "true" {
+			add("args-cluster-wide-snippets", "WARN",
+				"Controller watches every namespace and snippet annotations are allowed",
+				"Any user who can create an Ingress in any namespace can inject NGINX configuration into the shared controller.",
+				"Set --watch-namespace/--watch-namespace-selector or disable allow-snippet-annotations")
+		} else {
+			add("args-watch-all-namespaces", "INFO",
+				"Controller watches Ingresses in all namespaces",
+				"", "Set --watch-namespace-selector to scope the controller if it serves only some tenants")
+		}
+	}
+
+	if argEnabled(args, "watch-ingress-without-class", false) {
+		add("args-watch-ingress-without-class", "WARN",
+			"--watch-ingress-without-class is set — Ingresses without a class are served by this controller",
+			"Unclassed Ingresses are claimed by every controller with this flag, so a tenant can publish routes on an unintended entry point.",
+			"Remove the flag and set spec.ingressClassName on every Ingress")
+	}
+	if args["ingress-class"] == "" && args["controller-class"] == "" {
+		add("args-default-class", "INFO",
+			"Controller uses the default class (nginx / k8s.io/ingress-nginx)",
+			"", "Set --ingress-class and --controller-class explicitly when running more than one controller")
+	}
+
+	webhook := args["validating-webhook"] != ""
+	if !webhook {
+		add("args-admission-webhook-disabled", "WARN",
+			"Validating admission webhook is disabled (--validating-webhook not set)",
+			"Without the webhook, invalid or rejected annotations are only detected at reload time and can break routing for every Ingress.",
+			"Enable controller.admissionWebhooks in the Helm chart")
+	} else if args["validating-webhook-certificate"] == "" || args["validating-webhook-key"] == "" {
+		add("args-admission-webhook-tls", "FAIL",
+			"--validating-webhook is set without --validating-webhook-certificate/--validating-webhook-key",
+			"The webhook cannot serve TLS, so the API server rejects or skips admission for every Ingress.",
+			"Set both --validating-webhook-certificate and --validating-webhook-key")
+	}
+
+	if versionAtLeast(version, "v1.9.0") && !argEnabled(args, "enable-annotation-validation", true) {
+		level := "WARN"
+		if !webhook && snippets == "true" {
+			level = "FAIL"
+		}
+		add("args-annotation-validation-disabled", level,
+			"--enable-annotation-validation=false — annotation values are not validated",
+			"Annotation validation rejects values that would inject NGINX directives; together with a disabled webhook and snippets nothing filters annotations.",
+			"Remove --enable-annotation-validation=false")
+	}
+
+	if args["publish-service"] == "" {
+		add("args-publish-service", "INFO",
+			"--publish-service is not set — Ingress status reports node addresses instead of the load balancer",
+			"", "Set --publish-service=<namespace>/<controller-service>")
+	}
+	return findings
+}
+
+// evaluateStreamServices checks the entries of a --tcp-services-configmap or
+// --udp-services-configmap ConfigMap. Each entry exposes "namespace/service:port"
+// on the controller's listen port, outside every HTTP-level control.
+func evaluateStreamServices(proto string, data map[string]string, res string) []Finding {
+	var listen []string
+	for k := range data {
+		listen = append(listen, k)
+	}
+	sort.Strings(listen)
+	var findings []Finding
+	for _, l := range listen {
+		target := data[l]
+		f := Finding{
+			ID:          "args-" + strings.ToLower(proto) + "-services",
+			Level:       "WARN",
+			Resource:    res,
+			Message:     fmt.Sprintf("%s port %s is forwarded to %s", proto, l, target),
+			Rationale:   "Raw TCP/UDP forwarding bypasses TLS termination, auth and allowlist annotations.",
+			Remediation: "Remove the entry or restrict it with a NetworkPolicy/loadBalancerSourceRanges",
+		}
+		ports := []string{l}
+		if parts := strings.Split(target, ":"); len(parts) > 1 {
+			ports = append(ports, parts[1])
+		}
+		for _, p := range ports {
+			if n, err := strconv.Atoi(p); err == nil {
+				if desc, ok := sensitivePorts[n]; ok {
+					f.Level = "FAIL"
+					f.Message += fmt.Sprintf(" — %s (port %d) exposed through the controller", desc, n)
+					break
+				}
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
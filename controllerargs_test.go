@@ -0,0 +1,107 @@
+package main
+
+import "testing"
+
+// ─── parseControllerArgs ─────────────────────────────────────────────────────
+
+func TestParseControllerArgs(t *testing.T) {
+	args := parseControllerArgs([]string{
+		"/nginx-ingress-controller",
+		"--publish-service=$(POD_NAMESPACE)/ingress-nginx-controller",
+		"--enable-ssl-passthrough",
+		"--watch-namespace", "team-a",
+		"--enable-metrics=false",
+		"--ingress-class=nginx",
+		"--ingress-class=internal",
+	})
+	want := map[string]string{
+		"publish-service":        "$(POD_NAMESPACE)/ingress-nginx-controller",
+		"enable-ssl-passthrough": "true",
+		"watch-namespace":        "team-a",
+		"enable-metrics":         "false",
+		"ingress-class":          "internal",
+	}
+	if len(args) != len(want) {
+		t.Errorf("parsed %d flags, want %d: %v", len(args), len(want), args)
+	}
+	for k, v := range want {
+		if args[k] != v {
+			t.Errorf("args[%q] = %q, want %q", k, args[k], v)
+		}
+	}
+}
+
+func TestParseControllerArgsBoolBeforeValue(t *testing.T) {
+	// A bool flag must not swallow the next token as its value.
+	args := parseControllerArgs([]string{"--enable-ssl-passthrough", "/extra"})
+	if args["enable-ssl-passthrough"] != "true" {
+		t.Errorf("enable-ssl-passthrough = %q, want true", args["enable-ssl-passthrough"])
+	}
+}
+
+func TestExpandPodNamespace(t *testing.T) {
+	if got := expandPodNamespace("$(POD_NAMESPACE)/default-cert", "ingress-nginx"); got != "ingress-nginx/default-cert" {
+		t.Errorf("got %q", got)
+	}
+}
+
+// ─── evaluateControllerArgs ──────────────────────────────────────────────────
+
+func TestEvaluateControllerArgsHardened(t *testing.T) {
+	args := map[string]string{
+		"watch-namespace-selector":       "tenant=a",
+		"controller-class":               "k8s.io/ingress-nginx-a",
+		"validating-webhook":             ":8443",
+		"validating-webhook-certificate": "/usr/local/certificates/cert",
+		"validating-webhook-key":         "/usr/local/certificates/key",
+		"publish-service":                "ingress-nginx/ingress-nginx-controller",
+	}
+	if fs := evaluateControllerArgs(args, map[string]string{}, "v1.12.1", "deployment/x"); len(fs) != 0 {
+		t.Errorf("hardened args produced findings: %+v", fs)
+	}
+}
+
+func TestEvaluateControllerArgsRiskyCombination(t *testing.T) {
+	args := map[string]string{
+		"enable-ssl-passthrough":       "true",
+		"enable-annotation-validation": "false",
+	}
+	cm := map[string]string{"allow-snippet-annotations": "true"}
+	fs := evaluateControllerArgs(args, cm, "v1.11.0", "deployment/x")
+	for _, id := range []string{"args-ssl-passthrough", "args-cluster-wide-snippets",
+		"args-admission-webhook-disabled", "args-annotation-validation-disabled"} {
+		if !hasFinding(fs, id) {
+			t.Errorf("missing finding %s", id)
+		}
+	}
+	for _, f := range fs {
+		if f.ID == "args-annotation-validation-disabled" && f.Level != "FAIL" {
+			t.Errorf("no webhook + snippets + no validation: level %s, want FAIL", f.Level)
+		}
+	}
+}
+
+func TestEvaluateControllerArgsWebhookTLS(t *testing.T) {
+	args := map[string]string{"validating-webhook": ":8443"}
+	if fs := evaluateControllerArgs(args, nil, "v1.12.0", "deployment/x"); !hasFinding(fs, "args-admission-webhook-tls") {
+		t.Error("webhook without certificate should be reported")
+	}
+}
+
+// ─── evaluateStreamServices ──────────────────────────────────────────────────
+
+func TestEvaluateStreamServices(t *testing.T) {
+	fs := evaluateStreamServices("TCP", map[string]string{
+		"2222": "git/gitea-ssh:22",
+		"5432": "db/postgres:5432",
+	}, "configmap/ingress-nginx/tcp-services")
+	if len(fs) != 2 {
+		t.Fatalf("got %d findings, want 2", len(fs))
+	}
+	if fs[0].Level != "WARN" || fs[1].Level != "FAIL" {
+		t.Errorf("levels = %s, %s; want WARN, FAIL", fs[0].Level, fs[1].Level)
+	}
+	if fs[0].ID != "args-tcp-services" {
+		t.Errorf("ID = %q", fs[0].ID)
+	}
+}
@@ -21,6 +21,16 @@ func runCmd(name string, args ...string) error {
 	return cmd.Run()
 }
 
+// runCmdInput is runCmd with input fed to the command's stdin (used to pipe
+// generated manifests into "kubectl apply -f -").
+func runCmdInput(input, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // fixDeleteExposingIngress deletes every Ingress resource (ns/name) that was
 // found exposing the admission controller endpoint.
@@ -139,7 +149,7 @@ func offerFixes(a *AuditState) {
 
 			// For changes that affect running pods, wait for rollout
 			switch fix.ID {
-			case "snippet-annotations", "resource-limits":
+			case "snippet-annotations", "resource-limits", "container-security-context":
 				resType := strings.ToLower(a.DeploymentType)
 				if resType == "" {
 					resType = "deployment"
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Helm release drift
+// ─────────────────────────────────────────────
+
+// Annotations Helm stamps on every object it manages.
+const (
+	helmReleaseNameAnn      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnn = "meta.helm.sh/release-namespace"
+)
+
+// defaultHelmChartRepo is the upstream repository of the ingress-nginx chart.
+const defaultHelmChartRepo = "https://kubernetes.github.io/ingress-nginx"
+
+// helmChartVersionRE matches the version "helm list" appends to the chart
+// name, e.g. "4.12.1" in "ingress-nginx-4.12.1".
+var helmChartVersionRE = regexp.MustCompile(`^ingress-nginx-(\d+\.\d+\.\d+\S*)$`)
+
+// helmChartVersion returns the chart version of a "helm list" chart column,
+// or "" when it is not an ingress-nginx chart.
+func helmChartVersion(chart string) string {
+	if m := helmChartVersionRE.FindStringSubmatch(chart); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// driftKinds are the manifest kinds compared with their live objects.
+var driftKinds = map[string]bool{"Deployment": true, "DaemonSet": true, "ConfigMap": true, "Service": true}
+
+// driftEntry is one field whose live value differs from the release manifest.
+type driftEntry struct {
+	Path    string
+	Release string // value rendered by the chart ("" = not set)
+	Live    string // value on the live object ("" = not set)
+}
+
+// helmReleaseOf returns the Helm release that manages an object, from its
+// metadata annotations.
+func helmReleaseOf(meta map[string]interface{}) (name, ns string) {
+	ann := getStringMap(meta, "annotations")
+	return ann[helmReleaseNameAnn], ann[helmReleaseNamespaceAnn]
+}
+
+var (
+	manifestKindRE = regexp.MustCompile(`(?m)^kind:\s*(\S+)`)
+	manifestSepRE  = regexp.MustCompile(`(?m)^---\s*$`)
+)
+
+// filterManifest keeps the YAML documents of a rendered release manifest
+// whose kind is in kinds.
+func filterManifest(manifest string, kinds map[string]bool) string {
+	var keep []string
+	for _, doc := range manifestSepRE.Split(manifest, -1) {
+		if m := manifestKindRE.FindStringSubmatch(doc); m != nil && kinds[m[1]] {
+			keep = append(keep, strings.TrimSpace(doc))
+		}
+	}
+	return strings.Join(keep, "\n---\n")
+}
+
+// strictDriftPaths are subtrees where keys present only on the live object
+// count as drift, because live patches typically add them (ConfigMap data,
+// container resources and args).
+var strictDriftPaths = []*regexp.Regexp{
+	regexp.MustCompile(`^data$`),
+	regexp.MustCompile(`^spec\.template\.spec\.containers\[[^]]+\]\.(resources|resources\.limits|resources\.requests|securityContext)$`),
+}
+
+func isStrictPath(path string) bool {
+	for _, re := range strictDriftPaths {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderValue formats a decoded JSON value for a drift report.
+func renderValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		var parts []string
+		for _, e := range t {
+			parts = append(parts, renderValue(e))
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	case map[string]interface{}:
+		var keys []string
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, k+":"+renderValue(t[k]))
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// namedItems indexes a list of objects by their "name" field. ok is false
+// when any item is not a named object.
+func namedItems(list []interface{}) (map[string]interface{}, []string, bool) {
+	items := map[string]interface{}{}
+	var order []string
+	for _, e := range list {
+		m, isMap := e.(map[string]interface{})
+		name := getString(m, "name")
+		if !isMap || name == "" {
+			return nil, nil, false
+		}
+		items[name] = m
+		order = append(order, name)
+	}
+	return items, order, true
+}
+
+// diffObjects compares the fields set in the release manifest with the live
+// object. Fields the API server defaults are ignored, except under
+// strictDriftPaths where extra live keys are reported too. Lists of named
+// objects (containers, ports, env) are matched by name.
+func diffObjects(release, live interface{}, path string, out *[]driftEntry) {
+	switch r := release.(type) {
+	case map[string]interface{}:
+		l, _ := live.(map[string]interface{})
+		if l == nil {
+			*out = append(*out, driftEntry{path, renderValue(release), renderValue(live)})
+			return
+		}
+		keys := make([]string, 0, len(r))
+		for k := range r {
+			keys = append(keys, k)
+		}
+		if isStrictPath(path) {
+			for k := range l {
+				if _, ok := r[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, inRelease := r[k]; !inRelease {
+				*out = append(*out, driftEntry{joinPath(path, k), "", renderValue(l[k])})
+				continue
+			}
+			diffObjects(r[k], l[k], joinPath(path, k), out)
+		}
+	case []interface{}:
+		l, _ := live.([]interface{})
+		rn, order, okR := namedItems(r)
+		ln, lorder, okL := namedItems(l)
+		if okR && okL && len(r) > 0 {
+			for _, name := range order {
+				diffObjects(rn[name], ln[name], fmt.Sprintf("%s[%s]", path, name), out)
+			}
+			for _, name := range lorder {
+				if _, ok := rn[name]; !ok {
+					*out = append(*out, driftEntry{fmt.Sprintf("%s[%s]", path, name), "", renderValue(ln[name])})
+				}
+			}
+			return
+		}
+		if renderValue(r) != renderValue(l) {
+			*out = append(*out, driftEntry{path, renderValue(r), renderValue(l)})
+		}
+	default:
+		// A null in the manifest (e.g. creationTimestamp) leaves the field to
+		// the API server.
+		if release == nil {
+			return
+		}
+		if renderValue(release) != renderValue(live) {
+			*out = append(*out, driftEntry{path, renderValue(release), renderValue(live)})
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// controllerContainerPathRE matches the controller container in drift paths.
+var controllerContainerPathRE = regexp.MustCompile(`^spec\.template\.spec\.containers\[controller\]\.`)
+
+// chartServiceValues maps the name suffix of each Service the chart renders
+// (after the release fullname) to its values block and the spec fields that
+// block sets directly.
+var chartServiceValues = []struct {
+	suffix, values string
+	fields         []string
+}{
+	{"-controller-admission", "controller.admissionWebhooks.service", []string{"type", "loadBalancerSourceRanges"}},
+	{"-controller-metrics", "controller.metrics.service", []string{"type", "externalTrafficPolicy", "loadBalancerSourceRanges"}},
+	{"-controller-internal", "controller.service.internal", []string{"externalTrafficPolicy", "loadBalancerSourceRanges"}},
+	{"-controller", "controller.service", []string{"type", "externalTrafficPolicy", "loadBalancerSourceRanges", "loadBalancerClass"}},
+}
+
+// helmValuePath maps a drifted field of an ingress-nginx chart object, named
+// name, to the chart value that renders it, or "" when there is no direct
+// equivalent. The object name tells the chart's Services and ConfigMaps apart.
+func helmValuePath(kind, name, path string) string {
+	switch kind {
+	case "ConfigMap":
+		k, ok := strings.CutPrefix(path, "data.")
+		switch {
+		case !ok:
+		case strings.HasSuffix(name, "-controller"):
+			return "controller.config." + k
+		case strings.HasSuffix(name, "-tcp"):
+			return "tcp." + k
+		case strings.HasSuffix(name, "-udp"):
+			return "udp." + k
+		}
+	case "Deployment", "DaemonSet":
+		if path == "spec.replicas" {
+			return "controller.replicaCount"
+		}
+		if rest, ok := strings.CutPrefix(path, "spec.template.spec."); ok {
+			switch {
+			case strings.HasPrefix(rest, "priorityClassName"):
+				return "controller.priorityClassName"
+			case strings.HasPrefix(rest, "terminationGracePeriodSeconds"):
+				return "controller.terminationGracePeriodSeconds"
+			case strings.HasPrefix(rest, "affinity"):
+				return "controller.affinity"
+			case strings.HasPrefix(rest, "topologySpreadConstraints"):
+				return "controller.topologySpreadConstraints"
+			}
+		}
+		if rest := controllerContainerPathRE.ReplaceAllString(path, ""); rest != path {
+			switch {
+			case strings.HasPrefix(rest, "resources"):
+				return "controller." + rest
+			case strings.HasPrefix(rest, "args"):
+				return "controller.extraArgs"
+			case strings.HasPrefix(rest, "securityContext"):
+				return "controller.containerSecurityContext"
+			case strings.HasPrefix(rest, "image"):
+				return "controller.image"
+			}
+		}
+	case "Service":
+		for _, svc := range chartServiceValues {
+			if !strings.HasSuffix(name, svc.suffix) {
+				continue
+			}
+			if rest, ok := strings.CutPrefix(path, "spec."); ok {
+				for _, k := range svc.fields {
+					if strings.HasPrefix(rest, k) {
+						return svc.values + "." + k
+					}
+				}
+			}
+			if rest, ok := strings.CutPrefix(path, "metadata.annotations."); ok {
+				// Annotation keys contain dots, which --set treats as nesting.
+				return svc.values + ".annotations." + strings.ReplaceAll(rest, ".", `\.`)
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// helmSetEscape escapes a --set-string value ("," separates assignments).
+func helmSetEscape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), ",", `\,`)
+}
+
+// helmSetArgs renders values (dotted chart path → string value) as sorted
+// --set-string arguments.
+func helmSetArgs(values map[string]string) []string {
+	var keys []string
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var args []string
+	for _, k := range keys {
+		args = append(args, "--set-string", k+"="+helmSetEscape(values[k]))
+	}
+	return args
+}
+
+// lookupValue returns the value at a dotted chart path in decoded Helm
+// values ("\." escapes a literal dot in a key).
+func lookupValue(values map[string]interface{}, path string) (interface{}, bool) {
+	const sentinel = "\x00"
+	parts := strings.Split(strings.ReplaceAll(path, `\.`, sentinel), ".")
+	var cur interface{} = values
+	for _, p := range parts {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[strings.ReplaceAll(p, sentinel, ".")]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// driftRemediation describes how to resolve drift on a Helm-managed object.
+func driftRemediation(kind, name string, d driftEntry, values map[string]interface{}) string {
+	vp := helmValuePath(kind, name, d.Path)
+	if vp == "" {
+		return "Re-run helm upgrade to restore the chart value, or move the change into the chart values"
+	}
+	cur := "unset"
+	if v, ok := lookupValue(values, vp); ok {
+		cur = renderValue(v)
+	}
+	if d.Live == "" || strings.ContainsAny(d.Live, "[{") {
+		return fmt.Sprintf("Set %s in the release values (currently %s), or re-run helm upgrade to revert", vp, cur)
+	}
+	return fmt.Sprintf("Keep the live value with helm upgrade --reuse-values --set-string %s=%s (currently %s), or re-run helm upgrade to revert",
+		vp, helmSetEscape(d.Live), cur)
+}
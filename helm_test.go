@@ -0,0 +1,169 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func driftPaths(entries []driftEntry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Path)
+	}
+	return out
+}
+
+// ─── filterManifest ──────────────────────────────────────────────────────────
+
+func TestFilterManifest(t *testing.T) {
+	manifest := `---
+# Source: ingress-nginx/templates/controller-serviceaccount.yaml
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ingress-nginx
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ingress-nginx-controller
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ingress-nginx-controller`
+	got := filterManifest(manifest, driftKinds)
+	if strings.Contains(got, "ServiceAccount") || !strings.Contains(got, "kind: ConfigMap") || !strings.Contains(got, "kind: Deployment") {
+		t.Errorf("filterManifest kept the wrong documents:\n%s", got)
+	}
+}
+
+// ─── diffObjects ─────────────────────────────────────────────────────────────
+
+func TestDiffObjectsIgnoresServerDefaults(t *testing.T) {
+	release := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "svc", "creationTimestamp": nil},
+		"spec":     map[string]interface{}{"type": "LoadBalancer"},
+	}
+	live := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "svc", "uid": "1", "creationTimestamp": "2026-01-01T00:00:00Z"},
+		"spec":     map[string]interface{}{"type": "LoadBalancer", "clusterIP": "10.0.0.1"},
+		"status":   map[string]interface{}{},
+	}
+	var entries []driftEntry
+	diffObjects(release, live, "", &entries)
+	if len(entries) != 0 {
+		t.Errorf("unexpected drift: %+v", entries)
+	}
+}
+
+func TestDiffObjectsConfigMapData(t *testing.T) {
+	release := map[string]interface{}{"data": map[string]interface{}{"hsts": "true", "server-tokens": "false"}}
+	live := map[string]interface{}{"data": map[string]interface{}{"hsts": "false", "allow-snippet-annotations": "false"}}
+	var entries []driftEntry
+	diffObjects(release, live, "", &entries)
+	got := strings.Join(driftPaths(entries), ",")
+	if got != "data.allow-snippet-annotations,data.hsts,data.server-tokens" {
+		t.Errorf("drift paths = %s", got)
+	}
+}
+
+func TestDiffObjectsContainersByName(t *testing.T) {
+	container := func(cpu string, extra bool) map[string]interface{} {
+		c := map[string]interface{}{
+			"name":      "controller",
+			"args":      []interface{}{"/nginx-ingress-controller", "--election-id=x"},
+			"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": cpu}},
+		}
+		if extra {
+			c["resources"].(map[string]interface{})["limits"] = map[string]interface{}{"memory": "256Mi"}
+		}
+		return c
+	}
+	spec := func(c map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{
+			"spec": map[string]interface{}{"containers": []interface{}{c}}}}}
+	}
+	var entries []driftEntry
+	diffObjects(spec(container("100m", false)), spec(container("250m", true)), "", &entries)
+	got := strings.Join(driftPaths(entries), ",")
+	want := "spec.template.spec.containers[controller].resources.limits," +
+		"spec.template.spec.containers[controller].resources.requests.cpu"
+	if got != want {
+		t.Errorf("drift paths = %s, want %s", got, want)
+	}
+}
+
+// ─── helmValuePath / remediation ─────────────────────────────────────────────
+
+func TestHelmChartVersion(t *testing.T) {
+	cases := map[string]string{
+		"ingress-nginx-4.12.1":        "4.12.1",
+		"ingress-nginx-4.13.0-beta.1": "4.13.0-beta.1",
+		"nginx-ingress-1.1.0":         "",
+		"ingress-nginx":               "",
+	}
+	for chart, want := range cases {
+		if got := helmChartVersion(chart); got != want {
+			t.Errorf("helmChartVersion(%q) = %q, want %q", chart, got, want)
+		}
+	}
+}
+
+func TestHelmValuePath(t *testing.T) {
+	const ctrl = "ingress-nginx-controller"
+	cases := []struct{ kind, name, path, want string }{
+		{"ConfigMap", ctrl, "data.allow-snippet-annotations", "controller.config.allow-snippet-annotations"},
+		{"ConfigMap", "ingress-nginx-tcp", "data.9000", "tcp.9000"},
+		{"ConfigMap", "ingress-nginx-udp", "data.53", "udp.53"},
+		{"ConfigMap", "custom-headers", "data.X-Frame-Options", ""},
+		{"Deployment", ctrl, "spec.replicas", "controller.replicaCount"},
+		{"Deployment", ctrl, "spec.template.spec.containers[controller].resources.limits.memory", "controller.resources.limits.memory"},
+		{"Deployment", ctrl, "spec.template.spec.containers[controller].args", "controller.extraArgs"},
+		{"Service", ctrl, "spec.loadBalancerSourceRanges", "controller.service.loadBalancerSourceRanges"},
+		{"Service", ctrl, "metadata.annotations.service.beta.kubernetes.io/aws-load-balancer-scheme",
+			`controller.service.annotations.service\.beta\.kubernetes\.io/aws-load-balancer-scheme`},
+		{"Service", ctrl, "spec.clusterIP", ""},
+		{"Service", ctrl + "-admission", "spec.type", "controller.admissionWebhooks.service.type"},
+		{"Service", ctrl + "-metrics", "spec.type", "controller.metrics.service.type"},
+		{"Service", ctrl + "-internal", "metadata.annotations.a.b/c", `controller.service.internal.annotations.a\.b/c`},
+		{"Service", ctrl + "-internal", "spec.type", ""},
+		{"Service", "other", "spec.type", ""},
+	}
+	for _, c := range cases {
+		if got := helmValuePath(c.kind, c.name, c.path); got != c.want {
+			t.Errorf("helmValuePath(%s, %s, %s) = %q, want %q", c.kind, c.name, c.path, got, c.want)
+		}
+	}
+}
+
+func TestLookupValue(t *testing.T) {
+	values := map[string]interface{}{"controller": map[string]interface{}{
+		"config":  map[string]interface{}{"hsts": "true"},
+		"service": map[string]interface{}{"annotations": map[string]interface{}{"a.b/c": "x"}},
+	}}
+	if v, ok := lookupValue(values, "controller.config.hsts"); !ok || v != "true" {
+		t.Errorf("got %v, %v", v, ok)
+	}
+	if v, ok := lookupValue(values, `controller.service.annotations.a\.b/c`); !ok || v != "x" {
+		t.Errorf("escaped key: got %v, %v", v, ok)
+	}
+	if _, ok := lookupValue(values, "controller.config.missing"); ok {
+		t.Error("missing key found")
+	}
+}
+
+func TestDriftRemediation(t *testing.T) {
+	d := driftEntry{Path: "data.ssl-ciphers", Release: "", Live: "A,B"}
+	got := driftRemediation("ConfigMap", "ingress-nginx-controller", d, nil)
+	if !strings.Contains(got, `--set-string controller.config.ssl-ciphers=A\,B`) || !strings.Contains(got, "currently unset") {
+		t.Errorf("remediation = %q", got)
+	}
+}
+
+func TestHelmSetArgs(t *testing.T) {
+	got := strings.Join(helmSetArgs(map[string]string{"b": "x,y", "a": "1"}), " ")
+	if got != `--set-string a=1 --set-string b=x\,y` {
+		t.Errorf("helmSetArgs = %s", got)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Host-level exposure (hostNetwork, hostPort, hostPath)
+// ─────────────────────────────────────────────
+
+// sensitiveHostPaths give a container control over the node when mounted.
+var sensitiveHostPaths = []string{
+	"/", "/etc", "/proc", "/sys", "/root", "/var/lib/kubelet", "/var/lib/docker",
+	"/var/run/docker.sock", "/run/containerd", "/var/run/containerd", "/var/run/crio",
+}
+
+// HostExposure summarises which controller ports are reachable on node IPs.
+type HostExposure struct {
+	HostNetwork   bool     `json:"host_network"`
+	HostPorts     []string `json:"host_ports"` // "name hostPort→containerPort"
+	WebhookOnHost bool     `json:"webhook_on_host"`
+}
+
+// argPort returns the port of a "host:port" or ":port" flag value, or def.
+func argPort(v string, def int) int {
+	if i := strings.LastIndex(v, ":"); i >= 0 {
+		v = v[i+1:]
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		return n
+	}
+	return def
+}
+
+// hostNetworkPorts lists the ports the controller binds on every interface,
+// which become node ports under hostNetwork. The NGINX status and stream
+// ports listen on localhost only and are left out.
+func hostNetworkPorts(args map[string]string) map[string]int {
+	ports := map[string]int{
+		"http":           argPort(args["http-port"], 80),
+		"https":          argPort(args["https-port"], 443),
+		"healthz":        argPort(args["healthz-port"], 10254),
+		"default-server": argPort(args["default-server-port"], 8181),
+	}
+	if args["validating-webhook"] != "" {
+		ports["webhook"] = argPort(args["validating-webhook"], 8443)
+	}
+	return ports
+}
+
+// isSensitiveHostPath reports whether p is, or is inside, a sensitive path.
+func isSensitiveHostPath(p string) bool {
+	p = strings.TrimSuffix(p, "/")
+	if p == "" {
+		return true
+	}
+	for _, s := range sensitiveHostPaths {
+		if s != "/" && (p == s || strings.HasPrefix(p, s+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateHostExposure checks the controller pod spec for host namespaces,
+// host ports and hostPath volumes.
+func evaluateHostExposure(podSpec map[string]interface{}, args map[string]string, res string) (HostExposure, []Finding) {
+	var findings []Finding
+	add := func(id, level, msg, rationale, fix string) {
+		findings = append(findings, Finding{ID: id, Level: level, Resource: res,
+			Message: msg, Rationale: rationale, Remediation: fix})
+	}
+	ex := HostExposure{HostNetwork: podSpec["hostNetwork"] == true}
+	webhookPort := argPort(args["validating-webhook"], 8443)
+	webhookEnabled := args["validating-webhook"] != ""
+
+	// ── hostNetwork ──
+	if ex.HostNetwork {
+		ports := hostNetworkPorts(args)
+		var names []string
+		for n := range ports {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		var extra []string
+		for _, n := range names {
+			if p := ports[n]; p != 80 && p != 443 {
+				extra = append(extra, fmt.Sprintf("%s %d", n, p))
+			}
+		}
+		add("host-network", "WARN",
+			fmt.Sprintf("Controller runs with hostNetwork; besides 80/443 it listens on every node IP on: %s", strings.Join(extra, ", ")),
+			"Ports bound on the host network bypass Services and most NetworkPolicy engines.",
+			"Run without hostNetwork behind a LoadBalancer/NodePort Service, or firewall the extra ports on the nodes")
+		if webhookEnabled {
+			ex.WebhookOnHost = true
+			add("host-webhook-exposed", "FAIL",
+				fmt.Sprintf("Admission webhook port %d is bound on every node IP (hostNetwork)", webhookPort),
+				"The webhook is reachable without authentication from anything that can reach a node — the exposure reported by AbuseBSI (CVE-2025-1974).",
+				"Disable hostNetwork, or block the webhook port on the node firewall to everything but the API server")
+		}
+		if dp := getString(podSpec, "dnsPolicy"); dp != "ClusterFirstWithHostNet" {
+			add("host-dns-policy", "WARN",
+				fmt.Sprintf("hostNetwork pod uses dnsPolicy %s", orDefault(dp)),
+				"With hostNetwork only ClusterFirstWithHostNet resolves cluster Service names (auth-url, ExternalName backends).",
+				"Set dnsPolicy: ClusterFirstWithHostNet")
+		}
+	} else if getString(podSpec, "dnsPolicy") == "Default" {
+		add("host-dns-policy", "INFO", "Controller uses dnsPolicy Default (node resolver)",
+			"Cluster Service names used in auth-url or ExternalName backends will not resolve.",
+			"Use dnsPolicy: ClusterFirst unless node DNS is intended")
+	}
+
+	// ── hostPort mappings ──
+	containers, _ := podContainers(podSpec)
+	for _, c := range containers {
+		for _, p := range getSlice(c, "ports") {
+			pm := asMap(p)
+			hp, _ := toInt(pm["hostPort"])
+			if hp == 0 {
+				continue
+			}
+			cp, _ := toInt(pm["containerPort"])
+			name := getString(pm, "name")
+			ex.HostPorts = append(ex.HostPorts, fmt.Sprintf("%s %d→%d", orDefault(name), hp, cp))
+			switch {
+			case name == "webhook" || (webhookEnabled && cp == webhookPort):
+				ex.WebhookOnHost = true
+				add("host-webhook-exposed", "FAIL",
+					fmt.Sprintf("Admission webhook port %d is mapped to hostPort %d on every node", cp, hp),
+					"The webhook is reachable without authentication from anything that can reach a node — the exposure reported by AbuseBSI (CVE-2025-1974).",
+					fmt.Sprintf("Remove hostPort from the %s port", orDefault(name)))
+			case hp != 80 && hp != 443:
+				add("host-port", "WARN",
+					fmt.Sprintf("Container %s maps port %s (%d) to hostPort %d", getString(c, "name"), orDefault(name), cp, hp),
+					"hostPorts publish the port on every node running the pod.",
+					"Remove the hostPort; expose only 80/443 on the host")
+			}
+		}
+	}
+
+	// ── Host namespaces ──
+	for _, f := range []string{"hostPID", "hostIPC"} {
+		if podSpec[f] == true {
+			add("host-namespace", "FAIL", fmt.Sprintf("Controller pod sets %s: true", f),
+				"The controller can see or signal every process (hostPID) or shared memory segment (hostIPC) on the node.",
+				fmt.Sprintf("Remove %s", f))
+		}
+	}
+
+	// ── hostPath volumes ──
+	for _, v := range getSlice(podSpec, "volumes") {
+		vm := asMap(v)
+		hp, ok := vm["hostPath"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := getString(hp, "path")
+		level := "WARN"
+		if isSensitiveHostPath(path) {
+			level = "FAIL"
+		}
+		add("host-path", level, fmt.Sprintf("Volume %s mounts hostPath %s", getString(vm, "name"), path),
+			"A compromised controller can read or modify node files through the mount.",
+			"Replace the hostPath volume with a ConfigMap, Secret or emptyDir")
+	}
+	return ex, findings
+}
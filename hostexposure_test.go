@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func hostPodSpec() map[string]interface{} {
+	return map[string]interface{}{
+		"containers": []interface{}{map[string]interface{}{
+			"name": "controller",
+			"ports": []interface{}{
+				map[string]interface{}{"name": "http", "containerPort": float64(80), "hostPort": float64(80)},
+				map[string]interface{}{"name": "https", "containerPort": float64(443), "hostPort": float64(443)},
+				map[string]interface{}{"name": "webhook", "containerPort": float64(8443)},
+			},
+		}},
+	}
+}
+
+var webhookArgs = map[string]string{"validating-webhook": ":8443"}
+
+func TestArgPort(t *testing.T) {
+	for v, want := range map[string]int{":8443": 8443, "0.0.0.0:9443": 9443, "10254": 10254, "": 42, "x": 42} {
+		if got := argPort(v, 42); got != want {
+			t.Errorf("argPort(%q) = %d, want %d", v, got, want)
+		}
+	}
+}
+
+func TestEvaluateHostExposureClean(t *testing.T) {
+	ex, fs := evaluateHostExposure(hostPodSpec(), webhookArgs, "deployment/x")
+	if len(fs) != 0 || ex.WebhookOnHost || len(ex.HostPorts) != 2 {
+		t.Errorf("ex = %+v, findings = %+v", ex, fs)
+	}
+}
+
+func TestEvaluateHostExposureHostNetwork(t *testing.T) {
+	spec := hostPodSpec()
+	spec["hostNetwork"] = true
+	spec["dnsPolicy"] = "ClusterFirst"
+	ex, fs := evaluateHostExposure(spec, webhookArgs, "deployment/x")
+	if !ex.WebhookOnHost {
+		t.Error("webhook should be on the host network")
+	}
+	for _, id := range []string{"host-network", "host-webhook-exposed", "host-dns-policy"} {
+		if !hasFinding(fs, id) {
+			t.Errorf("missing %s in %+v", id, fs)
+		}
+	}
+	for _, f := range fs {
+		if f.ID == "host-network" && (!strings.Contains(f.Message, "healthz 10254") || !strings.Contains(f.Message, "webhook 8443")) {
+			t.Errorf("host-network message = %q", f.Message)
+		}
+	}
+
+	// Without the webhook and with the right dnsPolicy only hostNetwork remains.
+	spec["dnsPolicy"] = "ClusterFirstWithHostNet"
+	ex, fs = evaluateHostExposure(spec, map[string]string{}, "deployment/x")
+	if ex.WebhookOnHost || len(fs) != 1 || fs[0].ID != "host-network" {
+		t.Errorf("ex = %+v, findings = %+v", ex, fs)
+	}
+}
+
+func TestEvaluateHostExposureHostPorts(t *testing.T) {
+	spec := hostPodSpec()
+	ports := spec["containers"].([]interface{})[0].(map[string]interface{})["ports"].([]interface{})
+	ports[2].(map[string]interface{})["hostPort"] = float64(8443)
+	ports = append(ports, map[string]interface{}{"name": "metrics", "containerPort": float64(10254), "hostPort": float64(10254)})
+	spec["containers"].([]interface{})[0].(map[string]interface{})["ports"] = ports
+	ex, fs := evaluateHostExposure(spec, webhookArgs, "deployment/x")
+	if !ex.WebhookOnHost || !hasFinding(fs, "host-webhook-exposed") || !hasFinding(fs, "host-port") {
+		t.Errorf("ex = %+v, findings = %+v", ex, fs)
+	}
+}
+
+func TestEvaluateHostExposureNamespacesAndPaths(t *testing.T) {
+	spec := hostPodSpec()
+	spec["hostPID"] = true
+	spec["volumes"] = []interface{}{
+		map[string]interface{}{"name": "sock", "hostPath": map[string]interface{}{"path": "/var/run/docker.sock"}},
+		map[string]interface{}{"name": "geoip", "hostPath": map[string]interface{}{"path": "/opt/geoip"}},
+		map[string]interface{}{"name": "cfg", "configMap": map[string]interface{}{"name": "x"}},
+	}
+	_, fs := evaluateHostExposure(spec, nil, "deployment/x")
+	levels := map[string]string{}
+	for _, f := range fs {
+		levels[f.ID+":"+f.Message] = f.Level
+	}
+	if !hasFinding(fs, "host-namespace") {
+		t.Error("missing host-namespace")
+	}
+	if levels["host-path:Volume sock mounts hostPath /var/run/docker.sock"] != "FAIL" ||
+		levels["host-path:Volume geoip mounts hostPath /opt/geoip"] != "WARN" {
+		t.Errorf("hostPath levels = %v", levels)
+	}
+}
+
+func TestIsSensitiveHostPath(t *testing.T) {
+	for p, want := range map[string]bool{
+		"/": true, "/etc/": true, "/etc/nginx": true, "/var/lib/kubelet/pods": true,
+		"/etcd": false, "/opt/geoip": false, "/var/log": false,
+	} {
+		if got := isSensitiveHostPath(p); got != want {
+			t.Errorf("isSensitiveHostPath(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
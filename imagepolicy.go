@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Image provenance policy
+// ─────────────────────────────────────────────
+
+// defaultImageAllowlist admits the official ingress-nginx images only.
+const defaultImageAllowlist = "registry.k8s.io/ingress-nginx/*"
+
+// imageRef is a parsed container image reference.
+type imageRef struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string // "sha256:..."
+}
+
+// Name returns "registry/repository".
+func (r imageRef) Name() string { return r.Registry + "/" + r.Repository }
+
+// parseImageRef splits an image reference, applying the Docker Hub defaults
+// the container runtime uses for short names.
+func parseImageRef(image string) imageRef {
+	var r imageRef
+	if i := strings.Index(image, "@"); i >= 0 {
+		image, r.Digest = image[:i], image[i+1:]
+	}
+	// A ":" after the last "/" separates the tag; earlier ones are a registry port.
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, r.Tag = image[:i], image[i+1:]
+	}
+	first, rest, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		r.Registry, r.Repository = first, rest
+	} else {
+		r.Registry, r.Repository = "docker.io", image
+		if !found {
+			r.Repository = "library/" + image
+		}
+	}
+	return r
+}
+
+// imageAllowed reports whether "registry/repository" matches one of the
+// allowlist globs.
+func imageAllowed(ref imageRef, allowlist []string) bool {
+	for _, pattern := range allowlist {
+		if ok, _ := path.Match(pattern, ref.Name()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// effectivePullPolicy applies the API server default for an unset
+// imagePullPolicy.
+func effectivePullPolicy(policy string, ref imageRef) string {
+	if policy != "" {
+		return policy
+	}
+	if ref.Digest == "" && (ref.Tag == "" || ref.Tag == "latest") {
+		return "Always"
+	}
+	return "IfNotPresent"
+}
+
+// imageIDDigest extracts the digest from a container status imageID
+// ("docker-pullable://repo@sha256:...", "repo@sha256:..." or "sha256:...").
+func imageIDDigest(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
+	return strings.TrimPrefix(imageID, "docker://")
+}
+
+// evaluateImagePolicy checks every container and init container of the pod
+// template for digest pinning, registry allowlisting and mutable tags, and
+// compares the image IDs the running pods report.
+func evaluateImagePolicy(podSpec map[string]interface{}, pods []interface{}, allowlist []string, res string) []Finding {
+	var findings []Finding
+	containers, init := podContainers(podSpec)
+	for i, c := range containers {
+		name := getString(c, "name")
+		label := name
+		if init[i] {
+			label += " (init)"
+		}
+		cres := fmt.Sprintf("%s[%s]", res, name)
+		add := func(id, level, msg, rationale, fix string) {
+			findings = append(findings, Finding{ID: id, Level: level, Resource: cres,
+				Message: msg, Rationale: rationale, Remediation: fix})
+		}
+		image := getString(c, "image")
+		ref := parseImageRef(image)
+		tag := ref.Tag
+		if tag == "" {
+			tag = "latest"
+		}
+
+		if !imageAllowed(ref, allowlist) {
+			add("img-registry", "FAIL",
+				fmt.Sprintf("Container %s image %s is not from an allowed registry/repository (%s)", label, ref.Name(), strings.Join(allowlist, ", ")),
+				"Images from unvetted sources can carry tampered binaries into the pod that holds every TLS key.",
+				"Use registry.k8s.io/ingress-nginx images or an approved mirror, or extend --image-allowlist")
+		}
+		if ref.Digest == "" {
+			add("img-digest", "WARN", fmt.Sprintf("Container %s image %s is not pinned by digest", label, image),
+				"A tag can be re-pointed to a different image; a digest cannot.",
+				fmt.Sprintf("Reference the image as %s:%s@sha256:<digest>", ref.Name(), tag))
+			if policy := effectivePullPolicy(getString(c, "imagePullPolicy"), ref); policy == "Always" {
+				add("img-mutable-always", "WARN",
+					fmt.Sprintf("Container %s uses mutable tag %q with imagePullPolicy Always", label, tag),
+					"Every restart re-resolves the tag, so pods of one rollout can run different images.",
+					"Pin the image by digest, or use imagePullPolicy: IfNotPresent with an immutable tag")
+			}
+		}
+
+		// ── Image IDs reported by running pods ──
+		statusKey := "containerStatuses"
+		if init[i] {
+			statusKey = "initContainerStatuses"
+		}
+		byDigest := map[string][]string{}
+		for _, p := range pods {
+			pm, _ := p.(map[string]interface{})
+			podName := getString(getMap(pm, "metadata"), "name")
+			for _, s := range getSlice(getMap(pm, "status"), statusKey) {
+				sm, _ := s.(map[string]interface{})
+				if getString(sm, "name") != name {
+					continue
+				}
+				if d := imageIDDigest(getString(sm, "imageID")); d != "" {
+					byDigest[d] = append(byDigest[d], podName)
+				}
+			}
+		}
+		var digests []string
+		for d := range byDigest {
+			digests = append(digests, d)
+		}
+		sort.Strings(digests)
+		for _, d := range digests {
+			if ref.Digest != "" && d != ref.Digest {
+				add("img-id-mismatch", "FAIL",
+					fmt.Sprintf("Container %s in pod(s) %s runs image %s, not the pinned %s",
+						label, strings.Join(byDigest[d], ", "), truncate(d, 19), truncate(ref.Digest, 19)),
+					"The running image is not the one the pod template pins.",
+					"Restart the rollout and verify the digest of a multi-arch image matches the node platform")
+			}
+		}
+		if ref.Digest == "" && len(digests) > 1 {
+			var parts []string
+			for _, d := range digests {
+				parts = append(parts, fmt.Sprintf("%s (%s)", truncate(d, 19), strings.Join(byDigest[d], ", ")))
+			}
+			add("img-id-mismatch", "FAIL",
+				fmt.Sprintf("Container %s runs %d different images for %s: %s", label, len(digests), image, strings.Join(parts, "; ")),
+				"The tag was re-pointed between pulls, so pods of one rollout run different code.",
+				"Pin the image by digest and restart the rollout")
+		}
+	}
+	return findings
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:4bbbd3c1e2cbb8a2f3f9e1b8c0f2b5d6b61e6a0c8b3e4d1a2c9f7e6d5c4b3a21"
+
+// ─── parseImageRef ───────────────────────────────────────────────────────────
+
+func TestParseImageRef(t *testing.T) {
+	cases := []struct {
+		image string
+		want  imageRef
+	}{
+		{"registry.k8s.io/ingress-nginx/controller:v1.14.3@" + testDigest,
+			imageRef{"registry.k8s.io", "ingress-nginx/controller", "v1.14.3", testDigest}},
+		{"nginx", imageRef{"docker.io", "library/nginx", "", ""}},
+		{"bitnami/nginx:1.25", imageRef{"docker.io", "bitnami/nginx", "1.25", ""}},
+		{"mirror.local:5000/ingress-nginx/controller", imageRef{"mirror.local:5000", "ingress-nginx/controller", "", ""}},
+		{"localhost/controller:dev", imageRef{"localhost", "controller", "dev", ""}},
+	}
+	for _, c := range cases {
+		if got := parseImageRef(c.image); got != c.want {
+			t.Errorf("parseImageRef(%q) = %+v, want %+v", c.image, got, c.want)
+		}
+	}
+}
+
+func TestImageAllowed(t *testing.T) {
+	allow := []string{defaultImageAllowlist, "mirror.example.com/k8s/ingress-nginx/controller"}
+	for image, want := range map[string]bool{
+		"registry.k8s.io/ingress-nginx/controller:v1.14.3":          true,
+		"registry.k8s.io/ingress-nginx/controller-chroot:v1.14.3":   true,
+		"mirror.example.com/k8s/ingress-nginx/controller:v1.14.3":   true,
+		"registry.k8s.io/ingress-nginx/sub/controller:v1":           false,
+		"docker.io/someone/ingress-nginx-controller:v1.14.3":        false,
+		"mirror.example.com/k8s/ingress-nginx/kube-webhook-certgen": false,
+	} {
+		if got := imageAllowed(parseImageRef(image), allow); got != want {
+			t.Errorf("imageAllowed(%s) = %v, want %v", image, got, want)
+		}
+	}
+}
+
+func TestEffectivePullPolicy(t *testing.T) {
+	cases := []struct {
+		image, policy, want string
+	}{
+		{"nginx", "", "Always"},
+		{"nginx:latest", "", "Always"},
+		{"nginx:1.25", "", "IfNotPresent"},
+		{"nginx@" + testDigest, "", "IfNotPresent"},
+		{"nginx:1.25", "Always", "Always"},
+	}
+	for _, c := range cases {
+		if got := effectivePullPolicy(c.policy, parseImageRef(c.image)); got != c.want {
+			t.Errorf("effectivePullPolicy(%q, %q) = %s, want %s", c.policy, c.image, got, c.want)
+		}
+	}
+}
+
+func TestImageIDDigest(t *testing.T) {
+	for _, id := range []string{
+		"docker-pullable://registry.k8s.io/ingress-nginx/controller@" + testDigest,
+		"registry.k8s.io/ingress-nginx/controller@" + testDigest,
+		testDigest,
+	} {
+		if got := imageIDDigest(id); got != testDigest {
+			t.Errorf("imageIDDigest(%q) = %s", id, got)
+		}
+	}
+}
+
+// ─── evaluateImagePolicy ─────────────────────────────────────────────────────
+
+func imagePod(name, container, imageID string) interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name},
+		"status": map[string]interface{}{"containerStatuses": []interface{}{
+			map[string]interface{}{"name": container, "imageID": imageID},
+		}},
+	}
+}
+
+func TestEvaluateImagePolicyPinned(t *testing.T) {
+	spec := map[string]interface{}{"containers": []interface{}{map[string]interface{}{
+		"name":  "controller",
+		"image": "registry.k8s.io/ingress-nginx/controller:v1.14.3@" + testDigest,
+	}}}
+	pods := []interface{}{
+		imagePod("a", "controller", "registry.k8s.io/ingress-nginx/controller@"+testDigest),
+		imagePod("b", "controller", "registry.k8s.io/ingress-nginx/controller@"+testDigest),
+	}
+	if fs := evaluateImagePolicy(spec, pods, []string{defaultImageAllowlist}, "deployment/x"); len(fs) != 0 {
+		t.Errorf("findings = %+v", fs)
+	}
+	pods = append(pods, imagePod("c", "controller", "registry.k8s.io/ingress-nginx/controller@sha256:0000"))
+	fs := evaluateImagePolicy(spec, pods, []string{defaultImageAllowlist}, "deployment/x")
+	if len(fs) != 1 || fs[0].ID != "img-id-mismatch" || !strings.Contains(fs[0].Message, "pod(s) c") {
+		t.Errorf("findings = %+v", fs)
+	}
+}
+
+func TestEvaluateImagePolicyInitAndMutable(t *testing.T) {
+	spec := map[string]interface{}{
+		"initContainers": []interface{}{map[string]interface{}{"name": "init", "image": "busybox"}},
+		"containers": []interface{}{map[string]interface{}{
+			"name": "controller", "image": "registry.k8s.io/ingress-nginx/controller:v1.14.3", "imagePullPolicy": "Always",
+		}},
+	}
+	pods := []interface{}{
+		imagePod("a", "controller", "registry.k8s.io/ingress-nginx/controller@sha256:1111"),
+		imagePod("b", "controller", "registry.k8s.io/ingress-nginx/controller@sha256:2222"),
+	}
+	fs := evaluateImagePolicy(spec, pods, []string{defaultImageAllowlist}, "deployment/x")
+	want := map[string]int{
+		"deployment/x[init]:img-registry":             1,
+		"deployment/x[init]:img-digest":               1,
+		"deployment/x[init]:img-mutable-always":       1,
+		"deployment/x[controller]:img-digest":         1,
+		"deployment/x[controller]:img-mutable-always": 1,
+		"deployment/x[controller]:img-id-mismatch":    1,
+	}
+	got := map[string]int{}
+	for _, f := range fs {
+		got[f.Resource+":"+f.ID]++
+	}
+	if len(got) != len(want) {
+		t.Errorf("findings = %+v", fs)
+	}
+	for k, n := range want {
+		if got[k] != n {
+			t.Errorf("%s: got %d, want %d", k, got[k], n)
+		}
+	}
+}
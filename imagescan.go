@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Image vulnerability scanner results (Trivy, Grype, CycloneDX)
+// ─────────────────────────────────────────────
+
+// scanSeverities orders severities from most to least severe.
+var scanSeverities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"}
+
+// osPackageTypes are the package types scanners report for distro packages.
+var osPackageTypes = map[string]bool{"apk": true, "deb": true, "rpm": true, "alpine": true, "debian": true, "ubuntu": true, "redhat": true}
+
+// ImageVuln is one vulnerability in one installed package.
+type ImageVuln struct {
+	ID        string `json:"id"`
+	Package   string `json:"package"`
+	Installed string `json:"installed"`
+	Fixed     string `json:"fixed,omitempty"`
+	Severity  string `json:"severity"`
+	Class     string `json:"class"` // "os" or "library"
+}
+
+// ImageScanResult is a parsed scanner report.
+type ImageScanResult struct {
+	Scanner string         `json:"scanner"`
+	Image   string         `json:"image"`
+	Counts  map[string]int `json:"counts"` // "os/CRITICAL" → n
+	Vulns   []ImageVuln    `json:"vulnerabilities"`
+}
+
+// normalizeSeverity maps scanner severities onto scanSeverities.
+func normalizeSeverity(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for _, known := range scanSeverities {
+		if s == known {
+			return s
+		}
+	}
+	if s == "NEGLIGIBLE" || s == "INFO" || s == "NONE" {
+		return "LOW"
+	}
+	return "UNKNOWN"
+}
+
+// parseTrivyReport reads "trivy image --format json" output.
+func parseTrivyReport(doc map[string]interface{}) ImageScanResult {
+	r := ImageScanResult{Scanner: "trivy", Image: getString(doc, "ArtifactName")}
+	for _, res := range getSlice(doc, "Results") {
+		rm := asMap(res)
+		class := "library"
+		if getString(rm, "Class") == "os-pkgs" {
+			class = "os"
+		}
+		for _, v := range getSlice(rm, "Vulnerabilities") {
+			vm := asMap(v)
+			r.Vulns = append(r.Vulns, ImageVuln{
+				ID:        getString(vm, "VulnerabilityID"),
+				Package:   getString(vm, "PkgName"),
+				Installed: getString(vm, "InstalledVersion"),
+				Fixed:     getString(vm, "FixedVersion"),
+				Severity:  normalizeSeverity(getString(vm, "Severity")),
+				Class:     class,
+			})
+		}
+	}
+	return r
+}
+
+// parseGrypeReport reads "grype -o json" output.
+func parseGrypeReport(doc map[string]interface{}) ImageScanResult {
+	src := getMap(doc, "source")
+	image := getString(getMap(src, "target"), "userInput")
+	if image == "" {
+		image = getString(src, "target")
+	}
+	r := ImageScanResult{Scanner: "grype", Image: image}
+	for _, m := range getSlice(doc, "matches") {
+		mm := asMap(m)
+		vuln, art := getMap(mm, "vulnerability"), getMap(mm, "artifact")
+		class := "library"
+		if osPackageTypes[getString(art, "type")] {
+			class = "os"
+		}
+		var fixed []string
+		for _, f := range getSlice(getMap(vuln, "fix"), "versions") {
+			fixed = append(fixed, fmt.Sprintf("%v", f))
+		}
+		r.Vulns = append(r.Vulns, ImageVuln{
+			ID:        getString(vuln, "id"),
+			Package:   getString(art, "name"),
+			Installed: getString(art, "version"),
+			Fixed:     strings.Join(fixed, ", "),
+			Severity:  normalizeSeverity(getString(vuln, "severity")),
+			Class:     class,
+		})
+	}
+	return r
+}
+
+// parseCycloneDXReport reads the vulnerabilities of a CycloneDX SBOM/VEX.
+// Components are resolved through their bom-ref; the package type comes from
+// the purl.
+func parseCycloneDXReport(doc map[string]interface{}) ImageScanResult {
+	image := getString(getMap(getMap(doc, "metadata"), "component"), "name")
+	r := ImageScanResult{Scanner: "cyclonedx", Image: image}
+	components := map[string]map[string]interface{}{}
+	for _, c := range getSlice(doc, "components") {
+		cm := asMap(c)
+		components[getString(cm, "bom-ref")] = cm
+	}
+	for _, v := range getSlice(doc, "vulnerabilities") {
+		vm := asMap(v)
+		severity := "UNKNOWN"
+		for _, rt := range getSlice(vm, "ratings") {
+			if s := normalizeSeverity(getString(asMap(rt), "severity")); s != "UNKNOWN" {
+				severity = s
+				break
+			}
+		}
+		for _, af := range getSlice(vm, "affects") {
+			comp := components[getString(asMap(af), "ref")]
+			class := "library"
+			if purl := getString(comp, "purl"); strings.HasPrefix(purl, "pkg:") {
+				if t, _, ok := strings.Cut(strings.TrimPrefix(purl, "pkg:"), "/"); ok && osPackageTypes[t] {
+					class = "os"
+				}
+			}
+			r.Vulns = append(r.Vulns, ImageVuln{
+				ID:        getString(vm, "id"),
+				Package:   getString(comp, "name"),
+				Installed: getString(comp, "version"),
+				Severity:  severity,
+				Class:     class,
+			})
+		}
+	}
+	return r
+}
+
+// parseScanReport detects the report format and parses it. sbomOnly is true
+// for an SBOM that lists components but carries no vulnerability data.
+func parseScanReport(data []byte) (r ImageScanResult, sbomOnly bool, err error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return r, false, fmt.Errorf("not a JSON scan report: %w", err)
+	}
+	switch {
+	case doc["Results"] != nil || doc["ArtifactName"] != nil:
+		r = parseTrivyReport(doc)
+	case doc["matches"] != nil:
+		r = parseGrypeReport(doc)
+	case getString(doc, "bomFormat") == "CycloneDX":
+		if doc["vulnerabilities"] == nil {
+			return r, true, nil
+		}
+		r = parseCycloneDXReport(doc)
+	case getString(doc, "spdxVersion") != "":
+		return r, true, nil
+	default:
+		return r, false, fmt.Errorf("unrecognised scan report format (expected Trivy, Grype or CycloneDX JSON)")
+	}
+	r.Vulns = dedupeVulns(r.Vulns)
+	r.Counts = countVulns(r.Vulns)
+	return r, false, nil
+}
+
+// dedupeVulns drops repeated (ID, package, version) entries, which scanners
+// emit when a package appears in several layers or targets, and sorts the
+// rest by severity.
+func dedupeVulns(vulns []ImageVuln) []ImageVuln {
+	rank := map[string]int{}
+	for i, s := range scanSeverities {
+		rank[s] = i
+	}
+	seen := map[string]bool{}
+	var out []ImageVuln
+	for _, v := range vulns {
+		key := v.ID + "|" + v.Package + "|" + v.Installed
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, v)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if rank[out[i].Severity] != rank[out[j].Severity] {
+			return rank[out[i].Severity] < rank[out[j].Severity]
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// countVulns counts vulnerabilities by "class/severity".
+func countVulns(vulns []ImageVuln) map[string]int {
+	counts := map[string]int{}
+	for _, v := range vulns {
+		counts[v.Class+"/"+v.Severity]++
+	}
+	return counts
+}
+
+// scanReportMatchesImage reports whether a report's image refers to the
+// running image: digests must match when both are known, otherwise tags.
+func scanReportMatchesImage(reportImage, runningImage, runningDigest string) bool {
+	if reportImage == "" {
+		return true
+	}
+	rep, run := parseImageRef(reportImage), parseImageRef(runningImage)
+	if rep.Digest != "" && runningDigest != "" {
+		return rep.Digest == runningDigest
+	}
+	return rep.Tag == "" || rep.Tag == run.Tag
+}
+
+// evaluateImageScan summarises CRITICAL and HIGH vulnerabilities as one
+// finding per severity, listing the package upgrades that fix them.
+func evaluateImageScan(r ImageScanResult, res string) []Finding {
+	var findings []Finding
+	for _, sev := range []string{"CRITICAL", "HIGH"} {
+		var fixes []string
+		seen := map[string]bool{}
+		total, fixable := 0, 0
+		for _, v := range r.Vulns {
+			if v.Severity != sev {
+				continue
+			}
+			total++
+			if v.Fixed == "" {
+				continue
+			}
+			fixable++
+			if fix := fmt.Sprintf("%s %s → %s", v.Package, v.Installed, v.Fixed); !seen[fix] {
+				seen[fix] = true
+				fixes = append(fixes, fix)
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		level := "WARN"
+		if sev == "CRITICAL" {
+			level = "FAIL"
+		}
+		const maxListed = 5
+		if len(fixes) > maxListed {
+			fixes = append(fixes[:maxListed], fmt.Sprintf("and %d more", len(fixes)-maxListed))
+		}
+		fix := "No fixed versions published yet — track the upstream advisories"
+		if fixable > 0 {
+			fix = "Upgrade the controller image; fixed in " + strings.Join(fixes, ", ")
+		}
+		findings = append(findings, Finding{ID: "image-cve-" + strings.ToLower(sev), Level: level, Resource: res,
+			Message: fmt.Sprintf("%d %s vulnerabilities in the controller image (os: %d, library: %d; %d with a fix)",
+				total, sev, r.Counts["os/"+sev], r.Counts["library/"+sev], fixable),
+			Remediation: fix})
+	}
+	return findings
+}
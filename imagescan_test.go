@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const trivyReport = `{
+  "ArtifactName": "registry.k8s.io/ingress-nginx/controller:v1.12.0",
+  "Results": [
+    {"Target": "alpine 3.21", "Class": "os-pkgs", "Type": "alpine", "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2025-0001", "PkgName": "libcrypto3", "InstalledVersion": "3.3.2-r0", "FixedVersion": "3.3.3-r0", "Severity": "CRITICAL"},
+      {"VulnerabilityID": "CVE-2025-0002", "PkgName": "musl", "InstalledVersion": "1.2.5-r0", "Severity": "LOW"}
+    ]},
+    {"Target": "nginx-ingress-controller", "Class": "lang-pkgs", "Type": "gobinary", "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2025-0003", "PkgName": "golang.org/x/net", "InstalledVersion": "v0.30.0", "FixedVersion": "0.33.0", "Severity": "HIGH"},
+      {"VulnerabilityID": "CVE-2025-0003", "PkgName": "golang.org/x/net", "InstalledVersion": "v0.30.0", "FixedVersion": "0.33.0", "Severity": "HIGH"}
+    ]},
+    {"Target": "dbg", "Class": "lang-pkgs", "Type": "gobinary"}
+  ]
+}`
+
+const grypeReport = `{
+  "matches": [
+    {"vulnerability": {"id": "CVE-2025-0001", "severity": "Critical", "fix": {"versions": ["3.3.3-r0"], "state": "fixed"}},
+     "artifact": {"name": "libcrypto3", "version": "3.3.2-r0", "type": "apk"}},
+    {"vulnerability": {"id": "GHSA-xxxx", "severity": "Negligible", "fix": {"versions": [], "state": "not-fixed"}},
+     "artifact": {"name": "github.com/foo/bar", "version": "v1.0.0", "type": "go-module"}}
+  ],
+  "source": {"type": "image", "target": {"userInput": "registry.k8s.io/ingress-nginx/controller@sha256:aaa"}}
+}`
+
+const cycloneDXReport = `{
+  "bomFormat": "CycloneDX",
+  "metadata": {"component": {"name": "registry.k8s.io/ingress-nginx/controller:v1.14.3"}},
+  "components": [
+    {"bom-ref": "pkg:apk/alpine/busybox@1.37.0-r8", "name": "busybox", "version": "1.37.0-r8", "purl": "pkg:apk/alpine/busybox@1.37.0-r8"}
+  ],
+  "vulnerabilities": [
+    {"id": "CVE-2025-0004", "ratings": [{"severity": "high"}], "affects": [{"ref": "pkg:apk/alpine/busybox@1.37.0-r8"}]}
+  ]
+}`
+
+// ─── parseScanReport ─────────────────────────────────────────────────────────
+
+func TestParseScanReportTrivy(t *testing.T) {
+	r, sbomOnly, err := parseScanReport([]byte(trivyReport))
+	if err != nil || sbomOnly {
+		t.Fatalf("err = %v, sbomOnly = %v", err, sbomOnly)
+	}
+	if r.Scanner != "trivy" || len(r.Vulns) != 3 {
+		t.Fatalf("r = %+v", r)
+	}
+	if r.Vulns[0].ID != "CVE-2025-0001" || r.Vulns[0].Class != "os" || r.Vulns[0].Fixed != "3.3.3-r0" {
+		t.Errorf("first vuln = %+v", r.Vulns[0])
+	}
+	if r.Counts["os/CRITICAL"] != 1 || r.Counts["library/HIGH"] != 1 || r.Counts["os/LOW"] != 1 {
+		t.Errorf("counts = %v", r.Counts)
+	}
+}
+
+func TestParseScanReportGrype(t *testing.T) {
+	r, _, err := parseScanReport([]byte(grypeReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Scanner != "grype" || r.Image != "registry.k8s.io/ingress-nginx/controller@sha256:aaa" {
+		t.Errorf("r = %+v", r)
+	}
+	if r.Counts["os/CRITICAL"] != 1 || r.Counts["library/LOW"] != 1 {
+		t.Errorf("counts = %v", r.Counts)
+	}
+}
+
+func TestParseScanReportCycloneDX(t *testing.T) {
+	r, sbomOnly, err := parseScanReport([]byte(cycloneDXReport))
+	if err != nil || sbomOnly {
+		t.Fatalf("err = %v, sbomOnly = %v", err, sbomOnly)
+	}
+	if len(r.Vulns) != 1 || r.Vulns[0].Package != "busybox" || r.Vulns[0].Class != "os" || r.Vulns[0].Severity != "HIGH" {
+		t.Errorf("vulns = %+v", r.Vulns)
+	}
+}
+
+func TestParseScanReportSBOMOnly(t *testing.T) {
+	for _, doc := range []string{`{"bomFormat": "CycloneDX", "components": []}`, `{"spdxVersion": "SPDX-2.3"}`} {
+		if _, sbomOnly, err := parseScanReport([]byte(doc)); err != nil || !sbomOnly {
+			t.Errorf("%s: sbomOnly = %v, err = %v", doc, sbomOnly, err)
+		}
+	}
+	if _, _, err := parseScanReport([]byte(`{"foo": 1}`)); err == nil {
+		t.Error("unknown format should fail")
+	}
+}
+
+// ─── scanReportMatchesImage ──────────────────────────────────────────────────
+
+func TestScanReportMatchesImage(t *testing.T) {
+	running := "registry.k8s.io/ingress-nginx/controller:v1.14.3"
+	cases := []struct {
+		report, digest string
+		want           bool
+	}{
+		{"registry.k8s.io/ingress-nginx/controller:v1.14.3", "", true},
+		{"registry.k8s.io/ingress-nginx/controller:v1.12.0", "", false},
+		{"registry.k8s.io/ingress-nginx/controller@sha256:aaa", "sha256:aaa", true},
+		{"registry.k8s.io/ingress-nginx/controller@sha256:bbb", "sha256:aaa", false},
+		{"", "sha256:aaa", true},
+	}
+	for _, c := range cases {
+		if got := scanReportMatchesImage(c.report, running, c.digest); got != c.want {
+			t.Errorf("scanReportMatchesImage(%q, %q) = %v, want %v", c.report, c.digest, got, c.want)
+		}
+	}
+}
+
+// ─── evaluateImageScan ───────────────────────────────────────────────────────
+
+func TestEvaluateImageScan(t *testing.T) {
+	r, _, _ := parseScanReport([]byte(trivyReport))
+	fs := evaluateImageScan(r, "deployment/x")
+	if len(fs) != 2 {
+		t.Fatalf("findings = %+v", fs)
+	}
+	if fs[0].ID != "image-cve-critical" || fs[0].Level != "FAIL" || !strings.Contains(fs[0].Remediation, "libcrypto3 3.3.2-r0 → 3.3.3-r0") {
+		t.Errorf("critical = %+v", fs[0])
+	}
+	if fs[1].ID != "image-cve-high" || fs[1].Level != "WARN" || !strings.Contains(fs[1].Message, "library: 1") {
+		t.Errorf("high = %+v", fs[1])
+	}
+	if fs := evaluateImageScan(ImageScanResult{}, "deployment/x"); len(fs) != 0 {
+		t.Errorf("empty report: %+v", fs)
+	}
+}
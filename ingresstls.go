@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ─────────────────────────────────────────────
+// Ingress TLS secrets
+// ─────────────────────────────────────────────
+
+// parsePrivateKey parses the first private key of a PEM bundle (PKCS#1,
+// PKCS#8 or SEC 1).
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no PEM private key found")
+		}
+		if !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			continue
+		}
+		if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+			if s, ok := k.(crypto.Signer); ok {
+				return s, nil
+			}
+		}
+		if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			return k, nil
+		}
+		if k, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+			return k, nil
+		}
+		return nil, fmt.Errorf("unsupported %s", block.Type)
+	}
+}
+
+// keyMatchesCert reports whether key is the private key of cert.
+func keyMatchesCert(cert *x509.Certificate, key crypto.Signer) bool {
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && pub.Equal(cert.PublicKey)
+}
+
+// selfSigned reports whether c is signed by its own key.
+func selfSigned(c *x509.Certificate) bool {
+	return bytes.Equal(c.RawSubject, c.RawIssuer) && c.CheckSignatureFrom(c) == nil
+}
+
+// chainProblem returns why a leaf-first chain is incomplete, or "". Each
+// certificate must be signed by the next, and the last must be a root or be
+// issued by a root in roots. With roots nil the trust anchor is not checked.
+func chainProblem(chain []*x509.Certificate, roots *x509.CertPool) string {
+	for i := 0; i+1 < len(chain); i++ {
+		if chain[i].CheckSignatureFrom(chain[i+1]) != nil {
+			return fmt.Sprintf("certificate %d (%s) is not signed by certificate %d (%s); the chain is out of order or mixed",
+				i+1, chain[i].Subject.CommonName, i+2, chain[i+1].Subject.CommonName)
+		}
+	}
+	last := chain[len(chain)-1]
+	if roots == nil || selfSigned(last) {
+		return ""
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: chain[0].NotBefore.Add(time.Second)})
+	var unknown x509.UnknownAuthorityError
+	if errors.As(err, &unknown) {
+		return fmt.Sprintf("the issuer of %s (%s) is neither in the chain nor a trusted root", last.Subject.CommonName, last.Issuer.CommonName)
+	}
+	return ""
+}
+
+// ingressTLSHosts returns the hosts of an Ingress that are served over TLS
+// by each tls entry, and the rule hosts in order.
+func ingressTLSHosts(spec map[string]interface{}) (tls []map[string]interface{}, ruleHosts []string) {
+	for _, t := range getSlice(spec, "tls") {
+		tls = append(tls, asMap(t))
+	}
+	for _, r := range getSlice(spec, "rules") {
+		if h := getString(asMap(r), "host"); h != "" && !contains(ruleHosts, h) {
+			ruleHosts = append(ruleHosts, h)
+		}
+	}
+	return tls, ruleHosts
+}
+
+// evaluateIngressTLS checks every spec.tls entry of an Ingress against its
+// secret (keyed "ns/name" in secrets; absent when not found, nil when it
+// could not be read) and every host
+// against the certificate that serves it. Like ingress-nginx, a rule host
+// missing from spec.tls[].hosts is served by the first entry whose
+// certificate matches it, and by the default certificate only otherwise.
+func evaluateIngressTLS(ing map[string]interface{}, secrets map[string]map[string]interface{},
+	roots *x509.CertPool, now time.Time) []Finding {
+	meta := getMap(ing, "metadata")
+	ns := getString(meta, "namespace")
+	res := fmt.Sprintf("ingress/%s/%s", ns, getString(meta, "name"))
+	var findings []Finding
+	add := func(id, level, msg, fix string) {
+		findings = append(findings, Finding{ID: id, Level: level, Resource: res, Message: msg, Remediation: fix})
+	}
+
+	tlsEntries, ruleHosts := ingressTLSHosts(getMap(ing, "spec"))
+	listed := map[string]bool{}
+	var leaves []*x509.Certificate
+	unreadable := false
+	for _, t := range tlsEntries {
+		var hosts []string
+		for _, h := range getSlice(t, "hosts") {
+			hosts = append(hosts, fmt.Sprintf("%v", h))
+			listed[fmt.Sprintf("%v", h)] = true
+		}
+		name := getString(t, "secretName")
+		if name == "" {
+			continue // served with the default certificate
+		}
+		secret, ok := secrets[ns+"/"+name]
+		if !ok {
+			add("ing-tls-secret-missing", "FAIL", fmt.Sprintf("TLS secret %s not found in namespace %s (hosts: %s)", name, ns, strings.Join(hosts, ", ")),
+				"Create the secret in the Ingress namespace; until then the hosts are served with the default certificate")
+			continue
+		}
+		if secret == nil {
+			add("ing-tls-secret-unreadable", "WARN", fmt.Sprintf("Could not read TLS secret %s in namespace %s — its certificate was not checked", name, ns),
+				"Grant the auditing identity get on secrets in the namespace and re-run the audit")
+			unreadable = true
+			continue
+		}
+		if typ := getString(secret, "type"); typ != "kubernetes.io/tls" {
+			add("ing-tls-secret-type", "WARN", fmt.Sprintf("TLS secret %s has type %s, not kubernetes.io/tls", name, orDefault(typ)),
+				fmt.Sprintf("Recreate it with: kubectl create secret tls %s -n %s --cert=... --key=...", name, ns))
+		}
+		data := getMap(secret, "data")
+		certPEM, _ := base64.StdEncoding.DecodeString(getString(data, "tls.crt"))
+		info, err := describeCert("secret/"+ns+"/"+name, certPEM)
+		if err != nil {
+			add("ing-tls-cert-invalid", "FAIL", fmt.Sprintf("TLS secret %s: tls.crt is not a valid certificate: %v", name, err),
+				"Store the PEM certificate chain, leaf first, in tls.crt")
+			continue
+		}
+		keyPEM, _ := base64.StdEncoding.DecodeString(getString(data, "tls.key"))
+		if key, err := parsePrivateKey(keyPEM); err != nil {
+			add("ing-tls-key-invalid", "FAIL", fmt.Sprintf("TLS secret %s: tls.key is not a valid private key: %v", name, err),
+				"Store the PEM private key of the certificate in tls.key")
+		} else if !keyMatchesCert(info.chain[0], key) {
+			add("ing-tls-key-mismatch", "FAIL", fmt.Sprintf("TLS secret %s: tls.key does not match the certificate %s", name, info.Subject),
+				"Store the private key that belongs to the certificate; NGINX rejects the pair and serves the default certificate")
+		}
+		if p := chainProblem(info.chain, roots); p != "" {
+			add("ing-tls-chain-incomplete", "WARN", fmt.Sprintf("TLS secret %s: %s", name, p),
+				"Append the intermediate certificates to tls.crt, leaf first")
+		} else if info.ChainLength == 1 && selfSigned(info.chain[0]) {
+			add("ing-tls-self-signed", "WARN", fmt.Sprintf("TLS secret %s holds a self-signed certificate", name),
+				"Issue the certificate from a trusted CA (e.g. cert-manager with ACME)")
+		}
+		leaves = append(leaves, info.chain[0])
+		for _, f := range evaluateCert(info, now, res) {
+			f.Message = fmt.Sprintf("TLS secret %s: %s", name, f.Message)
+			findings = append(findings, f)
+		}
+		for _, h := range hosts {
+			if info.chain[0].VerifyHostname(h) != nil {
+				add("ing-tls-host-uncovered", "FAIL", fmt.Sprintf("Host %s is not covered by the SANs of secret %s (%s)", h, name, strings.Join(info.SANs, ", ")),
+					"Reissue the certificate with the host as a SAN, or fix spec.tls[].hosts")
+			}
+		}
+	}
+	if len(tlsEntries) == 0 || unreadable {
+		return findings
+	}
+	covered := func(h string) bool {
+		for _, c := range leaves {
+			if c.VerifyHostname(h) == nil {
+				return true
+			}
+		}
+		return false
+	}
+	for _, h := range ruleHosts {
+		if !listed[h] && !covered(h) {
+			add("ing-tls-host-not-listed", "WARN", fmt.Sprintf("Host %s is not listed in spec.tls[].hosts and no TLS secret covers it — it is served with the default certificate", h),
+				"Add the host to the spec.tls entry whose certificate covers it")
+		}
+	}
+	return findings
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+func tlsSecret(typ string, certPEM, keyPEM []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"type": typ,
+		"data": map[string]interface{}{
+			"tls.crt": base64.StdEncoding.EncodeToString(certPEM),
+			"tls.key": base64.StdEncoding.EncodeToString(keyPEM),
+		},
+	}
+}
+
+func tlsIngress(tls []interface{}, hosts ...string) map[string]interface{} {
+	var rules []interface{}
+	for _, h := range hosts {
+		rules = append(rules, map[string]interface{}{"host": h})
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"namespace": "team", "name": "web"},
+		"spec":     map[string]interface{}{"tls": tls, "rules": rules},
+	}
+}
+
+func ecKeyPEM(t *testing.T, k interface{}) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// ─── evaluateIngressTLS ──────────────────────────────────────────────────────
+
+func TestEvaluateIngressTLSValid(t *testing.T) {
+	now := time.Now()
+	caKey, leafKey := ecKey(t), ecKey(t)
+	_, ca := testCert(t, "Test CA", caKey, nil, nil, x509.ECDSAWithSHA256, now.Add(365*24*time.Hour))
+	leafPEM, _ := testCert(t, "*.example.com", leafKey, ca, caKey, x509.ECDSAWithSHA256, now.Add(90*24*time.Hour))
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	ing := tlsIngress([]interface{}{
+		map[string]interface{}{"hosts": []interface{}{"a.example.com", "b.example.com"}, "secretName": "web-tls"},
+	}, "a.example.com", "b.example.com")
+	secrets := map[string]map[string]interface{}{"team/web-tls": tlsSecret("kubernetes.io/tls", leafPEM, ecKeyPEM(t, leafKey))}
+	if fs := evaluateIngressTLS(ing, secrets, roots, now); len(fs) != 0 {
+		t.Errorf("findings = %+v", fs)
+	}
+	// A rule host missing from tls[].hosts is still served by the
+	// certificate that matches it.
+	ing = tlsIngress([]interface{}{
+		map[string]interface{}{"hosts": []interface{}{"a.example.com"}, "secretName": "web-tls"},
+	}, "a.example.com", "b.example.com")
+	if fs := evaluateIngressTLS(ing, secrets, roots, now); len(fs) != 0 {
+		t.Errorf("findings = %+v", fs)
+	}
+	// Without the intermediate/root the issuer is unknown.
+	if fs := evaluateIngressTLS(ing, secrets, x509.NewCertPool(), now); len(fs) != 1 || fs[0].ID != "ing-tls-chain-incomplete" {
+		t.Errorf("findings = %+v", fs)
+	}
+}
+
+func TestEvaluateIngressTLSProblems(t *testing.T) {
+	now := time.Now()
+	key, other := ecKey(t), ecKey(t)
+	certPEM, _ := testCert(t, "a.example.com", key, nil, nil, x509.ECDSAWithSHA256, now.Add(10*24*time.Hour))
+
+	ing := tlsIngress([]interface{}{
+		map[string]interface{}{"hosts": []interface{}{"a.example.com", "c.example.com"}, "secretName": "web-tls"},
+		map[string]interface{}{"hosts": []interface{}{"d.example.com"}, "secretName": "gone"},
+	}, "a.example.com", "c.example.com", "e.example.com")
+	secrets := map[string]map[string]interface{}{"team/web-tls": tlsSecret("Opaque", certPEM, ecKeyPEM(t, other))}
+	fs := evaluateIngressTLS(ing, secrets, nil, now)
+
+	want := map[string]string{
+		"ing-tls-secret-type":     "type Opaque",
+		"ing-tls-key-mismatch":    "tls.key does not match",
+		"ing-tls-self-signed":     "self-signed",
+		"cert-expiring":           "TLS secret web-tls: Certificate CN=a.example.com expires",
+		"ing-tls-host-uncovered":  "Host c.example.com",
+		"ing-tls-secret-missing":  "gone not found in namespace team (hosts: d.example.com)",
+		"ing-tls-host-not-listed": "Host e.example.com",
+	}
+	if len(fs) != len(want) {
+		t.Fatalf("findings = %+v", fs)
+	}
+	for id, msg := range want {
+		f, ok := findingByID(fs, id)
+		if !ok || !strings.Contains(f.Message, msg) || f.Resource != "ingress/team/web" {
+			t.Errorf("%s = %+v, want message containing %q", id, f, msg)
+		}
+	}
+}
+
+func TestEvaluateIngressTLSInvalidData(t *testing.T) {
+	ing := tlsIngress([]interface{}{map[string]interface{}{"secretName": "web-tls"}}, "a.example.com")
+	secrets := map[string]map[string]interface{}{"team/web-tls": tlsSecret("kubernetes.io/tls", []byte("junk"), nil)}
+	fs := evaluateIngressTLS(ing, secrets, nil, time.Now())
+	if _, ok := findingByID(fs, "ing-tls-cert-invalid"); !ok {
+		t.Errorf("findings = %+v", fs)
+	}
+}
+
+func TestEvaluateIngressTLSUnreadableSecret(t *testing.T) {
+	ing := tlsIngress([]interface{}{
+		map[string]interface{}{"hosts": []interface{}{"a.example.com"}, "secretName": "web-tls"},
+	}, "a.example.com", "b.example.com")
+	fs := evaluateIngressTLS(ing, map[string]map[string]interface{}{"team/web-tls": nil}, nil, time.Now())
+	if ids := findingIDs(fs); len(ids) != 1 || ids[0] != "ing-tls-secret-unreadable" {
+		t.Errorf("findings = %+v", fs)
+	}
+}
+
+// ─── chainProblem ────────────────────────────────────────────────────────────
+
+func TestChainProblemOutOfOrder(t *testing.T) {
+	now := time.Now()
+	caKey, leafKey := ecKey(t), ecKey(t)
+	_, ca := testCert(t, "Test CA", caKey, nil, nil, x509.ECDSAWithSHA256, now.Add(365*24*time.Hour))
+	_, leaf := testCert(t, "a.example.com", leafKey, ca, caKey, x509.ECDSAWithSHA256, now.Add(90*24*time.Hour))
+	if p := chainProblem([]*x509.Certificate{ca, leaf}, nil); !strings.Contains(p, "out of order") {
+		t.Errorf("chainProblem = %q", p)
+	}
+	if p := chainProblem([]*x509.Certificate{leaf, ca}, nil); p != "" {
+		t.Errorf("chainProblem = %q", p)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Cluster-wide external service inventory
+// ─────────────────────────────────────────────
+
+// ExternalService is one LoadBalancer/NodePort service in the inventory.
+type ExternalService struct {
+	Namespace       string   `json:"namespace"`
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	Addresses       []string `json:"addresses"`
+	Ports           []string `json:"ports"`
+	Selector        string   `json:"selector"`
+	Allowlisted     bool     `json:"allowlisted"`
+	SensitiveTarget string   `json:"sensitive_target,omitempty"`
+	// SensitivePortOnly is set when only the port number, not the service
+	// name or selector, points at the sensitive workload.
+	SensitivePortOnly bool `json:"sensitive_port_only,omitempty"`
+}
+
+// sensitivePorts maps well-known ports of workloads that should never sit
+// behind an external service to a short description.
+var sensitivePorts = map[int]string{
+	2379:  "etcd",
+	3000:  "Grafana",
+	3306:  "MySQL/MariaDB",
+	5432:  "PostgreSQL",
+	5601:  "Kibana",
+	6379:  "Redis",
+	9090:  "Prometheus",
+	9093:  "Alertmanager",
+	9100:  "node-exporter metrics",
+	9200:  "Elasticsearch",
+	10254: "ingress-nginx metrics",
+	15672: "RabbitMQ management",
+	27017: "MongoDB",
+}
+
+// sensitiveNames are matched against service names and selector values.
+var sensitiveNames = []struct{ keyword, desc string }{
+	{"postgres", "PostgreSQL"},
+	{"mysql", "MySQL/MariaDB"},
+	{"mariadb", "MySQL/MariaDB"},
+	{"mongo", "MongoDB"},
+	{"redis", "Redis"},
+	{"elasticsearch", "Elasticsearch"},
+	{"kibana", "Kibana"},
+	{"grafana", "Grafana"},
+	{"prometheus", "Prometheus"},
+	{"alertmanager", "Alertmanager"},
+	{"dashboard", "dashboard"},
+	{"etcd", "etcd"},
+}
+
+// parseAllowlist reads allowlist file content: one "namespace/name" entry
+// per line, glob patterns allowed, "#" starts a comment.
+func parseAllowlist(content string) []string {
+	var out []string
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// allowlisted reports whether ns/name matches any allowlist pattern.
+func allowlisted(patterns []string, ns, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, ns+"/"+name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveTarget returns a description of the well-known sensitive workload
+// a service appears to front, or "" when none is recognised. portOnly is
+// set when nothing but a port number matched: ports such as 3000 or 9090
+// are common defaults of unrelated applications.
+func sensitiveTarget(name string, selector map[string]string, ports []interface{}) (target string, portOnly bool) {
+	haystack := []string{strings.ToLower(name)}
+	for _, v := range selector {
+		haystack = append(haystack, strings.ToLower(v))
+	}
+	sort.Strings(haystack[1:])
+	byName := ""
+names:
+	for _, s := range sensitiveNames {
+		for _, h := range haystack {
+			if strings.Contains(h, s.keyword) {
+				byName = s.desc
+				break names
+			}
+		}
+	}
+	for _, p := range ports {
+		pm, _ := p.(map[string]interface{})
+		for _, key := range []string{"targetPort", "port"} {
+			n, ok := toInt(pm[key])
+			desc, known := sensitivePorts[n]
+			if ok && known && (byName == "" || desc == byName) {
+				return fmt.Sprintf("%s (port %d)", desc, n), byName == ""
+			}
+		}
+	}
+	return byName, false
+}
+
+// buildExternalService turns a decoded Service object into an inventory entry.
+// It returns false for services that are not LoadBalancer or NodePort.
+func buildExternalService(svc map[string]interface{}, patterns []string) (ExternalService, bool) {
+	meta := getMap(svc, "metadata")
+	spec := getMap(svc, "spec")
+	t := getString(spec, "type")
+	if t != "LoadBalancer" && t != "NodePort" {
+		return ExternalService{}, false
+	}
+	es := ExternalService{
+		Namespace: getString(meta, "namespace"),
+		Name:      getString(meta, "name"),
+		Type:      t,
+	}
+	for _, ing := range getSlice(getMap(getMap(svc, "status"), "loadBalancer"), "ingress") {
+		im, _ := ing.(map[string]interface{})
+		if ip := getString(im, "ip"); ip != "" {
+			es.Addresses = append(es.Addresses, ip)
+		} else if host := getString(im, "hostname"); host != "" {
+			es.Addresses = append(es.Addresses, host)
+		}
+	}
+	for _, ip := range getSlice(spec, "externalIPs") {
+		es.Addresses = append(es.Addresses, fmt.Sprintf("%v", ip))
+	}
+	ports := getSlice(spec, "ports")
+	for _, p := range ports {
+		pm, _ := p.(map[string]interface{})
+		port, _ := toInt(pm["port"])
+		proto := getString(pm, "protocol")
+		if proto == "" {
+			proto = "TCP"
+		}
+		if np, ok := toInt(pm["nodePort"]); ok {
+			es.Ports = append(es.Ports, fmt.Sprintf("%d:%d/%s", port, np, proto))
+		} else {
+			es.Ports = append(es.Ports, fmt.Sprintf("%d/%s", port, proto))
+		}
+	}
+	selector := getStringMap(spec, "selector")
+	var sel []string
+	for k, v := range selector {
+		sel = append(sel, k+"="+v)
+	}
+	sort.Strings(sel)
+	es.Selector = strings.Join(sel, ",")
+	es.Allowlisted = allowlisted(patterns, es.Namespace, es.Name)
+	es.SensitiveTarget, es.SensitivePortOnly = sensitiveTarget(es.Name, selector, ports)
+	return es, true
+}
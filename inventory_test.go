@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// ─── parseAllowlist / allowlisted ────────────────────────────────────────────
+
+func TestParseAllowlist(t *testing.T) {
+	content := "# expected external services\ningress-nginx/*\n\n  team-a/api  # public API\n"
+	got := parseAllowlist(content)
+	if len(got) != 2 || got[0] != "ingress-nginx/*" || got[1] != "team-a/api" {
+		t.Errorf("parseAllowlist = %q", got)
+	}
+	if !allowlisted(got, "ingress-nginx", "anything") {
+		t.Error("glob entry should match")
+	}
+	if !allowlisted(got, "team-a", "api") || allowlisted(got, "team-a", "db") {
+		t.Error("exact entry should match only its own service")
+	}
+}
+
+// ─── buildExternalService ────────────────────────────────────────────────────
+
+func TestBuildExternalService(t *testing.T) {
+	var svc map[string]interface{}
+	_ = json.Unmarshal([]byte(`{
+		"metadata":{"namespace":"data","name":"orders-db"},
+		"spec":{"type":"LoadBalancer","selector":{"app":"postgres"},
+			"ports":[{"port":5432,"nodePort":31432,"protocol":"TCP"}]},
+		"status":{"loadBalancer":{"ingress":[{"hostname":"abc.elb.amazonaws.com"}]}}
+	}`), &svc)
+	es, ok := buildExternalService(svc, nil)
+	if !ok {
+		t.Fatal("LoadBalancer service should be included")
+	}
+	if es.Addresses[0] != "abc.elb.amazonaws.com" || es.Ports[0] != "5432:31432/TCP" || es.Selector != "app=postgres" {
+		t.Errorf("unexpected entry: %+v", es)
+	}
+	if !strings.Contains(es.SensitiveTarget, "PostgreSQL") || es.SensitivePortOnly || es.Allowlisted {
+		t.Errorf("sensitive/allowlist = %q/%v", es.SensitiveTarget, es.Allowlisted)
+	}
+
+	_ = json.Unmarshal([]byte(`{"metadata":{"name":"x"},"spec":{"type":"ClusterIP"}}`), &svc)
+	if _, ok := buildExternalService(svc, nil); ok {
+		t.Error("ClusterIP services must be excluded")
+	}
+}
+
+func TestSensitiveTarget_bySelector(t *testing.T) {
+	got, portOnly := sensitiveTarget("web", map[string]string{"app.kubernetes.io/name": "kubernetes-dashboard"}, nil)
+	if got != "dashboard" || portOnly {
+		t.Errorf("sensitiveTarget = %q, %v; want dashboard, false", got, portOnly)
+	}
+	if got, _ := sensitiveTarget("shop", map[string]string{"app": "shop"}, nil); got != "" {
+		t.Errorf("plain service flagged as %q", got)
+	}
+}
+
+func TestSensitiveTarget_portOnly(t *testing.T) {
+	ports := []interface{}{map[string]interface{}{"port": 80, "targetPort": 3000}}
+	got, portOnly := sensitiveTarget("shop-frontend", map[string]string{"app": "shop"}, ports)
+	if got != "Grafana (port 3000)" || !portOnly {
+		t.Errorf("sensitiveTarget = %q, %v; want an unconfirmed Grafana port match", got, portOnly)
+	}
+	got, portOnly = sensitiveTarget("monitoring", map[string]string{"app.kubernetes.io/name": "grafana"}, ports)
+	if got != "Grafana (port 3000)" || portOnly {
+		t.Errorf("sensitiveTarget = %q, %v; want a confirmed Grafana match", got, portOnly)
+	}
+}
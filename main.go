@@ -8,6 +8,7 @@ import (
 
 func main() {
 	a := &AuditState{}
+	parseFlags(a)
 	interactiveSetup(a)
 	if a.ScanAll && len(a.Namespaces) > 1 {
 		runMultiNamespaceScan(a)
@@ -29,6 +30,7 @@ func runAudit(a *AuditState) {
 	a.auditVulnerabilities()
 	a.auditCertificates()
 	a.auditIngressResources()
+	a.auditAvailability()
 	a.generateJSONReport()
 	a.generateSummary()
 	_ = os.WriteFile(a.TextReportFile, a.OutputBuffer.Bytes(), 0644)
@@ -48,6 +50,7 @@ func runMultiNamespaceScan(a *AuditState) {
 			Domain:         a.Domain,
 			Email:          a.Email,
 			ControllerName: a.ControllerName,
+			Options:        a.Options,
 			TextReportFile: fmt.Sprintf("ingress-audit-%s-%s.txt", ns, ts),
 			JSONReportFile: fmt.Sprintf("ingress-audit-%s-%s.json", ns, ts),
 		}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// NetworkPolicy evaluation
+// ─────────────────────────────────────────────
+
+// Default controller container ports. The named ports of the controller
+// container take precedence when the pod template declares them.
+const (
+	webhookPort = 8443
+	metricsPort = 10254
+)
+
+// PortExposure describes who may reach one controller port once every policy
+// selecting the controller pods has been applied.
+type PortExposure struct {
+	Name        string   `json:"name"`
+	Port        int      `json:"port"`
+	Isolated    bool     `json:"isolated"`         // a policy selects the pods for ingress
+	Anywhere    bool     `json:"anywhere"`         // a rule admits every source
+	ClusterWide bool     `json:"cluster_wide"`     // a rule admits every pod in the cluster
+	Selectors   bool     `json:"selector_sources"` // a rule admits pods or namespaces by label
+	Sources     []string `json:"allowed_sources"`
+	Engines     []string `json:"engines"` // policy engines whose rules admit the sources
+}
+
+// peerScope classifies the sources admitted by one policy peer, from the
+// narrowest to the broadest.
+type peerScope int
+
+const (
+	scopeCIDR     peerScope = iota // IP blocks, the API server or the nodes
+	scopeSelector                  // pods or namespaces chosen by label
+	scopeCluster                   // every pod in the cluster
+	scopeAnywhere                  // every source, in the cluster or not
+)
+
+// NetPolEvaluation is the effective NetworkPolicy posture of the controller pods.
+type NetPolEvaluation struct {
+	CNI               string         `json:"cni,omitempty"`
+	Engines           []string       `json:"engines"` // policy engines isolating the pods
+	SelectingPolicies []string       `json:"selecting_policies"`
+	IngressIsolated   bool           `json:"ingress_isolated"`
+	EgressRestricted  bool           `json:"egress_restricted"`
+	Ports             []PortExposure `json:"ports"`
+}
+
+// namedPort is a controller container port evaluated against policy rules.
+type namedPort struct {
+	name string
+	port int
+}
+
+// controllerPolicyPorts returns the ports evaluated for the controller pods,
+// preferring the numbers declared on the controller container.
+func controllerPolicyPorts(declared map[string]int) []namedPort {
+	ports := []namedPort{
+		{"http", 80},
+		{"https", 443},
+		{"webhook", webhookPort},
+		{"metrics", metricsPort},
+	}
+	for i, p := range ports {
+		if n, ok := declared[p.name]; ok {
+			ports[i].port = n
+		}
+	}
+	return ports
+}
+
+// labelSelectorMatches reports whether a metav1.LabelSelector (decoded as a
+// map) selects an object with the given labels. An empty selector matches all.
+func labelSelectorMatches(sel map[string]interface{}, labels map[string]string) bool {
+	for k, v := range getStringMap(sel, "matchLabels") {
+		if labels[k] != v {
+			return false
+		}
+	}
+	for _, e := range getSlice(sel, "matchExpressions") {
+		em, _ := e.(map[string]interface{})
+		key := getString(em, "key")
+		val, has := labels[key]
+		values := map[string]bool{}
+		for _, v := range getSlice(em, "values") {
+			values[fmt.Sprintf("%v", v)] = true
+		}
+		switch getString(em, "operator") {
+		case "In":
+			if !has || !values[val] {
+				return false
+			}
+		case "NotIn":
+			if has && values[val] {
+				return false
+			}
+		case "Exists":
+			if !has {
+				return false
+			}
+		case "DoesNotExist":
+			if has {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// describeSelector renders a label selector compactly, e.g. "app=web,tier In (a,b)".
+// An empty selector is rendered as "*".
+func describeSelector(sel map[string]interface{}) string {
+	var parts []string
+	for k, v := range getStringMap(sel, "matchLabels") {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	for _, e := range getSlice(sel, "matchExpressions") {
+		em, _ := e.(map[string]interface{})
+		var vals []string
+		for _, v := range getSlice(em, "values") {
+			vals = append(vals, fmt.Sprintf("%v", v))
+		}
+		expr := getString(em, "key") + " " + getString(em, "operator")
+		if len(vals) > 0 {
+			expr += " (" + strings.Join(vals, ",") + ")"
+		}
+		parts = append(parts, expr)
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, ",")
+}
+
+// selectsAll reports whether a label selector matches every object that
+// carries key: it has no matchLabels and only Exists expressions on key.
+// Kubernetes sets kubernetes.io/metadata.name on every namespace, so a
+// namespace selector of that form matches them all, like an empty one.
+func selectsAll(sel map[string]interface{}, key string) bool {
+	if len(getStringMap(sel, "matchLabels")) > 0 {
+		return false
+	}
+	for _, e := range getSlice(sel, "matchExpressions") {
+		em, _ := e.(map[string]interface{})
+		if getString(em, "key") != key || getString(em, "operator") != "Exists" {
+			return false
+		}
+	}
+	return true
+}
+
+// describePeer renders a NetworkPolicyPeer and classifies the sources it
+// admits. An empty or match-all namespaceSelector admits every pod in the
+// cluster; 0.0.0.0/0 or ::/0 without exceptions admits every source.
+func describePeer(peer map[string]interface{}, ns string) (string, peerScope) {
+	if ipb, ok := peer["ipBlock"].(map[string]interface{}); ok {
+		cidr := getString(ipb, "cidr")
+		var except []string
+		for _, e := range getSlice(ipb, "except") {
+			except = append(except, fmt.Sprintf("%v", e))
+		}
+		if len(except) > 0 {
+			return fmt.Sprintf("ipBlock %s (except %s)", cidr, strings.Join(except, ",")), scopeCIDR
+		}
+		if cidr == "0.0.0.0/0" || cidr == "::/0" {
+			return "ipBlock " + cidr, scopeAnywhere
+		}
+		return "ipBlock " + cidr, scopeCIDR
+	}
+	nsSel, hasNS := peer["namespaceSelector"].(map[string]interface{})
+	podSel, hasPod := peer["podSelector"].(map[string]interface{})
+	allNS := hasNS && selectsAll(nsSel, "kubernetes.io/metadata.name")
+	switch {
+	case hasNS && hasPod:
+		desc := fmt.Sprintf("pods{%s} in namespaces{%s}", describeSelector(podSel), describeSelector(nsSel))
+		if allNS && selectsAll(podSel, "") {
+			return desc, scopeCluster
+		}
+		return desc, scopeSelector
+	case hasNS:
+		if allNS {
+			return fmt.Sprintf("namespaces{%s}", describeSelector(nsSel)), scopeCluster
+		}
+		return fmt.Sprintf("namespaces{%s}", describeSelector(nsSel)), scopeSelector
+	case hasPod:
+		return fmt.Sprintf("pods{%s} in %s", describeSelector(podSel), ns), scopeSelector
+	}
+	return "anywhere", scopeAnywhere
+}
+
+// ruleCoversPort reports whether the ports list of an ingress/egress rule
+// includes p. A rule without ports applies to every port.
+func ruleCoversPort(rule map[string]interface{}, p namedPort) bool {
+	ports := getSlice(rule, "ports")
+	if len(ports) == 0 {
+		return true
+	}
+	for _, rp := range ports {
+		pm, _ := rp.(map[string]interface{})
+		proto := getString(pm, "protocol")
+		if proto != "" && proto != "TCP" {
+			continue
+		}
+		switch v := pm["port"].(type) {
+		case nil:
+			return true
+		case string:
+			if v == p.name {
+				return true
+			}
+		default:
+			n, _ := toInt(v)
+			end, hasEnd := toInt(pm["endPort"])
+			if n == p.port || (hasEnd && p.port >= n && p.port <= end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// policyTypes returns the effective policyTypes of a NetworkPolicy spec,
+// applying the API defaulting rules when the field is omitted.
+func policyTypes(spec map[string]interface{}) (ingress, egress bool) {
+	types := getSlice(spec, "policyTypes")
+	if len(types) == 0 {
+		_, hasEgress := spec["egress"]
+		return true, hasEgress
+	}
+	for _, t := range types {
+		switch t {
+		case "Ingress":
+			ingress = true
+		case "Egress":
+			egress = true
+		}
+	}
+	return ingress, egress
+}
+
+// policyAccumulator merges the effect of every policy that selects the
+// controller pods, whichever policy engine supplied it.
+type policyAccumulator struct {
+	ports          []namedPort
+	ev             NetPolEvaluation
+	sources        []map[string]bool
+	engines        []map[string]bool
+	isolating      map[string]bool
+	scopes         []map[peerScope]bool
+	egressAllowAll bool
+}
+
+func newPolicyAccumulator(ports []namedPort) *policyAccumulator {
+	acc := &policyAccumulator{
+		ports:     ports,
+		sources:   make([]map[string]bool, len(ports)),
+		engines:   make([]map[string]bool, len(ports)),
+		isolating: map[string]bool{},
+		scopes:    make([]map[peerScope]bool, len(ports)),
+	}
+	for i := range ports {
+		acc.sources[i] = map[string]bool{}
+		acc.engines[i] = map[string]bool{}
+		acc.scopes[i] = map[peerScope]bool{}
+	}
+	return acc
+}
+
+// selectPolicy records a policy that selects the controller pods and whether
+// it isolates ingress and/or egress.
+func (acc *policyAccumulator) selectPolicy(name, engine string, ingress, egress bool) {
+	acc.ev.SelectingPolicies = append(acc.ev.SelectingPolicies, name)
+	if ingress {
+		acc.ev.IngressIsolated = true
+		acc.isolating[engine] = true
+	}
+	if egress {
+		acc.ev.EgressRestricted = true
+	}
+}
+
+// grant records an ingress allow rule: the described sources, of the given
+// scope, may reach every controller port for which covers returns true.
+func (acc *policyAccumulator) grant(engine string, covers func(namedPort) bool, sources []string, scope peerScope) {
+	for i, port := range acc.ports {
+		if !covers(port) {
+			continue
+		}
+		acc.engines[i][engine] = true
+		for _, s := range sources {
+			acc.sources[i][s] = true
+		}
+		acc.scopes[i][scope] = true
+	}
+}
+
+// result returns the merged evaluation.
+func (acc *policyAccumulator) result() NetPolEvaluation {
+	ev := acc.ev
+	ev.Ports = nil
+	if acc.egressAllowAll {
+		ev.EgressRestricted = false
+	}
+	ev.Engines = sortedKeys(acc.isolating)
+	for i, port := range acc.ports {
+		anywhere := acc.scopes[i][scopeAnywhere] || !ev.IngressIsolated
+		pe := PortExposure{
+			Name:        port.name,
+			Port:        port.port,
+			Isolated:    ev.IngressIsolated,
+			Anywhere:    anywhere,
+			ClusterWide: anywhere || acc.scopes[i][scopeCluster],
+			Selectors:   acc.scopes[i][scopeSelector],
+			Sources:     sortedKeys(acc.sources[i]),
+			Engines:     sortedKeys(acc.engines[i]),
+		}
+		if !ev.IngressIsolated {
+			pe.Sources = []string{"anywhere (not isolated)"}
+		}
+		ev.Ports = append(ev.Ports, pe)
+	}
+	return ev
+}
+
+// sortedKeys returns the keys of a set in sorted order.
+func sortedKeys(set map[string]bool) []string {
+	var out []string
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// addNetworkPolicies feeds core networking.k8s.io/v1 NetworkPolicies into acc.
+func (acc *policyAccumulator) addNetworkPolicies(policies []interface{}, podLabels map[string]string, ns string) {
+	const engine = "NetworkPolicy"
+	for _, p := range policies {
+		pm, _ := p.(map[string]interface{})
+		spec := getMap(pm, "spec")
+		if !labelSelectorMatches(getMap(spec, "podSelector"), podLabels) {
+			continue
+		}
+		ingress, egress := policyTypes(spec)
+		acc.selectPolicy(getString(getMap(pm, "metadata"), "name"), engine, ingress, egress)
+		if ingress {
+			for _, r := range getSlice(spec, "ingress") {
+				rule, _ := r.(map[string]interface{})
+				covers := func(p namedPort) bool { return ruleCoversPort(rule, p) }
+				peers := getSlice(rule, "from")
+				if len(peers) == 0 {
+					acc.grant(engine, covers, []string{"anywhere"}, scopeAnywhere)
+				}
+				for _, peer := range peers {
+					peerMap, _ := peer.(map[string]interface{})
+					desc, scope := describePeer(peerMap, ns)
+					acc.grant(engine, covers, []string{desc}, scope)
+				}
+			}
+		}
+		if egress {
+			for _, r := range getSlice(spec, "egress") {
+				rule, _ := r.(map[string]interface{})
+				if len(getSlice(rule, "ports")) == 0 && egressToAnywhere(getSlice(rule, "to"), ns) {
+					acc.egressAllowAll = true
+				}
+			}
+		}
+	}
+}
+
+// egressToAnywhere reports whether the destinations of an egress rule admit
+// every address: the rule has none, or one of them is 0.0.0.0/0 or ::/0
+// without exceptions.
+func egressToAnywhere(peers []interface{}, ns string) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		peerMap, _ := peer.(map[string]interface{})
+		if _, ok := peerMap["ipBlock"]; !ok {
+			continue
+		}
+		if _, scope := describePeer(peerMap, ns); scope == scopeAnywhere {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateNetworkPolicies computes which core NetworkPolicies select the
+// controller pods and the union of sources they admit per controller port.
+func evaluateNetworkPolicies(policies []interface{}, podLabels map[string]string, ns string, ports []namedPort) NetPolEvaluation {
+	acc := newPolicyAccumulator(ports)
+	acc.addNetworkPolicies(policies, podLabels, ns)
+	return acc.result()
+}
+
+// ─────────────────────────────────────────────
+// Least-privilege NetworkPolicy generation
+// ─────────────────────────────────────────────
+
+// stablePodLabels picks the labels of the controller pods that are stable
+// across rollouts (the app.kubernetes.io/* set when present).
+func stablePodLabels(labels map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, k := range []string{"app.kubernetes.io/name", "app.kubernetes.io/instance", "app.kubernetes.io/component"} {
+		if v, ok := labels[k]; ok {
+			out[k] = v
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+	for k, v := range labels {
+		if k != "pod-template-hash" && k != "controller-revision-hash" && k != "pod-template-generation" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// buildControllerNetworkPolicy renders a least-privilege NetworkPolicy for the
+// controller pods as YAML: http/https from anywhere, the webhook port only
+// from the control-plane CIDRs and the metrics port only from monitoringNS.
+func buildControllerNetworkPolicy(ns string, podLabels map[string]string, ports []namedPort,
+	cpCIDRs []string, monitoringNS string) string {
+	portOf := map[string]int{}
+	for _, p := range ports {
+		portOf[p.name] = p.port
+	}
+	var keys []string
+	sel := stablePodLabels(podLabels)
+	for k := range sel {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("apiVersion: networking.k8s.io/v1\n")
+	b.WriteString("kind: NetworkPolicy\n")
+	b.WriteString("metadata:\n")
+	b.WriteString("  name: ingress-nginx-controller-least-privilege\n")
+	fmt.Fprintf(&b, "  namespace: %s\n", ns)
+	b.WriteString("spec:\n")
+	b.WriteString("  podSelector:\n")
+	b.WriteString("    matchLabels:\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "      %s: %q\n", k, sel[k])
+	}
+	b.WriteString("  policyTypes:\n")
+	b.WriteString("    - Ingress\n")
+	b.WriteString("  ingress:\n")
+	b.WriteString("    - ports:\n")
+	fmt.Fprintf(&b, "        - protocol: TCP\n          port: %d\n", portOf["http"])
+	fmt.Fprintf(&b, "        - protocol: TCP\n          port: %d\n", portOf["https"])
+	if len(cpCIDRs) > 0 {
+		b.WriteString("    - from:\n")
+		for _, c := range cpCIDRs {
+			fmt.Fprintf(&b, "        - ipBlock:\n            cidr: %s\n", c)
+		}
+		fmt.Fprintf(&b, "      ports:\n        - protocol: TCP\n          port: %d\n", portOf["webhook"])
+	}
+	if monitoringNS != "" {
+		b.WriteString("    - from:\n")
+		b.WriteString("        - namespaceSelector:\n")
+		b.WriteString("            matchLabels:\n")
+		fmt.Fprintf(&b, "              kubernetes.io/metadata.name: %s\n", monitoringNS)
+		fmt.Fprintf(&b, "      ports:\n        - protocol: TCP\n          port: %d\n", portOf["metrics"])
+	}
+	return b.String()
+}
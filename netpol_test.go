@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// decodePolicies turns a JSON array of NetworkPolicy objects into the
+// []interface{} shape returned by kubectlJSON.
+func decodePolicies(t *testing.T, s string) []interface{} {
+	t.Helper()
+	var out []interface{}
+	if err := json.Unmarshal([]byte(s), &out); err != nil {
+		t.Fatalf("bad fixture: %v", err)
+	}
+	return out
+}
+
+var ctrlLabels = map[string]string{
+	"app.kubernetes.io/name":      "ingress-nginx",
+	"app.kubernetes.io/component": "controller",
+}
+
+func findPort(ev NetPolEvaluation, name string) PortExposure {
+	for _, p := range ev.Ports {
+		if p.Name == name {
+			return p
+		}
+	}
+	return PortExposure{}
+}
+
+// ─── labelSelectorMatches ────────────────────────────────────────────────────
+
+func TestLabelSelectorMatches(t *testing.T) {
+	cases := []struct {
+		sel  string
+		want bool
+	}{
+		{`{}`, true},
+		{`{"matchLabels":{"app.kubernetes.io/name":"ingress-nginx"}}`, true},
+		{`{"matchLabels":{"app":"web"}}`, false},
+		{`{"matchExpressions":[{"key":"app.kubernetes.io/component","operator":"In","values":["controller"]}]}`, true},
+		{`{"matchExpressions":[{"key":"app.kubernetes.io/component","operator":"NotIn","values":["controller"]}]}`, false},
+		{`{"matchExpressions":[{"key":"tier","operator":"DoesNotExist"}]}`, true},
+		{`{"matchExpressions":[{"key":"tier","operator":"Exists"}]}`, false},
+	}
+	for _, c := range cases {
+		var sel map[string]interface{}
+		_ = json.Unmarshal([]byte(c.sel), &sel)
+		if got := labelSelectorMatches(sel, ctrlLabels); got != c.want {
+			t.Errorf("labelSelectorMatches(%s) = %v, want %v", c.sel, got, c.want)
+		}
+	}
+}
+
+// ─── evaluateNetworkPolicies ─────────────────────────────────────────────────
+
+func TestEvaluateNetworkPolicies_unrelatedPolicyDoesNotIsolate(t *testing.T) {
+	policies := decodePolicies(t, `[{
+		"metadata":{"name":"allow-web"},
+		"spec":{"podSelector":{"matchLabels":{"app":"web"}},"ingress":[{}]}
+	}]`)
+	ev := evaluateNetworkPolicies(policies, ctrlLabels, "ingress-nginx", controllerPolicyPorts(nil))
+	if len(ev.SelectingPolicies) != 0 || ev.IngressIsolated {
+		t.Fatalf("unrelated policy should not select the controller: %+v", ev)
+	}
+	if !findPort(ev, "webhook").Anywhere {
+		t.Error("webhook port should be reachable from anywhere when not isolated")
+	}
+}
+
+func TestEvaluateNetworkPolicies_perPortSources(t *testing.T) {
+	policies := decodePolicies(t, `[{
+		"metadata":{"name":"controller"},
+		"spec":{
+			"podSelector":{"matchLabels":{"app.kubernetes.io/component":"controller"}},
+			"policyTypes":["Ingress","Egress"],
+			"ingress":[
+				{"ports":[{"port":80},{"port":"https"}]},
+				{"from":[{"ipBlock":{"cidr":"10.0.0.1/32"}}],"ports":[{"port":8443}]},
+				{"from":[{"namespaceSelector":{"matchLabels":{"kubernetes.io/metadata.name":"monitoring"}}}],"ports":[{"port":"metrics"}]}
+			],
+			"egress":[{"to":[{"ipBlock":{"cidr":"10.0.0.0/8"}}]}]
+		}
+	}]`)
+	ev := evaluateNetworkPolicies(policies, ctrlLabels, "ingress-nginx", controllerPolicyPorts(nil))
+	if !ev.IngressIsolated || !ev.EgressRestricted {
+		t.Fatalf("expected isolated ingress and restricted egress: %+v", ev)
+	}
+	if !findPort(ev, "https").Anywhere {
+		t.Error("https should be open to anywhere")
+	}
+	wh := findPort(ev, "webhook")
+	if wh.Anywhere || len(wh.Sources) != 1 || wh.Sources[0] != "ipBlock 10.0.0.1/32" {
+		t.Errorf("webhook sources = %v (anywhere=%v)", wh.Sources, wh.Anywhere)
+	}
+	m := findPort(ev, "metrics")
+	if m.Anywhere || len(m.Sources) != 1 || !strings.Contains(m.Sources[0], "monitoring") {
+		t.Errorf("metrics sources = %v", m.Sources)
+	}
+}
+
+func TestEvaluateNetworkPolicies_clusterWideSelectors(t *testing.T) {
+	cases := []struct {
+		peer                   string
+		clusterWide, selectors bool
+	}{
+		{`{"namespaceSelector":{}}`, true, false},
+		{`{"namespaceSelector":{},"podSelector":{}}`, true, false},
+		{`{"namespaceSelector":{"matchExpressions":[{"key":"kubernetes.io/metadata.name","operator":"Exists"}]}}`, true, false},
+		{`{"namespaceSelector":{},"podSelector":{"matchLabels":{"app":"prometheus"}}}`, false, true},
+		{`{"namespaceSelector":{"matchLabels":{"kubernetes.io/metadata.name":"kube-system"}}}`, false, true},
+		{`{"podSelector":{}}`, false, true},
+		{`{"ipBlock":{"cidr":"10.0.0.1/32"}}`, false, false},
+	}
+	for _, c := range cases {
+		policies := decodePolicies(t, `[{
+			"metadata":{"name":"webhook"},
+			"spec":{"podSelector":{},"ingress":[{"from":[`+c.peer+`],"ports":[{"port":8443}]}]}
+		}]`)
+		wh := findPort(evaluateNetworkPolicies(policies, ctrlLabels, "ingress-nginx", controllerPolicyPorts(nil)), "webhook")
+		if wh.Anywhere || wh.ClusterWide != c.clusterWide || wh.Selectors != c.selectors {
+			t.Errorf("%s: webhook = %+v, want clusterWide=%v selectors=%v", c.peer, wh, c.clusterWide, c.selectors)
+		}
+	}
+}
+
+func TestEvaluateNetworkPolicies_allowAllEgressIsNotRestricted(t *testing.T) {
+	policies := decodePolicies(t, `[{
+		"metadata":{"name":"egress-all"},
+		"spec":{"podSelector":{},"policyTypes":["Egress"],"egress":[{}]}
+	}]`)
+	ev := evaluateNetworkPolicies(policies, ctrlLabels, "ingress-nginx", controllerPolicyPorts(nil))
+	if ev.EgressRestricted {
+		t.Error("an allow-all egress rule must not count as restricted")
+	}
+	if ev.IngressIsolated {
+		t.Error("an Egress-only policy must not isolate ingress")
+	}
+}
+
+func TestEvaluateNetworkPolicies_egressToAllAddresses(t *testing.T) {
+	cases := []struct {
+		rule       string
+		restricted bool
+	}{
+		{`{"to":[{"ipBlock":{"cidr":"0.0.0.0/0"}}]}`, false},
+		{`{"to":[{"ipBlock":{"cidr":"10.0.0.0/8"}},{"ipBlock":{"cidr":"::/0"}}]}`, false},
+		{`{"to":[{"ipBlock":{"cidr":"0.0.0.0/0","except":["169.254.169.254/32"]}}]}`, true},
+		{`{"to":[{"ipBlock":{"cidr":"0.0.0.0/0"}}],"ports":[{"port":53,"protocol":"UDP"}]}`, true},
+		{`{"to":[{"namespaceSelector":{}}]}`, true},
+	}
+	for _, c := range cases {
+		policies := decodePolicies(t, `[{
+			"metadata":{"name":"egress"},
+			"spec":{"podSelector":{},"policyTypes":["Egress"],"egress":[`+c.rule+`]}
+		}]`)
+		ev := evaluateNetworkPolicies(policies, ctrlLabels, "ingress-nginx", controllerPolicyPorts(nil))
+		if ev.EgressRestricted != c.restricted {
+			t.Errorf("%s: restricted = %v, want %v", c.rule, ev.EgressRestricted, c.restricted)
+		}
+	}
+}
+
+// ─── buildControllerNetworkPolicy ────────────────────────────────────────────
+
+func TestBuildControllerNetworkPolicy(t *testing.T) {
+	labels := map[string]string{
+		"app.kubernetes.io/name":      "ingress-nginx",
+		"app.kubernetes.io/component": "controller",
+		"pod-template-hash":           "abc123",
+	}
+	y := buildControllerNetworkPolicy("ingress-nginx", labels,
+		controllerPolicyPorts(map[string]int{"metrics": 9913}), []string{"10.0.0.10/32"}, "monitoring")
+	for _, want := range []string{
+		"kind: NetworkPolicy",
+		"namespace: ingress-nginx",
+		`app.kubernetes.io/component: "controller"`,
+		"cidr: 10.0.0.10/32",
+		"port: 8443",
+		"kubernetes.io/metadata.name: monitoring",
+		"port: 9913",
+	} {
+		if !strings.Contains(y, want) {
+			t.Errorf("generated policy missing %q:\n%s", want, y)
+		}
+	}
+	if strings.Contains(y, "pod-template-hash") {
+		t.Error("generated selector must not include pod-template-hash")
+	}
+}
+
+func TestBuildControllerNetworkPolicy_noControlPlaneCIDR(t *testing.T) {
+	y := buildControllerNetworkPolicy("ns", ctrlLabels, controllerPolicyPorts(nil), nil, "")
+	if strings.Contains(y, "8443") || strings.Contains(y, "namespaceSelector") {
+		t.Errorf("webhook/metrics rules should be omitted without sources:\n%s", y)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Controller pod health
+// ─────────────────────────────────────────────
+
+// restartWarnThreshold is the container restart count worth reporting.
+const restartWarnThreshold = 5
+
+// PodHealth is one row of the per-pod health table.
+type PodHealth struct {
+	Name            string `json:"name"`
+	Node            string `json:"node"`
+	Status          string `json:"status"`    // kubectl-style STATUS column
+	Ready           string `json:"ready"`     // "ready/total" containers
+	PodReady        bool   `json:"pod_ready"` // Ready condition is True
+	Restarts        int    `json:"restarts"`
+	LastTermination string `json:"last_termination,omitempty"`
+	Images          string `json:"images"`
+}
+
+// podStatusSummary returns what kubectl shows in its STATUS column: the
+// first waiting or terminated reason of a container, else the pod phase.
+func podStatusSummary(pod map[string]interface{}) string {
+	if getString(getMap(pod, "metadata"), "deletionTimestamp") != "" {
+		return "Terminating"
+	}
+	status := getMap(pod, "status")
+	for _, key := range []string{"initContainerStatuses", "containerStatuses"} {
+		for _, s := range getSlice(status, key) {
+			state := getMap(asMap(s), "state")
+			if r := getString(getMap(state, "waiting"), "reason"); r != "" && r != "PodInitializing" {
+				return r
+			}
+			if r := getString(getMap(state, "terminated"), "reason"); r != "" && r != "Completed" {
+				return r
+			}
+		}
+	}
+	return getString(status, "phase")
+}
+
+// podCondition returns the condition of the given type.
+func podCondition(pod map[string]interface{}, condType string) map[string]interface{} {
+	for _, c := range getSlice(getMap(pod, "status"), "conditions") {
+		if cm := asMap(c); getString(cm, "type") == condType {
+			return cm
+		}
+	}
+	return nil
+}
+
+// analysePod builds the health row of a pod and the findings it raises.
+func analysePod(pod map[string]interface{}, ns string) (PodHealth, []Finding) {
+	meta, spec, status := getMap(pod, "metadata"), getMap(pod, "spec"), getMap(pod, "status")
+	name := getString(meta, "name")
+	res := fmt.Sprintf("pod/%s/%s", ns, name)
+	var findings []Finding
+	add := func(id, level, msg, fix string) {
+		findings = append(findings, Finding{ID: id, Level: level, Resource: res, Message: msg, Remediation: fix})
+	}
+
+	h := PodHealth{Name: name, Node: getString(spec, "nodeName"), Status: podStatusSummary(pod)}
+	var images []string
+	for _, c := range getSlice(spec, "containers") {
+		images = append(images, getString(asMap(c), "image"))
+	}
+	h.Images = strings.Join(images, ",")
+
+	ready := 0
+	crashing := false
+	var terminations []string
+	for _, key := range []string{"initContainerStatuses", "containerStatuses"} {
+		for _, s := range getSlice(status, key) {
+			sm := asMap(s)
+			cname := getString(sm, "name")
+			if key == "containerStatuses" && sm["ready"] == true {
+				ready++
+			}
+			restarts, _ := toInt(sm["restartCount"])
+			h.Restarts += restarts
+			if t := getMap(getMap(sm, "lastState"), "terminated"); len(t) > 0 {
+				reason := getString(t, "reason")
+				exit, _ := toInt(t["exitCode"])
+				terminations = append(terminations, fmt.Sprintf("%s: %s (exit %d)", cname, reason, exit))
+				if reason == "OOMKilled" || reason == "Error" {
+					add("pod-terminated", "WARN",
+						fmt.Sprintf("Container %s in pod %s was last terminated with %s (exit %d)", cname, name, reason, exit),
+						"Inspect kubectl logs --previous; raise the memory limit for OOMKilled")
+				}
+			}
+			waiting := getString(getMap(getMap(sm, "state"), "waiting"), "reason")
+			switch waiting {
+			case "CrashLoopBackOff":
+				crashing = true
+				add("pod-crashloop", "FAIL", fmt.Sprintf("Container %s in pod %s is in CrashLoopBackOff (%d restarts)", cname, name, restarts),
+					"Check kubectl logs --previous for the crash reason")
+			case "ImagePullBackOff", "ErrImagePull", "InvalidImageName":
+				add("pod-image-pull", "FAIL", fmt.Sprintf("Container %s in pod %s cannot pull its image (%s)", cname, name, waiting),
+					"Check the image reference and imagePullSecrets")
+			}
+			if restarts >= restartWarnThreshold && waiting != "CrashLoopBackOff" {
+				add("pod-restarts", "WARN", fmt.Sprintf("Container %s in pod %s restarted %d times", cname, name, restarts),
+					"Check kubectl logs --previous and the liveness probe")
+			}
+		}
+	}
+	h.Ready = fmt.Sprintf("%d/%d", ready, len(getSlice(spec, "containers")))
+	h.LastTermination = strings.Join(terminations, "; ")
+
+	h.PodReady = getString(podCondition(pod, "Ready"), "status") == "True"
+	phase := getString(status, "phase")
+	switch {
+	case h.Status == "Terminating":
+	case phase == "Pending":
+		if c := podCondition(pod, "PodScheduled"); getString(c, "status") == "False" {
+			add("pod-pending", "FAIL",
+				fmt.Sprintf("Pod %s cannot be scheduled (%s): %s", name, getString(c, "reason"), getString(c, "message")),
+				"Free capacity or relax nodeSelector/affinity/tolerations so the pod fits a node")
+		} else if h.Status == "Pending" || h.Status == "ContainerCreating" {
+			add("pod-pending", "WARN", fmt.Sprintf("Pod %s is scheduled but still %s", name, h.Status),
+				"kubectl describe pod to see volume mount or sandbox errors")
+		}
+	case phase == "Running" && !crashing:
+		// A crash-looping container already explains the pod not being ready.
+		if !h.PodReady {
+			add("pod-not-ready", "FAIL", fmt.Sprintf("Pod %s is Running but not Ready (%s containers ready)", name, h.Ready),
+				"Check the readiness probe and controller logs")
+		}
+	}
+	return h, findings
+}
+
+// evaluatePodHealth analyses every controller pod and flags a rollout that
+// left pods on different images. resType and name identify the workload.
+func evaluatePodHealth(pods []interface{}, ns, resType, name string) ([]PodHealth, []Finding) {
+	var rows []PodHealth
+	var findings []Finding
+	byImages := map[string][]string{}
+	for _, p := range pods {
+		pm := asMap(p)
+		if getString(getMap(pm, "status"), "phase") == "Succeeded" {
+			continue
+		}
+		h, fs := analysePod(pm, ns)
+		rows = append(rows, h)
+		findings = append(findings, fs...)
+		byImages[h.Images] = append(byImages[h.Images], h.Name)
+	}
+	if len(byImages) > 1 {
+		var imgs []string
+		for img := range byImages {
+			imgs = append(imgs, img)
+		}
+		sort.Strings(imgs)
+		var parts []string
+		for _, img := range imgs {
+			parts = append(parts, fmt.Sprintf("%s (%d pod(s))", img, len(byImages[img])))
+		}
+		findings = append(findings, Finding{ID: "pod-mixed-images", Level: "WARN",
+			Resource:    fmt.Sprintf("%s/%s/%s", resType, ns, name),
+			Message:     "Controller pods run different images: " + strings.Join(parts, "; "),
+			Rationale:   "A stalled rollout leaves old and new controller versions serving traffic side by side.",
+			Remediation: fmt.Sprintf("kubectl rollout status %s/%s -n %s, and fix whatever blocks the new pods", resType, name, ns)})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows, findings
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func healthPod(name, phase string, ready bool, statuses ...interface{}) map[string]interface{} {
+	readyStatus := "False"
+	if ready {
+		readyStatus = "True"
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"nodeName":   "node-1",
+			"containers": []interface{}{map[string]interface{}{"name": "controller", "image": "controller:v1.14.3"}},
+		},
+		"status": map[string]interface{}{
+			"phase":             phase,
+			"conditions":        []interface{}{map[string]interface{}{"type": "Ready", "status": readyStatus}},
+			"containerStatuses": statuses,
+		},
+	}
+}
+
+func containerStatus(ready bool, restarts int, state, lastState map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"name": "controller", "ready": ready, "restartCount": float64(restarts),
+		"state": state, "lastState": lastState,
+	}
+}
+
+var runningState = map[string]interface{}{"running": map[string]interface{}{}}
+
+// ─── analysePod ──────────────────────────────────────────────────────────────
+
+func TestAnalysePodHealthy(t *testing.T) {
+	h, fs := analysePod(healthPod("p", "Running", true, containerStatus(true, 0, runningState, nil)), "ns")
+	if len(fs) != 0 || !h.PodReady || h.Ready != "1/1" || h.Status != "Running" {
+		t.Errorf("h = %+v, findings = %+v", h, fs)
+	}
+}
+
+func TestAnalysePodRunningNotReady(t *testing.T) {
+	// Previously counted as healthy because the line contained "Running".
+	h, fs := analysePod(healthPod("p", "Running", false, containerStatus(false, 0, runningState, nil)), "ns")
+	if h.Ready != "0/1" || len(fs) != 1 || fs[0].ID != "pod-not-ready" {
+		t.Errorf("h = %+v, findings = %+v", h, fs)
+	}
+}
+
+func TestAnalysePodCrashLoopOOM(t *testing.T) {
+	waiting := map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}
+	last := map[string]interface{}{"terminated": map[string]interface{}{"reason": "OOMKilled", "exitCode": float64(137)}}
+	h, fs := analysePod(healthPod("p", "Running", false, containerStatus(false, 7, waiting, last)), "ns")
+	if h.Status != "CrashLoopBackOff" || h.Restarts != 7 || !strings.Contains(h.LastTermination, "OOMKilled (exit 137)") {
+		t.Errorf("h = %+v", h)
+	}
+	for _, id := range []string{"pod-crashloop", "pod-terminated"} {
+		if !hasFinding(fs, id) {
+			t.Errorf("missing %s in %+v", id, fs)
+		}
+	}
+	// The crash loop explains the restarts and the missing readiness.
+	for _, id := range []string{"pod-restarts", "pod-not-ready"} {
+		if hasFinding(fs, id) {
+			t.Errorf("unexpected %s", id)
+		}
+	}
+}
+
+func TestAnalysePodUnschedulable(t *testing.T) {
+	pod := healthPod("p", "Pending", false)
+	pod["status"].(map[string]interface{})["conditions"] = []interface{}{map[string]interface{}{
+		"type": "PodScheduled", "status": "False", "reason": "Unschedulable",
+		"message": "0/3 nodes are available: 3 Insufficient cpu.",
+	}}
+	_, fs := analysePod(pod, "ns")
+	if len(fs) != 1 || fs[0].ID != "pod-pending" || fs[0].Level != "FAIL" || !strings.Contains(fs[0].Message, "Insufficient cpu") {
+		t.Errorf("findings = %+v", fs)
+	}
+}
+
+// ─── evaluatePodHealth ───────────────────────────────────────────────────────
+
+func TestEvaluatePodHealthMixedImages(t *testing.T) {
+	old := healthPod("b-old", "Running", true, containerStatus(true, 0, runningState, nil))
+	old["spec"].(map[string]interface{})["containers"] = []interface{}{map[string]interface{}{"name": "controller", "image": "controller:v1.13.0"}}
+	done := healthPod("job", "Succeeded", false)
+	pods := []interface{}{
+		healthPod("a-new", "Running", true, containerStatus(true, 0, runningState, nil)),
+		old, done,
+	}
+	rows, fs := evaluatePodHealth(pods, "ns", "deployment", "ctrl")
+	if len(rows) != 2 || rows[0].Name != "a-new" {
+		t.Errorf("rows = %+v", rows)
+	}
+	if len(fs) != 1 || fs[0].ID != "pod-mixed-images" || !strings.Contains(fs[0].Remediation, "deployment/ctrl") {
+		t.Errorf("findings = %+v", fs)
+	}
+}
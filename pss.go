@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Pod Security Standards (offline evaluation)
+// ─────────────────────────────────────────────
+
+// Pod Security Standards levels, from least to most restrictive.
+const (
+	pssPrivileged = "privileged"
+	pssBaseline   = "baseline"
+	pssRestricted = "restricted"
+)
+
+// pssRank orders the levels for comparison.
+var pssRank = map[string]int{pssPrivileged: 0, pssBaseline: 1, pssRestricted: 2}
+
+// baselineCapabilities may be added under the baseline level.
+var baselineCapabilities = map[string]bool{
+	"AUDIT_WRITE": true, "CHOWN": true, "DAC_OVERRIDE": true, "FOWNER": true, "FSETID": true,
+	"KILL": true, "MKNOD": true, "NET_BIND_SERVICE": true, "SETFCAP": true, "SETGID": true,
+	"SETPCAP": true, "SETUID": true, "SYS_CHROOT": true,
+}
+
+// safeSysctls may be set under the baseline level.
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced": true, "net.ipv4.ip_local_port_range": true,
+	"net.ipv4.ip_unprivileged_port_start": true, "net.ipv4.tcp_syncookies": true,
+	"net.ipv4.ping_group_range": true, "net.ipv4.ip_local_reserved_ports": true,
+	"net.ipv4.tcp_keepalive_time": true, "net.ipv4.tcp_fin_timeout": true,
+	"net.ipv4.tcp_keepalive_intvl": true, "net.ipv4.tcp_keepalive_probes": true,
+}
+
+// restrictedVolumeTypes are the only volume sources allowed under restricted.
+var restrictedVolumeTypes = map[string]bool{
+	"configMap": true, "csi": true, "downwardAPI": true, "emptyDir": true, "ephemeral": true,
+	"persistentVolumeClaim": true, "projected": true, "secret": true,
+}
+
+// allowedSELinuxTypes may be set under the baseline level.
+var allowedSELinuxTypes = map[string]bool{
+	"": true, "container_t": true, "container_init_t": true, "container_kvm_t": true, "container_engine_t": true,
+}
+
+// PSSResult is the Pod Security Standards posture of the controller.
+type PSSResult struct {
+	Met                  string            `json:"met"` // strictest level the pod template satisfies
+	BaselineViolations   []string          `json:"baseline_violations"`
+	RestrictedViolations []string          `json:"restricted_violations"`
+	NamespaceLevels      map[string]string `json:"namespace_levels"` // mode (enforce/audit/warn) → level
+}
+
+// evaluatePSS checks a pod template against the baseline and restricted
+// Pod Security Standards. podAnnotations carries legacy AppArmor annotations.
+func evaluatePSS(podSpec map[string]interface{}, podAnnotations map[string]string) PSSResult {
+	var baseline, restricted []string
+	bad := func(list *[]string, format string, args ...interface{}) {
+		*list = append(*list, fmt.Sprintf(format, args...))
+	}
+	podSC := getMap(podSpec, "securityContext")
+
+	// ── Baseline: pod level ──
+	for _, f := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if podSpec[f] == true {
+			bad(&baseline, "spec.%s=true", f)
+		}
+	}
+	for _, v := range getSlice(podSpec, "volumes") {
+		vm, _ := v.(map[string]interface{})
+		for k := range vm {
+			if k == "name" {
+				continue
+			}
+			if k == "hostPath" {
+				bad(&baseline, "volume %s uses hostPath", getString(vm, "name"))
+			} else if !restrictedVolumeTypes[k] {
+				bad(&restricted, "volume %s uses %s", getString(vm, "name"), k)
+			}
+		}
+	}
+	for _, s := range getSlice(podSC, "sysctls") {
+		sm, _ := s.(map[string]interface{})
+		if name := getString(sm, "name"); !safeSysctls[name] {
+			bad(&baseline, "sysctl %s is not in the safe set", name)
+		}
+	}
+	for k, v := range podAnnotations {
+		if strings.HasPrefix(k, "container.apparmor.security.beta.kubernetes.io/") && v == "unconfined" {
+			bad(&baseline, "AppArmor annotation %s=unconfined", k)
+		}
+	}
+
+	// ── Per container ──
+	podNonRoot := podSC["runAsNonRoot"] == true
+	podSeccomp := getString(getMap(podSC, "seccompProfile"), "type")
+	checkCommon := func(where string, sc map[string]interface{}) {
+		if getMap(sc, "windowsOptions")["hostProcess"] == true {
+			bad(&baseline, "%s: windowsOptions.hostProcess=true", where)
+		}
+		if t := getString(getMap(sc, "seccompProfile"), "type"); t == "Unconfined" {
+			bad(&baseline, "%s: seccompProfile Unconfined", where)
+		}
+		if t := getString(getMap(sc, "appArmorProfile"), "type"); t == "Unconfined" {
+			bad(&baseline, "%s: appArmorProfile Unconfined", where)
+		}
+		se := getMap(sc, "seLinuxOptions")
+		if !allowedSELinuxTypes[getString(se, "type")] || getString(se, "user") != "" || getString(se, "role") != "" {
+			bad(&baseline, "%s: custom seLinuxOptions", where)
+		}
+		if uid, ok := toInt(sc["runAsUser"]); ok && uid == 0 {
+			bad(&restricted, "%s: runAsUser=0", where)
+		}
+	}
+	checkCommon("pod", podSC)
+
+	containers, _ := podContainers(podSpec)
+	for _, c := range containers {
+		name := getString(c, "name")
+		sc := getMap(c, "securityContext")
+		checkCommon("container "+name, sc)
+		if sc["privileged"] == true {
+			bad(&baseline, "container %s: privileged=true", name)
+		}
+		if pm := getString(sc, "procMount"); pm != "" && pm != "Default" {
+			bad(&baseline, "container %s: procMount=%s", name, pm)
+		}
+		for _, p := range getSlice(c, "ports") {
+			pm, _ := p.(map[string]interface{})
+			if hp, ok := toInt(pm["hostPort"]); ok && hp != 0 {
+				bad(&baseline, "container %s: hostPort %d", name, hp)
+			}
+		}
+		caps := getMap(sc, "capabilities")
+		dropAll := false
+		for _, d := range getSlice(caps, "drop") {
+			if strings.EqualFold(fmt.Sprintf("%v", d), "ALL") {
+				dropAll = true
+			}
+		}
+		for _, a := range getSlice(caps, "add") {
+			capName := strings.ToUpper(strings.TrimPrefix(fmt.Sprintf("%v", a), "CAP_"))
+			if !baselineCapabilities[capName] {
+				bad(&baseline, "container %s: adds capability %s", name, capName)
+			} else if capName != "NET_BIND_SERVICE" {
+				bad(&restricted, "container %s: adds capability %s", name, capName)
+			}
+		}
+
+		// ── Restricted only ──
+		if sc["allowPrivilegeEscalation"] != false {
+			bad(&restricted, "container %s: allowPrivilegeEscalation is not false", name)
+		}
+		if !dropAll {
+			bad(&restricted, "container %s: capabilities.drop does not include ALL", name)
+		}
+		if nr, set := sc["runAsNonRoot"]; nr != true && (set || !podNonRoot) {
+			bad(&restricted, "container %s: runAsNonRoot is not true", name)
+		}
+		seccomp := getString(getMap(sc, "seccompProfile"), "type")
+		if seccomp == "" {
+			seccomp = podSeccomp
+		}
+		if seccomp != "RuntimeDefault" && seccomp != "Localhost" {
+			bad(&restricted, "container %s: seccompProfile is not RuntimeDefault or Localhost", name)
+		}
+	}
+
+	res := PSSResult{Met: pssRestricted, BaselineViolations: baseline, RestrictedViolations: restricted}
+	switch {
+	case len(baseline) > 0:
+		res.Met = pssPrivileged
+	case len(restricted) > 0:
+		res.Met = pssBaseline
+	}
+	return res
+}
+
+// namespacePSSLevels extracts the enforce/audit/warn levels from namespace
+// labels. Unset modes are omitted.
+func namespacePSSLevels(labels map[string]string) map[string]string {
+	levels := map[string]string{}
+	for _, mode := range []string{"enforce", "audit", "warn"} {
+		if v := labels["pod-security.kubernetes.io/"+mode]; v != "" {
+			levels[mode] = v
+		}
+	}
+	return levels
+}
+
+// pssDryRunViolations extracts the Pod Security warnings from the stderr of
+// a server-side dry-run of an enforce label. The API server accepts the
+// label either way, so the warnings are the only sign that existing pods in
+// the namespace would violate the level.
+func pssDryRunViolations(stderr string) []string {
+	var warnings []string
+	violated := false
+	for _, line := range strings.Split(stderr, "\n") {
+		w, ok := strings.CutPrefix(strings.TrimSpace(line), "Warning: ")
+		if !ok {
+			continue
+		}
+		warnings = append(warnings, w)
+		violated = violated || strings.Contains(w, "violate the new PodSecurity enforce level")
+	}
+	if !violated {
+		return nil
+	}
+	return warnings
+}
+
+// evaluatePSSNamespace compares the namespace's Pod Security labels with the
+// level the controller meets.
+func evaluatePSSNamespace(r PSSResult, ns string) []Finding {
+	var findings []Finding
+	res := "namespace/" + ns
+	labelFix := fmt.Sprintf("kubectl label ns %s pod-security.kubernetes.io/enforce=%s --overwrite", ns, r.Met)
+	enforce := r.NamespaceLevels["enforce"]
+	switch {
+	case enforce == "" || enforce == pssPrivileged:
+		findings = append(findings, Finding{ID: "pss-enforce-missing", Level: "WARN", Resource: res,
+			Message:     fmt.Sprintf("Namespace %s enforces no Pod Security Standard", ns),
+			Rationale:   "Without an enforce label any pod, including privileged ones, can be created next to the controller.",
+			Remediation: labelFix})
+	case pssRank[enforce] > pssRank[r.Met]:
+		findings = append(findings, Finding{ID: "pss-enforce-violation", Level: "FAIL", Resource: res,
+			Message:     fmt.Sprintf("Namespace enforces %s but the controller only meets %s — new pods are rejected on the next rollout", enforce, r.Met),
+			Rationale:   "Pod Security admission checks pods at creation, so existing pods keep running until a rollout, eviction or scale-up.",
+			Remediation: "Fix the violations listed above, or lower the enforce label"})
+	case pssRank[enforce] < pssRank[r.Met]:
+		findings = append(findings, Finding{ID: "pss-enforce-below-met", Level: "INFO", Resource: res,
+			Message:     fmt.Sprintf("Namespace enforces %s but the controller already meets %s", enforce, r.Met),
+			Remediation: labelFix})
+	}
+	for _, mode := range []string{"audit", "warn"} {
+		if lvl := r.NamespaceLevels[mode]; lvl != "" && pssRank[lvl] > pssRank[r.Met] {
+			findings = append(findings, Finding{ID: "pss-" + mode + "-violation", Level: "INFO", Resource: res,
+				Message: fmt.Sprintf("Namespace %s level is %s; the controller pod template violates it", mode, lvl)})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func restrictedPodSpec() map[string]interface{} {
+	return map[string]interface{}{
+		"securityContext": map[string]interface{}{
+			"runAsNonRoot":   true,
+			"seccompProfile": map[string]interface{}{"type": "RuntimeDefault"},
+		},
+		"volumes": []interface{}{
+			map[string]interface{}{"name": "webhook-cert", "secret": map[string]interface{}{"secretName": "x"}},
+		},
+		"containers": []interface{}{map[string]interface{}{
+			"name":  "controller",
+			"ports": []interface{}{map[string]interface{}{"containerPort": float64(80)}},
+			"securityContext": map[string]interface{}{
+				"allowPrivilegeEscalation": false,
+				"runAsUser":                float64(101),
+				"capabilities": map[string]interface{}{
+					"drop": []interface{}{"ALL"},
+					"add":  []interface{}{"NET_BIND_SERVICE"},
+				},
+			},
+		}},
+	}
+}
+
+func controllerSC(spec map[string]interface{}) map[string]interface{} {
+	return spec["containers"].([]interface{})[0].(map[string]interface{})["securityContext"].(map[string]interface{})
+}
+
+// ─── evaluatePSS ─────────────────────────────────────────────────────────────
+
+func TestEvaluatePSSRestricted(t *testing.T) {
+	r := evaluatePSS(restrictedPodSpec(), nil)
+	if r.Met != pssRestricted {
+		t.Errorf("met = %s, violations: %v %v", r.Met, r.BaselineViolations, r.RestrictedViolations)
+	}
+}
+
+func TestEvaluatePSSBaseline(t *testing.T) {
+	spec := restrictedPodSpec()
+	delete(controllerSC(spec), "allowPrivilegeEscalation")
+	delete(spec["securityContext"].(map[string]interface{}), "seccompProfile")
+	r := evaluatePSS(spec, nil)
+	if r.Met != pssBaseline || len(r.RestrictedViolations) != 2 {
+		t.Errorf("met = %s, restricted violations = %v", r.Met, r.RestrictedViolations)
+	}
+}
+
+func TestEvaluatePSSPrivileged(t *testing.T) {
+	spec := restrictedPodSpec()
+	spec["hostNetwork"] = true
+	spec["volumes"] = append(spec["volumes"].([]interface{}),
+		map[string]interface{}{"name": "modules", "hostPath": map[string]interface{}{"path": "/lib/modules"}})
+	controllerSC(spec)["capabilities"].(map[string]interface{})["add"] = []interface{}{"SYS_ADMIN"}
+	r := evaluatePSS(spec, map[string]string{"container.apparmor.security.beta.kubernetes.io/controller": "unconfined"})
+	if r.Met != pssPrivileged {
+		t.Fatalf("met = %s", r.Met)
+	}
+	joined := strings.Join(r.BaselineViolations, "; ")
+	for _, want := range []string{"hostNetwork", "hostPath", "SYS_ADMIN", "AppArmor"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("baseline violations %q missing %s", joined, want)
+		}
+	}
+}
+
+func TestEvaluatePSSInitContainers(t *testing.T) {
+	spec := restrictedPodSpec()
+	spec["initContainers"] = []interface{}{map[string]interface{}{
+		"name":            "init",
+		"securityContext": map[string]interface{}{"privileged": true},
+	}}
+	if r := evaluatePSS(spec, nil); r.Met != pssPrivileged {
+		t.Errorf("privileged init container: met = %s", r.Met)
+	}
+}
+
+// ─── evaluatePSSNamespace ────────────────────────────────────────────────────
+
+func TestEvaluatePSSNamespace(t *testing.T) {
+	cases := []struct {
+		met    string
+		labels map[string]string
+		want   string
+	}{
+		{pssRestricted, map[string]string{}, "pss-enforce-missing"},
+		{pssRestricted, map[string]string{"pod-security.kubernetes.io/enforce": "privileged"}, "pss-enforce-missing"},
+		{pssBaseline, map[string]string{"pod-security.kubernetes.io/enforce": "restricted"}, "pss-enforce-violation"},
+		{pssRestricted, map[string]string{"pod-security.kubernetes.io/enforce": "baseline"}, "pss-enforce-below-met"},
+		{pssBaseline, map[string]string{"pod-security.kubernetes.io/enforce": "baseline",
+			"pod-security.kubernetes.io/warn": "restricted"}, "pss-warn-violation"},
+	}
+	for _, c := range cases {
+		r := PSSResult{Met: c.met, NamespaceLevels: namespacePSSLevels(c.labels)}
+		if fs := evaluatePSSNamespace(r, "ingress-nginx"); !hasFinding(fs, c.want) {
+			t.Errorf("met=%s labels=%v: missing %s in %+v", c.met, c.labels, c.want, fs)
+		}
+	}
+	r := PSSResult{Met: pssRestricted, NamespaceLevels: map[string]string{"enforce": "restricted"}}
+	if fs := evaluatePSSNamespace(r, "ingress-nginx"); len(fs) != 0 {
+		t.Errorf("matching enforce level: %+v", fs)
+	}
+}
+
+func TestPSSDryRunViolations(t *testing.T) {
+	stderr := `Warning: existing pods in namespace "ingress-nginx" violate the new PodSecurity enforce level "restricted:latest"
+Warning: debug-shell: allowPrivilegeEscalation != false, runAsNonRoot != true
+namespace/ingress-nginx labeled (server dry run)`
+	v := pssDryRunViolations(stderr)
+	if len(v) != 2 || !strings.HasPrefix(v[1], "debug-shell:") {
+		t.Errorf("violations = %q", v)
+	}
+	if v := pssDryRunViolations("Warning: unrelated deprecation\n"); v != nil {
+		t.Errorf("unrelated warning reported as violation: %q", v)
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Controller ServiceAccount RBAC analysis
+// ─────────────────────────────────────────────
+
+// rbacGrant is one PolicyRule granted to the controller ServiceAccount
+// through a binding.
+type rbacGrant struct {
+	Binding   string // "ClusterRoleBinding/name" or "RoleBinding/ns/name"
+	Role      string // "ClusterRole/name" or "Role/ns/name"
+	Namespace string // "" for cluster-wide grants
+	Rule      map[string]interface{}
+}
+
+// minimalControllerRules is the permission set ingress-nginx needs, keyed by
+// "apiGroup/resource". Leases are only needed in the controller namespace.
+var minimalControllerRules = map[string][]string{
+	"/configmaps":                        {"get", "list", "watch"},
+	"/endpoints":                         {"get", "list", "watch"},
+	"/nodes":                             {"get", "list", "watch"},
+	"/pods":                              {"get", "list", "watch"},
+	"/secrets":                           {"get", "list", "watch"},
+	"/namespaces":                        {"get", "list", "watch"},
+	"/services":                          {"get", "list", "watch"},
+	"/events":                            {"create", "patch"},
+	"events.k8s.io/events":               {"create", "patch"},
+	"networking.k8s.io/ingresses":        {"get", "list", "watch"},
+	"networking.k8s.io/ingresses/status": {"update"},
+	"networking.k8s.io/ingressclasses":   {"get", "list", "watch"},
+	"discovery.k8s.io/endpointslices":    {"get", "list", "watch"},
+	"coordination.k8s.io/leases":         {"get", "list", "watch", "create", "update"},
+}
+
+// minimalRulesFor returns minimalControllerRules for a controller version.
+// Before v1.9 the controller took its leader-election lock on a ConfigMap,
+// so the chart also grants configmaps create and update.
+func minimalRulesFor(version string) map[string][]string {
+	if versionAtLeast(version, "v1.9.0") {
+		return minimalControllerRules
+	}
+	rules := map[string][]string{}
+	for k, v := range minimalControllerRules {
+		rules[k] = v
+	}
+	rules["/configmaps"] = []string{"get", "list", "watch", "create", "update"}
+	return rules
+}
+
+// dangerousVerbs grant privilege escalation regardless of the resource.
+var dangerousVerbs = map[string]bool{"escalate": true, "bind": true, "impersonate": true}
+
+// subjectMatches reports whether a binding subject covers the ServiceAccount
+// sa in namespace ns, directly or through its groups.
+func subjectMatches(subject map[string]interface{}, sa, ns, bindingNS string) bool {
+	name := getString(subject, "name")
+	switch getString(subject, "kind") {
+	case "ServiceAccount":
+		subjNS := getString(subject, "namespace")
+		if subjNS == "" {
+			subjNS = bindingNS
+		}
+		return name == sa && subjNS == ns
+	case "Group":
+		return name == "system:serviceaccounts" || name == "system:serviceaccounts:"+ns ||
+			name == "system:authenticated"
+	}
+	return false
+}
+
+// resolveRBACGrants returns the rules granted to ServiceAccount sa in
+// namespace ns by the given bindings. Roles are looked up by
+// "ClusterRole/name" and "Role/ns/name".
+func resolveRBACGrants(sa, ns string, clusterRoleBindings, roleBindings, clusterRoles, roles []interface{}) []rbacGrant {
+	ruleSets := map[string][]interface{}{}
+	for _, list := range []struct {
+		kind  string
+		items []interface{}
+	}{{"ClusterRole", clusterRoles}, {"Role", roles}} {
+		for _, r := range list.items {
+			rm, _ := r.(map[string]interface{})
+			meta := getMap(rm, "metadata")
+			key := list.kind + "/" + getString(meta, "name")
+			if list.kind == "Role" {
+				key = "Role/" + getString(meta, "namespace") + "/" + getString(meta, "name")
+			}
+			ruleSets[key] = getSlice(rm, "rules")
+		}
+	}
+
+	var grants []rbacGrant
+	collect := func(kind string, items []interface{}) {
+		for _, b := range items {
+			bm, _ := b.(map[string]interface{})
+			meta := getMap(bm, "metadata")
+			bindingNS := getString(meta, "namespace")
+			matched := false
+			for _, s := range getSlice(bm, "subjects") {
+				sm, _ := s.(map[string]interface{})
+				if subjectMatches(sm, sa, ns, bindingNS) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+			binding := kind + "/" + getString(meta, "name")
+			if bindingNS != "" {
+				binding = kind + "/" + bindingNS + "/" + getString(meta, "name")
+			}
+			ref := getMap(bm, "roleRef")
+			role := getString(ref, "kind") + "/" + getString(ref, "name")
+			if getString(ref, "kind") == "Role" {
+				role = "Role/" + bindingNS + "/" + getString(ref, "name")
+			}
+			for _, rule := range ruleSets[role] {
+				rm, _ := rule.(map[string]interface{})
+				grants = append(grants, rbacGrant{Binding: binding, Role: role, Namespace: bindingNS, Rule: rm})
+			}
+		}
+	}
+	collect("ClusterRoleBinding", clusterRoleBindings)
+	collect("RoleBinding", roleBindings)
+	return grants
+}
+
+// ruleStrings returns a rule field as strings.
+func ruleStrings(rule map[string]interface{}, key string) []string {
+	var out []string
+	for _, v := range getSlice(rule, key) {
+		out = append(out, fmt.Sprintf("%v", v))
+	}
+	return out
+}
+
+// watchScope returns the namespaces the controller is limited to by
+// --watch-namespace, or nil when it watches the whole cluster.
+func watchScope(args map[string]string) []string {
+	if args["watch-namespace-selector"] != "" {
+		return nil
+	}
+	return nonEmpty(strings.Split(args["watch-namespace"], ","))
+}
+
+// evaluateRBAC compares the grants of the controller ServiceAccount with
+// the minimum rules for the controller version and the watch scope.
+func evaluateRBAC(grants []rbacGrant, scope []string, version, sa string) []Finding {
+	var findings []Finding
+	res := "serviceaccount/" + sa
+	add := func(id, level, msg, rationale, fix string) {
+		findings = append(findings, Finding{ID: id, Level: level, Resource: res,
+			Message: msg, Rationale: rationale, Remediation: fix})
+	}
+	excess := map[string]map[string]bool{} // "group/resource" → verbs
+	excessFrom := map[string]map[string]bool{}
+	clusterSecrets := map[string]bool{}
+	minimal := minimalRulesFor(version)
+
+	for _, g := range grants {
+		if len(ruleStrings(g.Rule, "nonResourceURLs")) > 0 {
+			continue
+		}
+		groups, resources, verbs := ruleStrings(g.Rule, "apiGroups"), ruleStrings(g.Rule, "resources"), ruleStrings(g.Rule, "verbs")
+		where := g.Role + " via " + g.Binding
+		if contains(groups, "*") || contains(resources, "*") || contains(verbs, "*") {
+			add("rbac-wildcard", "FAIL",
+				fmt.Sprintf("%s grants a wildcard rule (apiGroups=%v resources=%v verbs=%v)", where, groups, resources, verbs),
+				"Wildcards grant every current and future permission, including secrets and RBAC itself.",
+				"Replace the wildcard with the explicit ingress-nginx rules")
+			continue
+		}
+		for _, v := range verbs {
+			if dangerousVerbs[v] {
+				add("rbac-escalation", "FAIL", fmt.Sprintf("%s grants %q", where, v),
+					"escalate, bind and impersonate let the holder gain permissions it was not granted.",
+					"Remove the verb from the role")
+			}
+		}
+		for _, grp := range groups {
+			for _, r := range resources {
+				key := grp + "/" + r
+				allowed := minimal[key]
+				for _, v := range verbs {
+					if r == "secrets" && g.Namespace == "" && (v == "get" || v == "list" || v == "watch") {
+						clusterSecrets[where] = true
+					}
+					if contains(allowed, v) {
+						continue
+					}
+					if excess[key] == nil {
+						excess[key], excessFrom[key] = map[string]bool{}, map[string]bool{}
+					}
+					excess[key][v] = true
+					excessFrom[key][where] = true
+				}
+			}
+		}
+	}
+
+	var keys []string
+	for k := range excess {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		level := "WARN"
+		if strings.HasSuffix(k, "/secrets") || strings.HasSuffix(k, "/pods/exec") ||
+			strings.Contains(k, "rbac.authorization.k8s.io/") {
+			level = "FAIL"
+		}
+		resource := strings.TrimPrefix(k, "/")
+		add("rbac-excess-permission", level,
+			fmt.Sprintf("%s: %s beyond the ingress-nginx minimum (from %s)",
+				resource, strings.Join(sortedKeys(excess[k]), ","), strings.Join(sortedKeys(excessFrom[k]), "; ")),
+			"", "Remove the extra verbs/resources from the role")
+	}
+
+	if len(clusterSecrets) > 0 {
+		from := strings.Join(sortedKeys(clusterSecrets), "; ")
+		if len(scope) > 0 {
+			add("rbac-cluster-secrets", "WARN",
+				fmt.Sprintf("Cluster-wide read access to Secrets (%s) although the controller only watches %s", from, strings.Join(scope, ",")),
+				"A controller compromise (e.g. CVE-2025-1974, IngressNightmare) exposes every Secret in the cluster instead of the watched namespaces.",
+				"Set controller.scope.enabled=true in Helm values so the chart grants a namespaced Role")
+		} else {
+			add("rbac-cluster-secrets", "INFO",
+				fmt.Sprintf("Controller can read every Secret in the cluster (%s)", from),
+				"A controller compromise (e.g. CVE-2025-1974, IngressNightmare) exposes every Secret in the cluster.",
+				"Scope the controller with --watch-namespace (controller.scope.enabled) if it serves only some namespaces")
+		}
+	}
+	return findings
+}
+
+// evaluateSharedServiceAccount flags pods other than the controller that run
+// with the controller ServiceAccount and get its token mounted.
+// controllerSelector is the controller workload's label selector.
+func evaluateSharedServiceAccount(pods []interface{}, sa string, saAutomount bool, controllerSelector map[string]interface{}, ns string) []Finding {
+	var findings []Finding
+	for _, p := range pods {
+		pm, _ := p.(map[string]interface{})
+		spec := getMap(pm, "spec")
+		meta := getMap(pm, "metadata")
+		podSA := getString(spec, "serviceAccountName")
+		if podSA == "" {
+			podSA = "default"
+		}
+		if podSA != sa || labelSelectorMatches(controllerSelector, getStringMap(meta, "labels")) {
+			continue
+		}
+		automount := saAutomount
+		if v, ok := spec["automountServiceAccountToken"].(bool); ok {
+			automount = v
+		}
+		if automount {
+			findings = append(findings, Finding{ID: "rbac-sa-shared", Level: "FAIL",
+				Resource:    fmt.Sprintf("pod/%s/%s", ns, getString(meta, "name")),
+				Message:     fmt.Sprintf("Pod %s runs with the controller ServiceAccount %s and mounts its token", getString(meta, "name"), sa),
+				Rationale:   "The pod inherits the controller's cluster-wide Secret access.",
+				Remediation: "Give the pod its own ServiceAccount or set automountServiceAccountToken: false"})
+		}
+	}
+	return findings
+}
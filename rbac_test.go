@@ -0,0 +1,188 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func rule(groups, resources, verbs []interface{}) map[string]interface{} {
+	return map[string]interface{}{"apiGroups": groups, "resources": resources, "verbs": verbs}
+}
+
+func chartClusterRole() map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "ingress-nginx"},
+		"rules": []interface{}{
+			rule([]interface{}{""}, []interface{}{"configmaps", "endpoints", "nodes", "pods", "secrets", "namespaces"}, []interface{}{"list", "watch"}),
+			rule([]interface{}{""}, []interface{}{"services"}, []interface{}{"get", "list", "watch"}),
+			rule([]interface{}{"networking.k8s.io"}, []interface{}{"ingresses", "ingressclasses"}, []interface{}{"get", "list", "watch"}),
+			rule([]interface{}{"networking.k8s.io"}, []interface{}{"ingresses/status"}, []interface{}{"update"}),
+			rule([]interface{}{""}, []interface{}{"events"}, []interface{}{"create", "patch"}),
+		},
+	}
+}
+
+func binding(kind, name, ns, roleKind, roleName string, subjects ...interface{}) map[string]interface{} {
+	meta := map[string]interface{}{"name": name}
+	if ns != "" {
+		meta["namespace"] = ns
+	}
+	return map[string]interface{}{
+		"kind":     kind,
+		"metadata": meta,
+		"roleRef":  map[string]interface{}{"kind": roleKind, "name": roleName},
+		"subjects": subjects,
+	}
+}
+
+func saSubject(name, ns string) map[string]interface{} {
+	return map[string]interface{}{"kind": "ServiceAccount", "name": name, "namespace": ns}
+}
+
+// ─── resolveRBACGrants ───────────────────────────────────────────────────────
+
+func TestResolveRBACGrants(t *testing.T) {
+	crbs := []interface{}{
+		binding("ClusterRoleBinding", "ingress-nginx", "", "ClusterRole", "ingress-nginx", saSubject("ingress-nginx", "ingress-nginx")),
+		binding("ClusterRoleBinding", "other", "", "ClusterRole", "ingress-nginx", saSubject("other", "ingress-nginx")),
+	}
+	rbs := []interface{}{
+		binding("RoleBinding", "ingress-nginx", "ingress-nginx", "Role", "ingress-nginx",
+			map[string]interface{}{"kind": "ServiceAccount", "name": "ingress-nginx"}), // namespace defaults to the binding's
+	}
+	roles := []interface{}{map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "ingress-nginx", "namespace": "ingress-nginx"},
+		"rules":    []interface{}{rule([]interface{}{"coordination.k8s.io"}, []interface{}{"leases"}, []interface{}{"get", "update"})},
+	}}
+	grants := resolveRBACGrants("ingress-nginx", "ingress-nginx", crbs, rbs, []interface{}{chartClusterRole()}, roles)
+	if len(grants) != 6 {
+		t.Fatalf("got %d grants, want 6", len(grants))
+	}
+	last := grants[5]
+	if last.Role != "Role/ingress-nginx/ingress-nginx" || last.Namespace != "ingress-nginx" {
+		t.Errorf("role grant = %+v", last)
+	}
+}
+
+func TestSubjectMatchesGroups(t *testing.T) {
+	for _, g := range []string{"system:serviceaccounts", "system:serviceaccounts:ingress-nginx", "system:authenticated"} {
+		if !subjectMatches(map[string]interface{}{"kind": "Group", "name": g}, "sa", "ingress-nginx", "") {
+			t.Errorf("group %s should match", g)
+		}
+	}
+	if subjectMatches(map[string]interface{}{"kind": "Group", "name": "system:serviceaccounts:kube-system"}, "sa", "ingress-nginx", "") {
+		t.Error("other namespace group should not match")
+	}
+}
+
+// ─── evaluateRBAC ────────────────────────────────────────────────────────────
+
+func grantsFrom(ns string, rules ...map[string]interface{}) []rbacGrant {
+	var out []rbacGrant
+	for _, r := range rules {
+		out = append(out, rbacGrant{Binding: "ClusterRoleBinding/x", Role: "ClusterRole/x", Namespace: ns, Rule: r})
+	}
+	return out
+}
+
+func TestEvaluateRBACChartDefault(t *testing.T) {
+	var rules []map[string]interface{}
+	for _, r := range getSlice(chartClusterRole(), "rules") {
+		rules = append(rules, r.(map[string]interface{}))
+	}
+	fs := evaluateRBAC(grantsFrom("", rules...), nil, "v1.12.1", "sa")
+	if len(fs) != 1 || fs[0].ID != "rbac-cluster-secrets" || fs[0].Level != "INFO" {
+		t.Errorf("findings = %+v", fs)
+	}
+}
+
+func TestEvaluateRBACLegacyLeaderElection(t *testing.T) {
+	g := grantsFrom("ingress-nginx", rule([]interface{}{""}, []interface{}{"configmaps"}, []interface{}{"get", "create", "update"}))
+	if fs := evaluateRBAC(g, nil, "v1.8.4", "sa"); len(fs) != 0 {
+		t.Errorf("pre-v1.9 configmap leader election: %+v", fs)
+	}
+	if fs := evaluateRBAC(g, nil, "v1.9.0", "sa"); !hasFinding(fs, "rbac-excess-permission") {
+		t.Errorf("v1.9+ uses leases only: %+v", fs)
+	}
+}
+
+func TestEvaluateRBACClusterSecretsWithScope(t *testing.T) {
+	g := grantsFrom("", rule([]interface{}{""}, []interface{}{"secrets"}, []interface{}{"list", "watch"}))
+	fs := evaluateRBAC(g, []string{"team-a"}, "v1.12.1", "sa")
+	if len(fs) != 1 || fs[0].Level != "WARN" || !strings.Contains(fs[0].Message, "team-a") {
+		t.Errorf("findings = %+v", fs)
+	}
+	// The same rule bound in the watched namespace is fine.
+	if fs := evaluateRBAC(grantsFrom("team-a", g[0].Rule), []string{"team-a"}, "v1.12.1", "sa"); len(fs) != 0 {
+		t.Errorf("namespaced grant: %+v", fs)
+	}
+}
+
+func TestEvaluateRBACWildcardAndExcess(t *testing.T) {
+	g := grantsFrom("",
+		rule([]interface{}{"*"}, []interface{}{"*"}, []interface{}{"*"}),
+		rule([]interface{}{""}, []interface{}{"secrets"}, []interface{}{"get", "create", "delete"}),
+		rule([]interface{}{"apps"}, []interface{}{"deployments"}, []interface{}{"get"}),
+		rule([]interface{}{"rbac.authorization.k8s.io"}, []interface{}{"clusterroles"}, []interface{}{"bind"}),
+	)
+	fs := evaluateRBAC(g, nil, "v1.12.1", "sa")
+	for _, id := range []string{"rbac-wildcard", "rbac-escalation", "rbac-excess-permission", "rbac-cluster-secrets"} {
+		if !hasFinding(fs, id) {
+			t.Errorf("missing %s in %+v", id, fs)
+		}
+	}
+	for _, f := range fs {
+		if f.ID != "rbac-excess-permission" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(f.Message, "secrets:"):
+			if f.Level != "FAIL" || !strings.Contains(f.Message, "create,delete") {
+				t.Errorf("secrets excess = %+v", f)
+			}
+		case strings.HasPrefix(f.Message, "apps/deployments:"):
+			if f.Level != "WARN" {
+				t.Errorf("deployments excess = %+v", f)
+			}
+		}
+	}
+}
+
+func TestWatchScope(t *testing.T) {
+	if s := watchScope(map[string]string{"watch-namespace": "a,b"}); len(s) != 2 {
+		t.Errorf("scope = %v", s)
+	}
+	if s := watchScope(map[string]string{"watch-namespace": "a", "watch-namespace-selector": "team=a"}); s != nil {
+		t.Errorf("selector scope = %v", s)
+	}
+}
+
+// ─── evaluateSharedServiceAccount ────────────────────────────────────────────
+
+func TestEvaluateSharedServiceAccount(t *testing.T) {
+	pod := func(name, sa string, labels map[string]interface{}, automount interface{}) interface{} {
+		spec := map[string]interface{}{"serviceAccountName": sa}
+		if automount != nil {
+			spec["automountServiceAccountToken"] = automount
+		}
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "labels": labels},
+			"spec":     spec,
+		}
+	}
+	ctrl := map[string]interface{}{"app.kubernetes.io/component": "controller"}
+	selector := map[string]interface{}{"matchLabels": ctrl}
+	pods := []interface{}{
+		pod("controller-abc", "ingress-nginx", ctrl, nil),
+		pod("debug", "ingress-nginx", nil, nil),
+		pod("job", "ingress-nginx", nil, false),
+		pod("other", "default", nil, nil),
+	}
+	fs := evaluateSharedServiceAccount(pods, "ingress-nginx", true, selector, "ingress-nginx")
+	if len(fs) != 1 || fs[0].Resource != "pod/ingress-nginx/debug" {
+		t.Errorf("findings = %+v", fs)
+	}
+	if fs := evaluateSharedServiceAccount(pods, "ingress-nginx", false, selector, "ingress-nginx"); len(fs) != 0 {
+		t.Errorf("automount disabled on SA: %+v", fs)
+	}
+}
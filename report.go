@@ -24,6 +24,14 @@ type AuditReport struct {
 	Controller      ControllerReport   `json:"controller"`
 	Admission       AdmissionReport    `json:"admission_controller"`
 	Security        SecurityReport     `json:"security"`
+	NetworkPolicy   NetPolEvaluation   `json:"network_policy"`
+	ExternalSvcs    []ExternalService  `json:"external_services"`
+	TLSProfile      TLSProfileResult   `json:"tls_profile"`
+	PodSecurity     PSSResult          `json:"pod_security_standards"`
+	Pods            []PodHealth        `json:"pods"`
+	ImageScan       *ImageScanResult   `json:"image_scan,omitempty"`
+	Certificates    []CertInfo         `json:"certificates"`
+	Findings        []Finding          `json:"findings"`
 	AuditResults    AuditResultsReport `json:"audit_results"`
 	Recommendations []string           `json:"recommendations"`
 }
@@ -46,9 +54,10 @@ type AdmissionReport struct {
 
 // SecurityReport holds aggregated security findings.
 type SecurityReport struct {
-	AbuseBSICompliant         bool `json:"abusebsi_compliant"`
-	SnippetAnnotationsEnabled bool `json:"snippet_annotations_enabled"`
-	NetworkPoliciesCount      int  `json:"network_policies_count"`
+	AbuseBSICompliant         bool         `json:"abusebsi_compliant"`
+	SnippetAnnotationsEnabled bool         `json:"snippet_annotations_enabled"`
+	NetworkPoliciesCount      int          `json:"network_policies_count"`
+	HostExposure              HostExposure `json:"host_exposure"`
 }
 
 // AuditResultsReport summarises pass/fail/warn/info counters.
@@ -86,10 +95,19 @@ func (a *AuditState) generateJSONReport() {
 			PubliclyExposed: a.AdmissionSvcType != "ClusterIP",
 		},
 		Security: SecurityReport{
-			AbuseBSICompliant:         a.AdmissionSvcType == "ClusterIP",
+			AbuseBSICompliant:         a.abuseBSICompliant(),
 			SnippetAnnotationsEnabled: a.AllowSnippets == "true",
 			NetworkPoliciesCount:      a.NpCount,
+			HostExposure:              a.HostExposure,
 		},
+		NetworkPolicy: a.NetPol,
+		ExternalSvcs:  a.ExternalServices,
+		TLSProfile:    a.TLSCompliance,
+		PodSecurity:   a.PSS,
+		Pods:          a.PodHealth,
+		ImageScan:     a.ImageScan,
+		Certificates:  a.Certificates,
+		Findings:      a.Findings,
 		AuditResults: AuditResultsReport{
 			Passed:   a.PassCount,
 			Failed:   a.FailCount,
@@ -112,6 +130,9 @@ func buildRecommendations(a *AuditState) []string {
 	if a.AdmissionSvcType != "ClusterIP" {
 		recs = append(recs, "Change admission controller service to ClusterIP")
 	}
+	if a.HostExposure.WebhookOnHost {
+		recs = append(recs, "Stop binding the admission webhook port on the node network (hostNetwork/hostPort)")
+	}
 	recs = append(recs, "Plan migration from Ingress-NGINX (retiring March 2026)")
 	recs = append(recs, "Consider migrating to Gateway API or alternative controller")
 	return recs
@@ -170,7 +191,7 @@ func (a *AuditState) generateSummary() {
 	a.writeln(fmt.Sprintf("    • Controller version:          %s", a.ControllerVersion))
 	a.writeln(fmt.Sprintf("    • Admission controller:        %s", a.AdmissionSvcType))
 	compliant := fmt.Sprintf("%s✓ COMPLIANT%s", Green, Reset)
-	if a.AdmissionSvcType != "ClusterIP" {
+	if !a.abuseBSICompliant() {
 		compliant = fmt.Sprintf("%s✗ NON-COMPLIANT%s", Red, Reset)
 	}
 	a.writeln(fmt.Sprintf("    • AbuseBSI compliance:         %s", compliant))
@@ -184,10 +205,14 @@ func (a *AuditState) generateSummary() {
 	a.writeln("")
 	a.writeln(fmt.Sprintf("  %sAbuseBSI Report Response:%s", Bold, Reset))
 	a.writeln("    Report ID: CB-Report#20260218-10009947")
-	if a.AdmissionSvcType == "ClusterIP" {
+	switch {
+	case a.abuseBSICompliant():
 		a.writeln(fmt.Sprintf("    Status:    %s✓ RESOLVED%s", Green, Reset))
 		a.writeln("    Details:   Admission controller is not publicly exposed")
-	} else {
+	case a.AdmissionSvcType == "ClusterIP":
+		a.writeln(fmt.Sprintf("    Status:    %s✗ STILL VULNERABLE%s", Red, Reset))
+		a.writeln("    Details:   Webhook reachable through an Ingress or node IPs — immediate remediation required")
+	default:
 		a.writeln(fmt.Sprintf("    Status:    %s✗ STILL VULNERABLE%s", Red, Reset))
 		a.writeln(fmt.Sprintf("    Details:   Exposed via %s — immediate remediation required", a.AdmissionSvcType))
 	}
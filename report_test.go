@@ -111,3 +111,23 @@ func TestAdmissionReport_publiclyExposedLogic(t *testing.T) {
 		}
 	}
 }
+
+func TestAbuseBSICompliant_webhookOnHost(t *testing.T) {
+	a := &AuditState{AdmissionSvcType: "ClusterIP"}
+	if !a.abuseBSICompliant() {
+		t.Error("ClusterIP without other exposure should be compliant")
+	}
+	a.HostExposure.WebhookOnHost = true
+	if a.abuseBSICompliant() {
+		t.Error("webhook bound on the host network must not be compliant")
+	}
+	found := false
+	for _, r := range buildRecommendations(a) {
+		if strings.Contains(r, "node network") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("should recommend removing the host-network webhook binding")
+	}
+}
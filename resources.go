@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Usage-based resource recommendations
+// ─────────────────────────────────────────────
+
+// Floors for recommended values; a controller below these starves on reload.
+const (
+	minCPURequestMilli = 100
+	minMemRequestBytes = 128 << 20
+	minMemLimitBytes   = 256 << 20
+	memRoundBytes      = 32 << 20
+)
+
+// parseCPU converts a Kubernetes CPU quantity ("250m", "1", "0.5", "1500000n")
+// to millicores.
+func parseCPU(q string) (int64, bool) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return 0, false
+	}
+	scale := 1000.0
+	switch {
+	case strings.HasSuffix(q, "n"):
+		scale, q = 1e-6, strings.TrimSuffix(q, "n")
+	case strings.HasSuffix(q, "u"):
+		scale, q = 1e-3, strings.TrimSuffix(q, "u")
+	case strings.HasSuffix(q, "m"):
+		scale, q = 1, strings.TrimSuffix(q, "m")
+	}
+	f, err := strconv.ParseFloat(q, 64)
+	if err != nil || f < 0 {
+		return 0, false
+	}
+	return int64(math.Ceil(f * scale)), true
+}
+
+// memorySuffixes maps Kubernetes memory quantity suffixes to multipliers.
+var memorySuffixes = []struct {
+	suffix string
+	mult   float64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+}
+
+// parseMemory converts a Kubernetes memory quantity ("256Mi", "1G", "134217728")
+// to bytes.
+func parseMemory(q string) (int64, bool) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return 0, false
+	}
+	mult := 1.0
+	for _, s := range memorySuffixes {
+		if strings.HasSuffix(q, s.suffix) {
+			mult, q = s.mult, strings.TrimSuffix(q, s.suffix)
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(q, 64)
+	if err != nil || f < 0 {
+		return 0, false
+	}
+	return int64(math.Ceil(f * mult)), true
+}
+
+// formatCPU renders millicores as a Kubernetes quantity.
+func formatCPU(milli int64) string {
+	return fmt.Sprintf("%dm", milli)
+}
+
+// formatMemory renders bytes as a Kubernetes quantity in Mi, rounded up.
+func formatMemory(b int64) string {
+	return fmt.Sprintf("%dMi", (b+(1<<20)-1)>>20)
+}
+
+// resourceSpec is a container's requests and limits (0 = unset).
+type resourceSpec struct {
+	CPURequest, CPULimit int64 // millicores
+	MemRequest, MemLimit int64 // bytes
+}
+
+// resourceUsage is what was observed for the controller container.
+type resourceUsage struct {
+	CPU, Mem  int64 // highest current usage across pods (millicores, bytes)
+	OOMKilled bool  // a container was last terminated for exceeding its memory limit
+	Restarts  int
+}
+
+// roundUp rounds n up to a multiple of step.
+func roundUp(n, step int64) int64 {
+	return (n + step - 1) / step * step
+}
+
+// recommendResources derives requests and limits from a usage sample:
+// requests cover usage with 25% headroom, limits allow a 2x burst (reloads
+// briefly double NGINX worker memory). After an OOM kill the memory limit
+// is at least 1.5x the limit that was hit.
+func recommendResources(u resourceUsage, cur resourceSpec) resourceSpec {
+	var r resourceSpec
+	r.CPURequest = roundUp(max(u.CPU*5/4, minCPURequestMilli), 50)
+	r.CPULimit = roundUp(r.CPURequest*2, 50)
+
+	r.MemRequest = roundUp(max(u.Mem*5/4, minMemRequestBytes), memRoundBytes)
+	r.MemLimit = roundUp(max(u.Mem*2, minMemLimitBytes), memRoundBytes)
+	if u.OOMKilled && cur.MemLimit > 0 {
+		r.MemLimit = max(r.MemLimit, roundUp(cur.MemLimit*3/2, memRoundBytes))
+	}
+	if r.MemLimit < r.MemRequest {
+		r.MemLimit = r.MemRequest
+	}
+	return r
+}
+
+// nearLimit reports whether usage has reached 80% of limit.
+func nearLimit(usage, limit int64) bool {
+	return limit > 0 && usage*10 >= limit*8
+}
@@ -0,0 +1,97 @@
+package main
+
+import "testing"
+
+// ─── parseCPU / parseMemory ──────────────────────────────────────────────────
+
+func TestParseCPU(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"250m", 250, true},
+		{"1", 1000, true},
+		{"0.5", 500, true},
+		{"1500000n", 2, true}, // rounded up
+		{"300000u", 300, true},
+		{"", 0, false},
+		{"abc", 0, false},
+	}
+	for _, c := range cases {
+		got, ok := parseCPU(c.in)
+		if got != c.want || ok != c.ok {
+			t.Errorf("parseCPU(%q) = %d, %v; want %d, %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"256Mi", 256 << 20, true},
+		{"1Gi", 1 << 30, true},
+		{"131072Ki", 128 << 20, true},
+		{"1G", 1e9, true},
+		{"134217728", 128 << 20, true},
+		{"", 0, false},
+		{"12Xi", 0, false},
+	}
+	for _, c := range cases {
+		got, ok := parseMemory(c.in)
+		if got != c.want || ok != c.ok {
+			t.Errorf("parseMemory(%q) = %d, %v; want %d, %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestFormatQuantities(t *testing.T) {
+	if got := formatCPU(150); got != "150m" {
+		t.Errorf("formatCPU = %q", got)
+	}
+	if got := formatMemory(200<<20 + 1); got != "201Mi" {
+		t.Errorf("formatMemory = %q, want rounded up", got)
+	}
+}
+
+// ─── recommendResources ──────────────────────────────────────────────────────
+
+func TestRecommendResourcesFloors(t *testing.T) {
+	r := recommendResources(resourceUsage{CPU: 5, Mem: 40 << 20}, resourceSpec{})
+	if r.CPURequest != 100 || r.CPULimit != 200 {
+		t.Errorf("cpu = %d/%d, want 100/200", r.CPURequest, r.CPULimit)
+	}
+	if r.MemRequest != 128<<20 || r.MemLimit != 256<<20 {
+		t.Errorf("memory = %s/%s, want 128Mi/256Mi", formatMemory(r.MemRequest), formatMemory(r.MemLimit))
+	}
+}
+
+func TestRecommendResourcesBusyController(t *testing.T) {
+	r := recommendResources(resourceUsage{CPU: 900, Mem: 600 << 20}, resourceSpec{MemLimit: 256 << 20})
+	if r.CPURequest != 1150 || r.CPULimit != 2300 {
+		t.Errorf("cpu = %d/%d, want 1150/2300", r.CPURequest, r.CPULimit)
+	}
+	if r.MemRequest != 768<<20 || r.MemLimit != 1216<<20 {
+		t.Errorf("memory = %s/%s, want 768Mi/1216Mi", formatMemory(r.MemRequest), formatMemory(r.MemLimit))
+	}
+}
+
+func TestRecommendResourcesAfterOOM(t *testing.T) {
+	// Usage snapshot is low after the restart; the OOM limit still drives it.
+	r := recommendResources(resourceUsage{Mem: 100 << 20, OOMKilled: true}, resourceSpec{MemLimit: 512 << 20})
+	if r.MemLimit != 768<<20 {
+		t.Errorf("memory limit = %s, want 768Mi", formatMemory(r.MemLimit))
+	}
+}
+
+func TestNearLimit(t *testing.T) {
+	if !nearLimit(210<<20, 256<<20) {
+		t.Error("82% of limit should be near")
+	}
+	if nearLimit(100<<20, 256<<20) || nearLimit(100, 0) {
+		t.Error("low usage or no limit should not be near")
+	}
+}
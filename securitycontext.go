@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Container securityContext checks
+// ─────────────────────────────────────────────
+
+// controllerUID is the non-root www-data user the controller image runs as.
+const controllerUID = 101
+
+// containerSecurity is the result of auditing one container.
+type containerSecurity struct {
+	Name     string
+	Init     bool
+	Findings []Finding
+}
+
+// podContainers returns the init containers followed by the regular
+// containers of a pod spec, with a flag marking init containers.
+func podContainers(podSpec map[string]interface{}) (containers []map[string]interface{}, init []bool) {
+	for _, key := range []string{"initContainers", "containers"} {
+		for _, c := range getSlice(podSpec, key) {
+			cm, _ := c.(map[string]interface{})
+			containers = append(containers, cm)
+			init = append(init, key == "initContainers")
+		}
+	}
+	return containers, init
+}
+
+// effectiveSecurityField returns a securityContext field of the container,
+// falling back to the pod-level securityContext for fields that exist there.
+func effectiveSecurityField(podSC, sc map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := sc[key]; ok {
+		return v, true
+	}
+	v, ok := podSC[key]
+	return v, ok
+}
+
+// evaluateContainerSecurity audits the securityContext of every container in
+// a pod spec, resolving pod-level defaults. version is the controller version,
+// used for the controller container only.
+func evaluateContainerSecurity(podSpec map[string]interface{}, version, res string) []containerSecurity {
+	podSC := getMap(podSpec, "securityContext")
+	var out []containerSecurity
+	containers, init := podContainers(podSpec)
+	for i, c := range containers {
+		name := getString(c, "name")
+		cs := containerSecurity{Name: name, Init: init[i]}
+		sc := getMap(c, "securityContext")
+		cres := fmt.Sprintf("%s[%s]", res, name)
+		add := func(id, level, msg, fix string) {
+			cs.Findings = append(cs.Findings, Finding{ID: id, Level: level, Resource: cres, Message: msg, Remediation: fix})
+		}
+
+		if sc["privileged"] == true {
+			add("sc-privileged", "FAIL", fmt.Sprintf("Container %s runs privileged", name),
+				"Remove securityContext.privileged")
+		}
+
+		if sc["allowPrivilegeEscalation"] != false {
+			level := "WARN"
+			msg := fmt.Sprintf("Container %s allows privilege escalation", name)
+			if name == "controller" && !versionAtLeast(version, "v1.9.0") {
+				// Older controller images grant NET_BIND_SERVICE through a file
+				// capability, which needs privilege escalation.
+				level = "INFO"
+				msg += fmt.Sprintf(" (required by %s; upgrade to v1.9+ to disable it)", version)
+			}
+			add("sc-privilege-escalation", level, msg, "Set securityContext.allowPrivilegeEscalation: false")
+		}
+
+		caps := getMap(sc, "capabilities")
+		dropped := false
+		for _, d := range getSlice(caps, "drop") {
+			if strings.EqualFold(fmt.Sprintf("%v", d), "ALL") {
+				dropped = true
+			}
+		}
+		if !dropped {
+			add("sc-capabilities-drop", "WARN", fmt.Sprintf("Container %s does not drop ALL capabilities", name),
+				"Set securityContext.capabilities.drop: [ALL]")
+		}
+		var extra []string
+		for _, a := range getSlice(caps, "add") {
+			if s := strings.ToUpper(strings.TrimPrefix(fmt.Sprintf("%v", a), "CAP_")); s != "NET_BIND_SERVICE" {
+				extra = append(extra, s)
+			}
+		}
+		if len(extra) > 0 {
+			add("sc-capabilities-add", "FAIL",
+				fmt.Sprintf("Container %s adds capabilities beyond NET_BIND_SERVICE: %s", name, strings.Join(extra, ", ")),
+				"Keep only NET_BIND_SERVICE in securityContext.capabilities.add")
+		}
+
+		if sc["readOnlyRootFilesystem"] != true {
+			add("sc-readonly-rootfs", "WARN", fmt.Sprintf("Container %s has a writable root filesystem", name),
+				"Set securityContext.readOnlyRootFilesystem: true and mount emptyDir volumes for writable paths (/tmp, /etc/nginx for the controller)")
+		}
+
+		seccomp, _ := effectiveSecurityField(podSC, sc, "seccompProfile")
+		switch t := getString(asMap(seccomp), "type"); t {
+		case "RuntimeDefault", "Localhost":
+		case "Unconfined":
+			add("sc-seccomp", "FAIL", fmt.Sprintf("Container %s runs with an Unconfined seccomp profile", name),
+				"Set securityContext.seccompProfile.type: RuntimeDefault")
+		default:
+			add("sc-seccomp", "WARN", fmt.Sprintf("Container %s has no seccomp profile", name),
+				"Set securityContext.seccompProfile.type: RuntimeDefault")
+		}
+
+		nonRoot, _ := effectiveSecurityField(podSC, sc, "runAsNonRoot")
+		uidVal, hasUID := effectiveSecurityField(podSC, sc, "runAsUser")
+		uid, _ := toInt(uidVal)
+		switch {
+		case hasUID && uid == 0:
+			add("sc-run-as-root", "FAIL", fmt.Sprintf("Container %s runs as root (runAsUser: 0)", name),
+				"Set securityContext.runAsUser to a non-zero UID")
+		case nonRoot != true && !hasUID:
+			add("sc-run-as-non-root", "WARN", fmt.Sprintf("Container %s does not enforce runAsNonRoot", name),
+				"Set securityContext.runAsNonRoot: true")
+		}
+
+		if pm := getString(sc, "procMount"); pm != "" && pm != "Default" {
+			add("sc-proc-mount", "FAIL", fmt.Sprintf("Container %s uses procMount %s", name, pm),
+				"Remove securityContext.procMount")
+		}
+		out = append(out, cs)
+	}
+	return out
+}
+
+// asMap returns v as a map, or an empty map.
+func asMap(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
+
+// hardenedControllerSecurityContext is the securityContext offered as a fix
+// for the controller container. readOnlyRootFilesystem is left out because it
+// needs matching emptyDir mounts.
+func hardenedControllerSecurityContext(version string) map[string]interface{} {
+	sc := map[string]interface{}{
+		"runAsNonRoot":   true,
+		"runAsUser":      controllerUID,
+		"seccompProfile": map[string]interface{}{"type": "RuntimeDefault"},
+		"capabilities": map[string]interface{}{
+			"drop": []string{"ALL"},
+			"add":  []string{"NET_BIND_SERVICE"},
+		},
+	}
+	if versionAtLeast(version, "v1.9.0") {
+		sc["allowPrivilegeEscalation"] = false
+	}
+	return sc
+}
+
+// hardenedControllerHelmValues is hardenedControllerSecurityContext as
+// ingress-nginx chart values.
+func hardenedControllerHelmValues(version string) map[string]string {
+	values := map[string]string{
+		"controller.image.runAsNonRoot":        "true",
+		"controller.image.runAsUser":           fmt.Sprintf("%d", controllerUID),
+		"controller.image.seccompProfile.type": "RuntimeDefault",
+	}
+	if versionAtLeast(version, "v1.9.0") {
+		values["controller.image.allowPrivilegeEscalation"] = "false"
+	}
+	return values
+}
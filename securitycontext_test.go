@@ -0,0 +1,105 @@
+package main
+
+import "testing"
+
+func scFindingIDs(cs containerSecurity) map[string]string {
+	ids := map[string]string{}
+	for _, f := range cs.Findings {
+		ids[f.ID] = f.Level
+	}
+	return ids
+}
+
+func hardenedContainer(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"securityContext": map[string]interface{}{
+			"allowPrivilegeEscalation": false,
+			"readOnlyRootFilesystem":   true,
+			"runAsNonRoot":             true,
+			"runAsUser":                float64(101),
+			"seccompProfile":           map[string]interface{}{"type": "RuntimeDefault"},
+			"capabilities": map[string]interface{}{
+				"drop": []interface{}{"ALL"},
+				"add":  []interface{}{"NET_BIND_SERVICE"},
+			},
+		},
+	}
+}
+
+// ─── evaluateContainerSecurity ───────────────────────────────────────────────
+
+func TestEvaluateContainerSecurityHardened(t *testing.T) {
+	spec := map[string]interface{}{"containers": []interface{}{hardenedContainer("controller")}}
+	got := evaluateContainerSecurity(spec, "v1.12.0", "deployment/x")
+	if len(got) != 1 || len(got[0].Findings) != 0 {
+		t.Errorf("hardened container produced findings: %+v", got)
+	}
+}
+
+func TestEvaluateContainerSecurityPerContainer(t *testing.T) {
+	// The old jsonpath check joined "privileged" across containers and
+	// missed init containers entirely.
+	spec := map[string]interface{}{
+		"initContainers": []interface{}{map[string]interface{}{
+			"name":            "init-sysctl",
+			"securityContext": map[string]interface{}{"privileged": true, "runAsUser": float64(0)},
+		}},
+		"containers": []interface{}{hardenedContainer("controller"), map[string]interface{}{
+			"name": "sidecar",
+			"securityContext": map[string]interface{}{
+				"capabilities": map[string]interface{}{"add": []interface{}{"SYS_ADMIN", "NET_BIND_SERVICE"}},
+				"procMount":    "Unmasked",
+			},
+		}},
+	}
+	got := evaluateContainerSecurity(spec, "v1.12.0", "deployment/x")
+	if len(got) != 3 || !got[0].Init || got[1].Name != "controller" {
+		t.Fatalf("containers = %+v", got)
+	}
+	initIDs := scFindingIDs(got[0])
+	if initIDs["sc-privileged"] != "FAIL" || initIDs["sc-run-as-root"] != "FAIL" {
+		t.Errorf("init container findings = %v", initIDs)
+	}
+	if len(got[1].Findings) != 0 {
+		t.Errorf("controller findings = %+v", got[1].Findings)
+	}
+	sidecar := scFindingIDs(got[2])
+	for _, id := range []string{"sc-capabilities-add", "sc-proc-mount", "sc-capabilities-drop",
+		"sc-privilege-escalation", "sc-readonly-rootfs", "sc-seccomp", "sc-run-as-non-root"} {
+		if _, ok := sidecar[id]; !ok {
+			t.Errorf("sidecar missing %s (got %v)", id, sidecar)
+		}
+	}
+}
+
+func TestEvaluateContainerSecurityPodDefaults(t *testing.T) {
+	c := hardenedContainer("controller")
+	sc := c["securityContext"].(map[string]interface{})
+	delete(sc, "seccompProfile")
+	delete(sc, "runAsNonRoot")
+	delete(sc, "runAsUser")
+	spec := map[string]interface{}{
+		"securityContext": map[string]interface{}{
+			"runAsNonRoot":   true,
+			"seccompProfile": map[string]interface{}{"type": "RuntimeDefault"},
+		},
+		"containers": []interface{}{c},
+	}
+	if got := evaluateContainerSecurity(spec, "v1.12.0", "deployment/x"); len(got[0].Findings) != 0 {
+		t.Errorf("pod-level defaults not applied: %+v", got[0].Findings)
+	}
+}
+
+func TestEvaluateContainerSecurityOldControllerEscalation(t *testing.T) {
+	c := hardenedContainer("controller")
+	delete(c["securityContext"].(map[string]interface{}), "allowPrivilegeEscalation")
+	spec := map[string]interface{}{"containers": []interface{}{c}}
+	got := evaluateContainerSecurity(spec, "v1.8.4", "deployment/x")
+	if scFindingIDs(got[0])["sc-privilege-escalation"] != "INFO" {
+		t.Errorf("pre-v1.9 controller needs escalation: %+v", got[0].Findings)
+	}
+	if _, ok := hardenedControllerSecurityContext("v1.8.4")["allowPrivilegeEscalation"]; ok {
+		t.Error("fix must not disable escalation on pre-v1.9 controllers")
+	}
+}
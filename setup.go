@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
@@ -12,6 +13,30 @@ import (
 	"github.com/motki/cli/text/banner"
 )
 
+// ----- Command-line flags -----
+
+// parseFlags reads the optional command-line flags into a. Everything else
+// is collected by the interactive setup.
+func parseFlags(a *AuditState) {
+	flag.StringVar(&a.ControlPlaneCIDR, "control-plane-cidr", "",
+		"comma-separated CIDR(s) the API server calls the admission webhook from; required to offer the generated NetworkPolicy as a fix")
+	flag.StringVar(&a.MonitoringNamespace, "monitoring-namespace", "monitoring",
+		"namespace allowed to scrape the controller metrics port")
+	flag.StringVar(&a.LBScheme, "lb-scheme", "",
+		`intended load balancer scheme: "internal" or "internet-facing" (default: guessed from names)`)
+	flag.StringVar(&a.ServiceAllowlistFile, "service-allowlist", "",
+		`file listing expected external services, one "namespace/name" glob per line`)
+	flag.StringVar(&a.TLSProfile, "tls-profile", "intermediate",
+		`Mozilla TLS profile to grade against: "modern", "intermediate" or "old"`)
+	flag.StringVar(&a.ImageAllowlist, "image-allowlist", defaultImageAllowlist,
+		`comma-separated "registry/repository" globs controller pod images may come from`)
+	flag.StringVar(&a.ImageScanReport, "image-scan-report", "",
+		"Trivy/Grype JSON report or CycloneDX/SPDX SBOM of the controller image (default: scan with trivy/grype if installed)")
+	flag.StringVar(&a.HelmChartRepo, "helm-chart-repo", defaultHelmChartRepo,
+		"chart repository URL Helm-routed fixes upgrade the release from (pinned to the installed chart version)")
+	flag.Parse()
+}
+
 // ----- Interactive Setup -----
 
 // promptUser prints a prompt and reads a line from stdin.
@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"os"
 	"os/exec"
 	"regexp"
 	"strings"
@@ -23,12 +25,41 @@ func kubectlE(args ...string) (string, string, error) {
 	return strings.TrimSpace(stdout.String()), strings.TrimSpace(stderr.String()), err
 }
 
+// kubectlJSON runs kubectl with "-o json" appended and decodes the object.
+func kubectlJSON(args ...string) (map[string]interface{}, error) {
+	out, err := kubectl(append(args, "-o", "json")...)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// kubectlInput runs kubectl with input on stdin and returns trimmed stdout.
+func kubectlInput(input string, args ...string) (string, error) {
+	cmd := exec.Command("kubectl", args...)
+	cmd.Stdin = strings.NewReader(input)
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
 // helmCmd runs a helm command and returns trimmed stdout.
 func helmCmd(args ...string) (string, error) {
 	out, err := exec.Command("helm", args...).Output()
 	return strings.TrimSpace(string(out)), err
 }
 
+// commandEnv runs a program with extra environment variables and returns
+// its raw stdout.
+func commandEnv(env []string, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.Output()
+}
+
 // cmdExists reports whether a program is available on PATH.
 func cmdExists(name string) bool {
 	_, err := exec.LookPath(name)
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// NGINX snippet parsing and inspection
+// ─────────────────────────────────────────────
+
+// ingressSnippetAnnotations and configMapSnippetKeys hold raw NGINX
+// configuration.
+var (
+	ingressSnippetAnnotations = []string{"configuration-snippet", "server-snippet", "auth-snippet", "stream-snippet"}
+	configMapSnippetKeys      = []string{"main-snippet", "http-snippet", "server-snippet"}
+)
+
+// nginxDirective is one parsed directive. Block holds the children of a
+// block directive; Raw holds the body of a *_by_lua_block verbatim.
+type nginxDirective struct {
+	Name  string
+	Args  []string
+	Line  int
+	Block []nginxDirective
+	Raw   string
+}
+
+// snippetParser is a small tokenizer for nginx.conf syntax.
+type snippetParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+// next returns the next token: a word, a quoted string (unquoted), or one
+// of ";", "{", "}". quoted tells a quoted ";" apart from the terminator.
+func (p *snippetParser) next() (tok string, quoted bool, line int, ok bool) {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			goto token
+		}
+	}
+	return "", false, p.line, false
+
+token:
+	line = p.line
+	c := p.src[p.pos]
+	if c == ';' || c == '{' || c == '}' {
+		p.pos++
+		return string(c), false, line, true
+	}
+	if c == '"' || c == '\'' {
+		var b strings.Builder
+		p.pos++
+		for p.pos < len(p.src) && p.src[p.pos] != c {
+			if p.src[p.pos] == '\\' && p.pos+1 < len(p.src) {
+				p.pos++
+			}
+			if p.src[p.pos] == '\n' {
+				p.line++
+			}
+			b.WriteByte(p.src[p.pos])
+			p.pos++
+		}
+		p.pos++ // closing quote
+		return b.String(), true, line, true
+	}
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(" \t\r\n;{}\"'", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos], false, line, true
+}
+
+// rawBlock returns the text up to the "}" matching an already consumed "{",
+// for Lua blocks that are not nginx syntax.
+func (p *snippetParser) rawBlock() (string, error) {
+	depth, start := 1, p.pos
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\n':
+			p.line++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				raw := p.src[start:p.pos]
+				p.pos++
+				return raw, nil
+			}
+		}
+		p.pos++
+	}
+	return "", fmt.Errorf("unterminated block")
+}
+
+// parseBlock reads directives until the end of input (top level) or a "}".
+func (p *snippetParser) parseBlock(top bool) ([]nginxDirective, error) {
+	var out []nginxDirective
+	var cur *nginxDirective
+	for {
+		tok, quoted, line, ok := p.next()
+		if !ok {
+			if cur != nil {
+				return out, fmt.Errorf("line %d: directive %q is missing a terminating \";\"", cur.Line, cur.Name)
+			}
+			if !top {
+				return out, fmt.Errorf("line %d: unexpected end of input, expecting \"}\"", line)
+			}
+			return out, nil
+		}
+		switch {
+		case !quoted && tok == ";":
+			if cur == nil {
+				return out, fmt.Errorf("line %d: unexpected \";\"", line)
+			}
+			out = append(out, *cur)
+			cur = nil
+		case !quoted && tok == "{":
+			if cur == nil {
+				return out, fmt.Errorf("line %d: unexpected \"{\"", line)
+			}
+			var err error
+			if strings.HasSuffix(cur.Name, "_by_lua_block") {
+				cur.Raw, err = p.rawBlock()
+			} else {
+				cur.Block, err = p.parseBlock(false)
+				if cur.Block == nil {
+					cur.Block = []nginxDirective{}
+				}
+			}
+			if err != nil {
+				return out, err
+			}
+			out = append(out, *cur)
+			cur = nil
+		case !quoted && tok == "}":
+			if top {
+				return out, fmt.Errorf("line %d: unexpected \"}\"", line)
+			}
+			if cur != nil {
+				return out, fmt.Errorf("line %d: directive %q is missing a terminating \";\"", cur.Line, cur.Name)
+			}
+			return out, nil
+		case cur == nil:
+			cur = &nginxDirective{Name: tok, Line: line}
+		default:
+			cur.Args = append(cur.Args, tok)
+		}
+	}
+}
+
+// parseNginxSnippet parses snippet text into directives. Line numbers are
+// 1-based within the snippet.
+func parseNginxSnippet(src string) ([]nginxDirective, error) {
+	p := &snippetParser{src: src, line: 1}
+	return p.parseBlock(true)
+}
+
+// walkDirectives calls fn for every directive, depth first.
+func walkDirectives(ds []nginxDirective, fn func(nginxDirective)) {
+	for _, d := range ds {
+		fn(d)
+		walkDirectives(d.Block, fn)
+	}
+}
+
+// allowedWebRoots are the directories snippets may serve files from.
+var allowedWebRoots = []string{"/usr/local/nginx/html", "/var/www"}
+
+// securityHeaders must not be stripped from responses.
+var securityHeaders = []string{
+	"strict-transport-security", "content-security-policy", "x-frame-options",
+	"x-content-type-options", "referrer-policy", "permissions-policy",
+}
+
+// internalHostnames are reachable only from inside the cluster or the node.
+var internalHostnames = []string{"kubernetes.default", "metadata.google.internal", "metadata", "localhost"}
+
+// classifyProxyTarget describes why a proxy_pass target is dangerous, with
+// a level, or returns "" for an ordinary upstream.
+func classifyProxyTarget(target string) (level, why string) {
+	if clientVariableRE.MatchString(target) {
+		return "FAIL", "takes the upstream from client-controlled variables (SSRF)"
+	}
+	if !strings.Contains(target, "://") {
+		return "", "" // upstream{} name or variable
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", ""
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		switch {
+		case ip.IsLinkLocalUnicast():
+			return "FAIL", "targets a link-local address (cloud metadata service)"
+		case ip.IsLoopback():
+			return "FAIL", "targets the controller's loopback interface (internal status and configuration ports)"
+		case ip.IsPrivate():
+			return "WARN", "targets a cluster-internal address directly"
+		}
+		return "", ""
+	}
+	for _, h := range internalHostnames {
+		if host == h || strings.HasPrefix(host, h+".") {
+			return "FAIL", fmt.Sprintf("targets %s", host)
+		}
+	}
+	if isClusterLocalHost(host) && strings.Contains(host, ".") {
+		return "WARN", "targets a cluster-internal Service directly, bypassing the Ingress backend"
+	}
+	return "", ""
+}
+
+// headerName returns the lower-cased header name of a more_set_headers /
+// more_clear_headers argument and whether it clears the header (no value).
+func headerName(arg string) (string, bool) {
+	name, value, found := strings.Cut(arg, ":")
+	return strings.ToLower(strings.TrimSpace(name)), !found || strings.TrimSpace(value) == ""
+}
+
+// inspectSnippet parses one snippet and flags dangerous directives. source
+// names the snippet ("configuration-snippet", "ConfigMap http-snippet").
+func inspectSnippet(source, src, res string) []Finding {
+	var findings []Finding
+	add := func(d nginxDirective, id, level, msg, fix string) {
+		findings = append(findings, Finding{ID: id, Level: level, Resource: res,
+			Message: fmt.Sprintf("%s line %d: %s", source, d.Line, msg), Remediation: fix})
+	}
+	directives, err := parseNginxSnippet(src)
+	if err != nil {
+		findings = append(findings, Finding{ID: "snippet-parse-error", Level: "WARN", Resource: res,
+			Message:     fmt.Sprintf("%s does not parse: %v", source, err),
+			Remediation: "A snippet that does not parse breaks the NGINX reload for every Ingress; fix its syntax"})
+	}
+	walkDirectives(directives, func(d nginxDirective) {
+		switch {
+		case strings.Contains(d.Name, "_by_lua"):
+			add(d, "snippet-lua", "FAIL", fmt.Sprintf("%s runs Lua code inside the controller", d.Name),
+				"Remove the Lua directive; Lua has the controller's full privileges including its ServiceAccount token")
+		case d.Name == "load_module":
+			add(d, "snippet-load-module", "FAIL", "load_module loads a shared object into NGINX",
+				"Remove load_module")
+		case d.Name == "include":
+			add(d, "snippet-include", "FAIL", fmt.Sprintf("include %s pulls arbitrary files into the configuration", strings.Join(d.Args, " ")),
+				"Inline the configuration instead of including files")
+		case (d.Name == "alias" || d.Name == "root") && len(d.Args) > 0:
+			p := d.Args[0]
+			inside := false
+			for _, root := range allowedWebRoots {
+				clean := path.Clean(p)
+				if clean == root || strings.HasPrefix(clean, root+"/") {
+					inside = !strings.Contains(p, "..")
+				}
+			}
+			if !inside {
+				add(d, "snippet-file-access", "FAIL", fmt.Sprintf("%s %s serves files outside the web root", d.Name, p),
+					"Serve static content from a backend; alias/root can expose /etc/nginx, TLS keys or the ServiceAccount token (CVE-2021-25742)")
+			}
+		case d.Name == "proxy_pass" && len(d.Args) > 0:
+			if level, why := classifyProxyTarget(d.Args[0]); level != "" {
+				add(d, "snippet-proxy-internal", level, fmt.Sprintf("proxy_pass %s %s", d.Args[0], why),
+					"Route to a Service through the Ingress backend instead of proxy_pass in a snippet")
+			}
+		case d.Name == "more_set_headers" || d.Name == "more_clear_headers":
+			for _, a := range d.Args {
+				if strings.HasPrefix(a, "-") {
+					continue // -s/-t option and its value
+				}
+				name, clears := headerName(a)
+				if (d.Name == "more_clear_headers" || clears) && contains(securityHeaders, name) {
+					add(d, "snippet-header-strip", "WARN", fmt.Sprintf("%s strips security header %s", d.Name, name),
+						"Keep the security header, or override it with a stricter value")
+				}
+			}
+		}
+	})
+	return findings
+}
+
+// ingressSnippets returns the snippet annotations of an Ingress in a stable
+// order.
+func ingressSnippets(anns map[string]string) [][2]string {
+	var out [][2]string
+	for _, k := range ingressSnippetAnnotations {
+		if v, ok := anns[nginxAnnotationPrefix+k]; ok && strings.TrimSpace(v) != "" {
+			out = append(out, [2]string{k, v})
+		}
+	}
+	return out
+}
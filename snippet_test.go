@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ─── parseNginxSnippet ───────────────────────────────────────────────────────
+
+func TestParseNginxSnippet(t *testing.T) {
+	src := `# comment
+more_set_headers "X-A: b;c";
+location /x {
+    access_by_lua_block {
+        if ngx.var.a then ngx.exit(403) end
+    }
+    proxy_pass http://svc;
+}
+`
+	ds, err := parseNginxSnippet(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 2 || ds[0].Name != "more_set_headers" || ds[0].Line != 2 || ds[0].Args[0] != "X-A: b;c" {
+		t.Fatalf("directives = %+v", ds)
+	}
+	loc := ds[1]
+	if loc.Name != "location" || loc.Line != 3 || len(loc.Block) != 2 {
+		t.Fatalf("location = %+v", loc)
+	}
+	if lua := loc.Block[0]; lua.Line != 4 || !strings.Contains(lua.Raw, "ngx.exit") {
+		t.Errorf("lua = %+v", lua)
+	}
+	if pp := loc.Block[1]; pp.Name != "proxy_pass" || pp.Line != 7 {
+		t.Errorf("proxy_pass = %+v", pp)
+	}
+}
+
+func TestParseNginxSnippetErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"add_header X y":              `line 1: directive "add_header" is missing`,
+		"location / {\n  return 200;": "line 2: unexpected end of input",
+		"}":                           `line 1: unexpected "}"`,
+	} {
+		if _, err := parseNginxSnippet(src); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: err = %v, want %q", src, err, want)
+		}
+	}
+}
+
+// ─── classifyProxyTarget ─────────────────────────────────────────────────────
+
+func TestClassifyProxyTarget(t *testing.T) {
+	cases := map[string]string{
+		"http://169.254.169.254/latest/meta-data/": "FAIL",
+		"http://127.0.0.1:10246/nginx_status":      "FAIL",
+		"https://kubernetes.default.svc/api":       "FAIL",
+		"http://10.96.0.12:8080":                   "WARN",
+		"http://admin.internal.svc.cluster.local":  "WARN",
+		"http://$http_x_backend":                   "FAIL",
+		"https://api.example.com":                  "",
+		"http://upstream_balancer":                 "",
+		"$proxy_upstream_name":                     "",
+	}
+	for target, want := range cases {
+		if got, _ := classifyProxyTarget(target); got != want {
+			t.Errorf("classifyProxyTarget(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+// ─── inspectSnippet ──────────────────────────────────────────────────────────
+
+func TestInspectSnippet(t *testing.T) {
+	src := `more_set_headers "X-Served-By: edge";
+more_clear_headers Strict-Transport-Security;
+more_set_headers -s 404 "X-Frame-Options:";
+location /static/ {
+    alias /usr/local/nginx/html/static/;
+}
+location /leak {
+    alias /var/run/secrets/kubernetes.io/serviceaccount/;
+}
+location /meta {
+    proxy_pass http://169.254.169.254/;
+}
+include /etc/nginx/conf.d/*.conf;
+content_by_lua_block { ngx.say("hi") }
+`
+	fs := inspectSnippet("configuration-snippet", src, "ingress/team/web")
+	want := []struct {
+		id, level, msg string
+	}{
+		{"snippet-header-strip", "WARN", "configuration-snippet line 2: more_clear_headers strips security header strict-transport-security"},
+		{"snippet-header-strip", "WARN", "line 3: more_set_headers strips security header x-frame-options"},
+		{"snippet-file-access", "FAIL", "line 8: alias /var/run/secrets"},
+		{"snippet-proxy-internal", "FAIL", "line 11: proxy_pass http://169.254.169.254/"},
+		{"snippet-include", "FAIL", "line 13"},
+		{"snippet-lua", "FAIL", "line 14: content_by_lua_block"},
+	}
+	if len(fs) != len(want) {
+		t.Fatalf("findings = %+v", fs)
+	}
+	for i, w := range want {
+		if fs[i].ID != w.id || fs[i].Level != w.level || !strings.Contains(fs[i].Message, w.msg) || fs[i].Resource != "ingress/team/web" {
+			t.Errorf("finding %d = %+v, want %s %s %q", i, fs[i], w.id, w.level, w.msg)
+		}
+	}
+}
+
+func TestInspectSnippetParseError(t *testing.T) {
+	fs := inspectSnippet("ConfigMap http-snippet", "load_module x.so;\nserver {", "configmap/ingress-nginx/c")
+	if _, ok := findingByID(fs, "snippet-parse-error"); !ok {
+		t.Errorf("findings = %+v", fs)
+	}
+	if f, ok := findingByID(fs, "snippet-load-module"); !ok || !strings.Contains(f.Message, "http-snippet line 1") {
+		t.Errorf("directives before the error should still be inspected: %+v", fs)
+	}
+}
+
+func TestIngressSnippets(t *testing.T) {
+	anns := map[string]string{
+		nginxAnnotationPrefix + "server-snippet":        "return 403;",
+		nginxAnnotationPrefix + "configuration-snippet": "  ",
+		nginxAnnotationPrefix + "auth-snippet":          "proxy_set_header A b;",
+		nginxAnnotationPrefix + "rewrite-target":        "/",
+	}
+	got := ingressSnippets(anns)
+	if len(got) != 2 || got[0][0] != "server-snippet" || got[1][0] != "auth-snippet" {
+		t.Errorf("ingressSnippets = %v", got)
+	}
+}
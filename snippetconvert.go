@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Snippet → annotation conversion
+// ─────────────────────────────────────────────
+
+// snippetConversion is the first-class configuration that replaces the
+// snippets of one Ingress. Annotation names are without the prefix.
+type snippetConversion struct {
+	Annotations     map[string]string
+	Headers         map[string]string // response headers → custom-headers ConfigMap
+	UpstreamHeaders map[string]string // proxy_set_header → proxy-set-headers ConfigMap (global)
+	Unconverted     []string          // "configuration-snippet line 3: ..."
+}
+
+// Complete reports whether every snippet directive was converted.
+func (c snippetConversion) Complete() bool { return len(c.Unconverted) == 0 }
+
+// annotationNames returns the converted annotation names in order.
+func (c snippetConversion) annotationNames() []string {
+	names := make([]string, 0, len(c.Annotations))
+	for k := range c.Annotations {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// corsHeaderAnnotations maps CORS response headers onto the cors-* annotations.
+var corsHeaderAnnotations = map[string]string{
+	"access-control-allow-origin":      "cors-allow-origin",
+	"access-control-allow-methods":     "cors-allow-methods",
+	"access-control-allow-headers":     "cors-allow-headers",
+	"access-control-allow-credentials": "cors-allow-credentials",
+	"access-control-expose-headers":    "cors-expose-headers",
+	"access-control-max-age":           "cors-max-age",
+}
+
+// directiveAnnotations maps single-argument directives onto annotations.
+// Timeouts take plain seconds.
+var directiveAnnotations = map[string]string{
+	"client_max_body_size":    "proxy-body-size",
+	"proxy_read_timeout":      "proxy-read-timeout",
+	"proxy_send_timeout":      "proxy-send-timeout",
+	"proxy_connect_timeout":   "proxy-connect-timeout",
+	"proxy_buffer_size":       "proxy-buffer-size",
+	"proxy_buffering":         "proxy-buffering",
+	"proxy_request_buffering": "proxy-request-buffering",
+	"proxy_http_version":      "proxy-http-version",
+}
+
+// wholeSnippetRegexes are rewrite patterns that match every request.
+var wholeSnippetRegexes = []string{"^", "^.*$", "^(.*)$", "^/(.*)$", "^/?(.*)$"}
+
+// limitReqZones returns the rate of every limit_req_zone defined in the
+// ConfigMap http-snippet ("api" → "10r/s").
+func limitReqZones(httpSnippet string) map[string]string {
+	zones := map[string]string{}
+	ds, _ := parseNginxSnippet(httpSnippet)
+	walkDirectives(ds, func(d nginxDirective) {
+		if d.Name != "limit_req_zone" {
+			return
+		}
+		var zone, rate string
+		for _, a := range d.Args {
+			if v, ok := strings.CutPrefix(a, "zone="); ok {
+				zone, _, _ = strings.Cut(v, ":")
+			}
+			if v, ok := strings.CutPrefix(a, "rate="); ok {
+				rate = v
+			}
+		}
+		if zone != "" && rate != "" {
+			zones[zone] = rate
+		}
+	})
+	return zones
+}
+
+// nginxSeconds converts an nginx time ("30", "30s", "2m") to seconds.
+func nginxSeconds(v string) (string, bool) {
+	mult := 1
+	switch {
+	case strings.HasSuffix(v, "s"):
+		v = strings.TrimSuffix(v, "s")
+	case strings.HasSuffix(v, "m"):
+		v, mult = strings.TrimSuffix(v, "m"), 60
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return "", false
+	}
+	return strconv.Itoa(n * mult), true
+}
+
+// snippetConverter accumulates the conversion of one Ingress.
+type snippetConverter struct {
+	conv  snippetConversion
+	zones map[string]string
+}
+
+func (c *snippetConverter) unconverted(source string, d nginxDirective, why string) {
+	text := strings.TrimSpace(d.Name + " " + strings.Join(d.Args, " "))
+	if why != "" {
+		text += " (" + why + ")"
+	}
+	c.conv.Unconverted = append(c.conv.Unconverted, fmt.Sprintf("%s line %d: %s", source, d.Line, text))
+}
+
+// header converts one response header, routing CORS headers to the cors-*
+// annotations.
+func (c *snippetConverter) header(name, value string) {
+	if ann, ok := corsHeaderAnnotations[strings.ToLower(name)]; ok {
+		c.conv.Annotations["enable-cors"] = "true"
+		if ann == "cors-allow-credentials" || ann == "cors-max-age" || value != "" {
+			c.conv.Annotations[ann] = value
+		}
+		return
+	}
+	c.conv.Headers[name] = value
+}
+
+// redirect converts "return <code> <url>" and whole-request rewrites.
+func (c *snippetConverter) redirect(code, url string) bool {
+	switch code {
+	case "301", "308":
+		c.conv.Annotations["permanent-redirect"] = url
+		if code == "308" {
+			c.conv.Annotations["permanent-redirect-code"] = code
+		}
+	case "302", "307":
+		c.conv.Annotations["temporal-redirect"] = url
+		if code == "307" {
+			c.conv.Annotations["temporal-redirect-code"] = code
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// directive converts one directive, or records it as unconverted.
+func (c *snippetConverter) directive(source string, d nginxDirective) {
+	switch {
+	case d.Name == "add_header" && len(d.Args) >= 2:
+		c.header(d.Args[0], d.Args[1])
+	case d.Name == "more_set_headers":
+		for _, a := range d.Args {
+			if a == "-s" || a == "-t" {
+				c.unconverted(source, d, "status/content-type filters have no annotation")
+				return
+			}
+		}
+		for _, a := range d.Args {
+			name, value, _ := strings.Cut(a, ":")
+			c.header(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+	case d.Name == "proxy_set_header" && len(d.Args) == 2:
+		switch strings.ToLower(d.Args[0]) {
+		case "host":
+			c.conv.Annotations["upstream-vhost"] = d.Args[1]
+		case "x-forwarded-prefix":
+			c.conv.Annotations["x-forwarded-prefix"] = d.Args[1]
+		default:
+			c.conv.UpstreamHeaders[d.Args[0]] = d.Args[1]
+		}
+	case d.Name == "return" && len(d.Args) == 2 && strings.Contains(d.Args[1], "://"):
+		if !c.redirect(d.Args[0], d.Args[1]) {
+			c.unconverted(source, d, "")
+		}
+	case d.Name == "rewrite" && len(d.Args) == 3 && contains(wholeSnippetRegexes, d.Args[0]):
+		code := map[string]string{"permanent": "301", "redirect": "302"}[d.Args[2]]
+		if !c.redirect(code, d.Args[1]) {
+			c.unconverted(source, d, "")
+		}
+	case d.Name == "if" && d.Block != nil:
+		c.ifBlock(source, d)
+	case d.Name == "limit_req":
+		c.limitReq(source, d)
+	case d.Name == "limit_conn" && len(d.Args) == 2:
+		c.conv.Annotations["limit-connections"] = d.Args[1]
+	case directiveAnnotations[d.Name] != "" && len(d.Args) == 1:
+		v := d.Args[0]
+		if strings.HasSuffix(d.Name, "_timeout") {
+			s, ok := nginxSeconds(v)
+			if !ok {
+				c.unconverted(source, d, "")
+				return
+			}
+			v = s
+		}
+		c.conv.Annotations[directiveAnnotations[d.Name]] = v
+	default:
+		c.unconverted(source, d, "")
+	}
+}
+
+// ifBlock converts the two common if blocks: the CORS preflight and the
+// HTTP → HTTPS redirect.
+func (c *snippetConverter) ifBlock(source string, d nginxDirective) {
+	cond := strings.Join(d.Args, " ")
+	switch {
+	case strings.Contains(cond, "$request_method") && strings.Contains(strings.ToUpper(cond), "OPTIONS"):
+		c.conv.Annotations["enable-cors"] = "true"
+		for _, child := range d.Block {
+			if child.Name == "return" || child.Name == "add_header" || child.Name == "more_set_headers" {
+				if child.Name != "return" {
+					c.directive(source, child)
+				}
+				continue
+			}
+			c.unconverted(source, child, "inside CORS preflight block")
+		}
+	case strings.Contains(cond, "$scheme") && len(d.Block) == 1 && d.Block[0].Name == "return" &&
+		len(d.Block[0].Args) == 2 && strings.HasPrefix(d.Block[0].Args[1], "https://"):
+		c.conv.Annotations["force-ssl-redirect"] = "true"
+	default:
+		c.unconverted(source, d, "if blocks have no annotation equivalent")
+	}
+}
+
+// limitReq converts limit_req using the zone rate from the ConfigMap
+// http-snippet; burst becomes limit-burst-multiplier.
+func (c *snippetConverter) limitReq(source string, d nginxDirective) {
+	var zone string
+	burst := 0
+	for _, a := range d.Args {
+		if v, ok := strings.CutPrefix(a, "zone="); ok {
+			zone = v
+		}
+		if v, ok := strings.CutPrefix(a, "burst="); ok {
+			burst, _ = strconv.Atoi(v)
+		}
+	}
+	rate, ok := c.zones[zone]
+	if !ok {
+		c.unconverted(source, d, fmt.Sprintf("zone %q is not defined in the ConfigMap http-snippet", zone))
+		return
+	}
+	n, unit := rate, "limit-rps"
+	if v, ok := strings.CutSuffix(rate, "r/m"); ok {
+		n, unit = v, "limit-rpm"
+	} else {
+		n = strings.TrimSuffix(rate, "r/s")
+	}
+	r, err := strconv.Atoi(n)
+	if err != nil || r <= 0 {
+		c.unconverted(source, d, fmt.Sprintf("zone rate %s", rate))
+		return
+	}
+	c.conv.Annotations[unit] = n
+	if burst > r {
+		c.conv.Annotations["limit-burst-multiplier"] = strconv.Itoa((burst + r - 1) / r)
+	}
+}
+
+// convertIngressSnippets converts the configuration-snippet and
+// server-snippet of an Ingress. auth-snippet and stream-snippet have no
+// annotation equivalents and are listed as unconverted.
+func convertIngressSnippets(anns map[string]string, zones map[string]string) snippetConversion {
+	c := &snippetConverter{zones: zones, conv: snippetConversion{
+		Annotations: map[string]string{}, Headers: map[string]string{}, UpstreamHeaders: map[string]string{},
+	}}
+	for _, s := range ingressSnippets(anns) {
+		source, text := s[0], s[1]
+		if source != "configuration-snippet" && source != "server-snippet" {
+			c.conv.Unconverted = append(c.conv.Unconverted, fmt.Sprintf("%s: no annotation equivalent", source))
+			continue
+		}
+		ds, err := parseNginxSnippet(text)
+		if err != nil {
+			c.conv.Unconverted = append(c.conv.Unconverted, fmt.Sprintf("%s: does not parse (%v)", source, err))
+			continue
+		}
+		for _, d := range ds {
+			c.directive(source, d)
+		}
+	}
+	return c.conv
+}
+
+// customHeadersConfigMap names the ConfigMap holding an Ingress's converted
+// response headers.
+func customHeadersConfigMap(name string) string { return name + "-custom-headers" }
+
+// renderHeadersConfigMap returns a ConfigMap manifest holding headers.
+func renderHeadersConfigMap(ns, name string, headers map[string]string) string {
+	var b strings.Builder
+	b.WriteString("apiVersion: v1\n")
+	b.WriteString("kind: ConfigMap\n")
+	b.WriteString("metadata:\n")
+	fmt.Fprintf(&b, "  name: %s\n", name)
+	fmt.Fprintf(&b, "  namespace: %s\n", ns)
+	b.WriteString("data:\n")
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "  %s: %q\n", k, headers[k])
+	}
+	return b.String()
+}
+
+// conversionPatch returns the JSON merge patch that sets the converted
+// annotations on the Ingress and removes its snippets.
+func conversionPatch(ns, name string, conv snippetConversion, anns map[string]string) string {
+	patch := map[string]interface{}{}
+	for k, v := range conv.Annotations {
+		patch[nginxAnnotationPrefix+k] = v
+	}
+	if len(conv.Headers) > 0 {
+		patch[nginxAnnotationPrefix+"custom-headers"] = ns + "/" + customHeadersConfigMap(name)
+	}
+	for _, s := range ingressSnippets(anns) {
+		patch[nginxAnnotationPrefix+s[0]] = nil
+	}
+	body, _ := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": patch}})
+	return string(body)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ─── convertIngressSnippets ──────────────────────────────────────────────────
+
+func TestConvertIngressSnippetsComplete(t *testing.T) {
+	anns := map[string]string{
+		nginxAnnotationPrefix + "configuration-snippet": `more_set_headers "X-Frame-Options: DENY" "X-Team: web";
+add_header Access-Control-Allow-Origin "https://app.example.com" always;
+if ($request_method = 'OPTIONS') {
+    add_header Access-Control-Max-Age 3600;
+    return 204;
+}
+limit_req zone=api burst=20 nodelay;
+proxy_set_header Host backend.example.com;
+proxy_read_timeout 2m;
+client_max_body_size 10m;`,
+		nginxAnnotationPrefix + "server-snippet": `if ($scheme = http) { return 301 https://$host$request_uri; }`,
+	}
+	conv := convertIngressSnippets(anns, map[string]string{"api": "10r/s"})
+	if !conv.Complete() {
+		t.Fatalf("unconverted = %v", conv.Unconverted)
+	}
+	want := map[string]string{
+		"enable-cors":            "true",
+		"cors-allow-origin":      "https://app.example.com",
+		"cors-max-age":           "3600",
+		"limit-rps":              "10",
+		"limit-burst-multiplier": "2",
+		"upstream-vhost":         "backend.example.com",
+		"proxy-read-timeout":     "120",
+		"proxy-body-size":        "10m",
+		"force-ssl-redirect":     "true",
+	}
+	for k, v := range want {
+		if conv.Annotations[k] != v {
+			t.Errorf("%s = %q, want %q", k, conv.Annotations[k], v)
+		}
+	}
+	if len(conv.Annotations) != len(want) {
+		t.Errorf("annotations = %v", conv.Annotations)
+	}
+	if conv.Headers["X-Frame-Options"] != "DENY" || conv.Headers["X-Team"] != "web" || len(conv.Headers) != 2 {
+		t.Errorf("headers = %v", conv.Headers)
+	}
+}
+
+func TestConvertIngressSnippetsRedirects(t *testing.T) {
+	cases := map[string]map[string]string{
+		"return 308 https://new.example.com;":                     {"permanent-redirect": "https://new.example.com", "permanent-redirect-code": "308"},
+		"rewrite ^ https://new.example.com$request_uri redirect;": {"temporal-redirect": "https://new.example.com$request_uri"},
+	}
+	for snippet, want := range cases {
+		conv := convertIngressSnippets(map[string]string{nginxAnnotationPrefix + "configuration-snippet": snippet}, nil)
+		if !conv.Complete() || len(conv.Annotations) != len(want) {
+			t.Errorf("%s: %+v", snippet, conv)
+			continue
+		}
+		for k, v := range want {
+			if conv.Annotations[k] != v {
+				t.Errorf("%s: %s = %q, want %q", snippet, k, conv.Annotations[k], v)
+			}
+		}
+	}
+}
+
+func TestConvertIngressSnippetsUnconverted(t *testing.T) {
+	anns := map[string]string{
+		nginxAnnotationPrefix + "configuration-snippet": "limit_req zone=other;\nsub_filter a b;\nproxy_set_header X-Tenant web;",
+		nginxAnnotationPrefix + "auth-snippet":          "proxy_set_header A b;",
+	}
+	conv := convertIngressSnippets(anns, map[string]string{"api": "10r/s"})
+	want := []string{
+		`configuration-snippet line 1: limit_req zone=other (zone "other" is not defined`,
+		"configuration-snippet line 2: sub_filter a b",
+		"auth-snippet: no annotation equivalent",
+	}
+	if len(conv.Unconverted) != len(want) {
+		t.Fatalf("unconverted = %v", conv.Unconverted)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(conv.Unconverted[i], w) {
+			t.Errorf("unconverted[%d] = %q, want prefix %q", i, conv.Unconverted[i], w)
+		}
+	}
+	if conv.UpstreamHeaders["X-Tenant"] != "web" {
+		t.Errorf("upstream headers = %v", conv.UpstreamHeaders)
+	}
+}
+
+// ─── limitReqZones ───────────────────────────────────────────────────────────
+
+func TestLimitReqZones(t *testing.T) {
+	zones := limitReqZones("limit_req_zone $binary_remote_addr zone=api:10m rate=10r/s;\nlimit_req_zone $server_name zone=bulk:1m rate=30r/m;")
+	if zones["api"] != "10r/s" || zones["bulk"] != "30r/m" || len(zones) != 2 {
+		t.Errorf("zones = %v", zones)
+	}
+}
+
+// ─── conversionPatch ─────────────────────────────────────────────────────────
+
+func TestConversionPatch(t *testing.T) {
+	anns := map[string]string{nginxAnnotationPrefix + "configuration-snippet": `more_set_headers "X-Team: web";`}
+	conv := convertIngressSnippets(anns, nil)
+	patch := conversionPatch("team", "web", conv, anns)
+	for _, want := range []string{
+		`"nginx.ingress.kubernetes.io/configuration-snippet":null`,
+		`"nginx.ingress.kubernetes.io/custom-headers":"team/web-custom-headers"`,
+	} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("patch %s lacks %s", patch, want)
+		}
+	}
+	cm := renderHeadersConfigMap("team", customHeadersConfigMap("web"), conv.Headers)
+	if !strings.Contains(cm, "name: web-custom-headers\n  namespace: team\ndata:\n  X-Team: \"web\"\n") {
+		t.Errorf("ConfigMap:\n%s", cm)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -19,6 +20,29 @@ type Fix struct {
 	Run         func() error
 }
 
+// Finding is a single structured audit result. Findings are printed like any
+// other log line and additionally carried into the JSON report.
+type Finding struct {
+	ID          string `json:"id"`
+	Level       string `json:"level"` // "FAIL" | "WARN" | "INFO"
+	Resource    string `json:"resource,omitempty"`
+	Message     string `json:"message"`
+	Rationale   string `json:"rationale,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Options holds the optional command-line flags. It is embedded in
+// AuditState and copied as a whole into each per-namespace audit.
+type Options struct {
+	ControlPlaneCIDR     string
+	MonitoringNamespace  string
+	LBScheme             string
+	ServiceAllowlistFile string
+	TLSProfile           string
+	ImageAllowlist       string
+	ImageScanReport      string
+}
+
 // AuditState carries all configuration, discovered values, counters and the
 // collected output for both the terminal and the saved text report.
 type AuditState struct {
@@ -30,8 +54,12 @@ type AuditState struct {
 	Email          string
 	ControllerName string
 
-	// ── Fixable issues ────────────────────────────────
-	Fixes []Fix
+	// ── Command-line options ──────────────────────────
+	Options
+
+	// ── Fixable issues and structured findings ────────
+	Fixes    []Fix
+	Findings []Finding
 
 	// ── Result counters ───────────────────────────────
 	PassCount int
@@ -45,14 +73,18 @@ type AuditState struct {
 	CurrentContext      string
 	NodeCount           int
 	ReadyNodes          int
+	CloudProvider       string
 	DeploymentType      string
 	ControllerImage     string
 	ControllerVersion   string
 	ControllerReplicas  string
 	HelmChart           string
-	HelmChartVersion    string
+	HelmChartVersion    string // installed chart version, "" when unknown
+	HelmChartRepo       string
 	HelmStatus          string
 	HelmRevision        string
+	HelmRelease         string // release managing the controller, "" when not Helm-managed
+	HelmReleaseNS       string
 	AdmissionSvcType    string
 	AdmissionClusterIP  string
 	AdmissionExternalIP string
@@ -65,6 +97,20 @@ type AuditState struct {
 	NpCount             int
 	UpdateStrategy      string
 	ImagePullPolicy     string
+	NetPol              NetPolEvaluation
+	ExternalServices    []ExternalService
+	TLSCompliance       TLSProfileResult
+	PSS                 PSSResult
+	PodHealth           []PodHealth
+	HostExposure        HostExposure
+	ImageScan           *ImageScanResult
+	Certificates        []CertInfo
+
+	// ── Cached cluster objects ────────────────────────
+	workload  map[string]interface{}
+	configMap map[string]string
+	nsLabels  map[string]string
+	args      map[string]string
 
 	// ── Report file paths ─────────────────────────────
 	TextReportFile string
@@ -122,6 +168,85 @@ func (a *AuditState) addFix(id, severity, description, command string, run func(
 	})
 }
 
+// helmUpgradeArgs returns the "helm upgrade" arguments that apply values to
+// the release managing the controller, keeping every other value and the
+// installed chart version as is. Callers check helmFixUnavailable first.
+func (a *AuditState) helmUpgradeArgs(values map[string]string) []string {
+	args := []string{"upgrade", a.HelmRelease, "ingress-nginx", "--repo", a.HelmChartRepo,
+		"--version", a.HelmChartVersion, "-n", a.HelmReleaseNS, "--reuse-values"}
+	return append(args, helmSetArgs(values)...)
+}
+
+// helmFixUnavailable reports, and logs, that fix id cannot be applied to the
+// Helm-managed controller because the installed chart version is unknown:
+// an unpinned helm upgrade would also upgrade the chart.
+func (a *AuditState) helmFixUnavailable(id string) bool {
+	if a.HelmRelease == "" || a.HelmChartVersion != "" {
+		return false
+	}
+	a.logInfo(fmt.Sprintf("Fix %s not offered: the chart version of Helm release %s is unknown, so helm upgrade cannot be pinned",
+		id, a.HelmRelease))
+	return true
+}
+
+// addConfigMapFix registers a change to the controller ConfigMap. When the
+// controller is Helm-managed it is applied through controller.config values,
+// so the next helm upgrade does not revert it; otherwise as a live patch.
+func (a *AuditState) addConfigMapFix(id, severity, description string, data map[string]string) {
+	if a.helmFixUnavailable(id) {
+		return
+	}
+	if a.HelmRelease != "" {
+		values := map[string]string{}
+		for k, v := range data {
+			values["controller.config."+k] = v
+		}
+		args := a.helmUpgradeArgs(values)
+		a.addFix(id, severity, description+" (Helm values)", "helm "+shellJoin(args),
+			func() error { return runCmd("helm", args...) })
+		return
+	}
+	body, _ := json.Marshal(map[string]interface{}{"data": data})
+	ns, name := a.Namespace, a.ControllerName
+	a.addFix(id, severity, description,
+		fmt.Sprintf("kubectl patch cm %s -n %s --type merge -p '%s'", name, ns, body),
+		func() error {
+			return runCmd("kubectl", "patch", "cm", name, "-n", ns, "--type", "merge", "-p", string(body))
+		})
+}
+
+// addFinding logs msg at the given level ("FAIL", "WARN" or "INFO"), prints
+// the remediation beneath it and records the finding for the JSON report.
+func (a *AuditState) addFinding(id, level, resource, msg, remediation string) {
+	a.recordFinding(Finding{
+		ID:          id,
+		Level:       level,
+		Resource:    resource,
+		Message:     msg,
+		Remediation: remediation,
+	})
+}
+
+// recordFinding is addFinding for a fully populated Finding.
+func (a *AuditState) recordFinding(f Finding) {
+	switch f.Level {
+	case "FAIL":
+		a.logFail(f.Message)
+	case "WARN":
+		a.logWarn(f.Message)
+	default:
+		f.Level = "INFO"
+		a.logInfo(f.Message)
+	}
+	if f.Rationale != "" {
+		a.writeln(fmt.Sprintf("    %sRationale:%s   %s", Dim, Reset, f.Rationale))
+	}
+	if f.Remediation != "" {
+		a.writeln(fmt.Sprintf("    %sRemediation:%s %s", Dim, Reset, f.Remediation))
+	}
+	a.Findings = append(a.Findings, f)
+}
+
 // ─────────────────────────────────────────────
 // Section / header printers
 // ─────────────────────────────────────────────
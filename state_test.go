@@ -125,3 +125,57 @@ func TestAddFix_multipleFixesOrdered(t *testing.T) {
 		t.Error("Fixes should maintain insertion order")
 	}
 }
+
+func TestAddConfigMapFix_helmManaged(t *testing.T) {
+	a := newTestState()
+	a.Namespace, a.ControllerName = "ingress-nginx", "ingress-nginx-controller"
+	a.addConfigMapFix("snippet-annotations", "CRITICAL", "disable snippets",
+		map[string]string{"allow-snippet-annotations": "false"})
+	if !strings.HasPrefix(a.Fixes[0].Command, "kubectl patch cm ingress-nginx-controller") {
+		t.Errorf("unmanaged command = %q", a.Fixes[0].Command)
+	}
+
+	// helm list reports the chart as ingress-nginx-<version>.
+	a.HelmRelease, a.HelmReleaseNS, a.HelmChartRepo = "edge", "ingress", defaultHelmChartRepo
+	a.HelmChartVersion = helmChartVersion("ingress-nginx-4.12.1")
+	a.addConfigMapFix("snippet-annotations", "CRITICAL", "disable snippets",
+		map[string]string{"allow-snippet-annotations": "false"})
+	want := "helm upgrade edge ingress-nginx --repo https://kubernetes.github.io/ingress-nginx --version 4.12.1 " +
+		"-n ingress --reuse-values --set-string controller.config.allow-snippet-annotations=false"
+	if a.Fixes[1].Command != want {
+		t.Errorf("Helm command = %q, want %q", a.Fixes[1].Command, want)
+	}
+
+	// Without a chart version the upgrade cannot be pinned.
+	a.HelmChartVersion = ""
+	a.addConfigMapFix("snippet-annotations", "CRITICAL", "disable snippets",
+		map[string]string{"allow-snippet-annotations": "false"})
+	if len(a.Fixes) != 2 {
+		t.Errorf("unpinned Helm fix registered: %+v", a.Fixes[2])
+	}
+}
+
+// ─── addFinding ──────────────────────────────────────────────────────────────
+
+func TestAddFinding_recordsAndCounts(t *testing.T) {
+	a := newTestState()
+	a.addFinding("svc-extra-port", "WARN", "service/ns/ctrl", "port 10254 published", "remove it")
+	a.addFinding("lb-node-ports", "INFO", "service/ns/ctrl", "node ports allocated", "")
+	if a.WarnCount != 1 || a.InfoCount != 1 {
+		t.Errorf("counters: warn=%d info=%d, want 1/1", a.WarnCount, a.InfoCount)
+	}
+	if len(a.Findings) != 2 || a.Findings[0].ID != "svc-extra-port" {
+		t.Fatalf("Findings = %+v", a.Findings)
+	}
+	if !strings.Contains(a.OutputBuffer.String(), "Remediation: remove it") {
+		t.Error("remediation should be printed beneath the finding")
+	}
+}
+
+func TestAddFinding_unknownLevelIsInfo(t *testing.T) {
+	a := newTestState()
+	a.addFinding("x", "bogus", "", "msg", "")
+	if a.Findings[0].Level != "INFO" || a.InfoCount != 1 {
+		t.Errorf("unknown level should be recorded as INFO, got %+v", a.Findings[0])
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────────────────────────
+// Mozilla server-side TLS profiles
+// ─────────────────────────────────────────────
+
+// tlsProfile is one Mozilla server-side TLS configuration profile (v5.7).
+type tlsProfile struct {
+	Name                string
+	Protocols           []string
+	Ciphers             string // TLS 1.2 and below; TLS 1.3 suites are not configurable
+	Curves              []string
+	PreferServerCiphers bool
+	HSTSMaxAge          int
+}
+
+// mozillaProfiles lists the profiles from strictest to most permissive.
+var mozillaProfiles = []tlsProfile{
+	{
+		Name:       "modern",
+		Protocols:  []string{"TLSv1.3"},
+		Curves:     []string{"X25519", "prime256v1", "secp384r1"},
+		HSTSMaxAge: 63072000,
+	},
+	{
+		Name:      "intermediate",
+		Protocols: []string{"TLSv1.2", "TLSv1.3"},
+		Ciphers: "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:" +
+			"ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384:" +
+			"ECDHE-ECDSA-CHACHA20-POLY1305:ECDHE-RSA-CHACHA20-POLY1305:" +
+			"DHE-RSA-AES128-GCM-SHA256:DHE-RSA-AES256-GCM-SHA384:DHE-RSA-CHACHA20-POLY1305",
+		Curves:     []string{"X25519", "prime256v1", "secp384r1"},
+		HSTSMaxAge: 63072000,
+	},
+	{
+		Name:      "old",
+		Protocols: []string{"TLSv1", "TLSv1.1", "TLSv1.2", "TLSv1.3"},
+		Ciphers: "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:" +
+			"ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384:" +
+			"ECDHE-ECDSA-CHACHA20-POLY1305:ECDHE-RSA-CHACHA20-POLY1305:" +
+			"DHE-RSA-AES128-GCM-SHA256:DHE-RSA-AES256-GCM-SHA384:DHE-RSA-CHACHA20-POLY1305:" +
+			"ECDHE-ECDSA-AES128-SHA256:ECDHE-RSA-AES128-SHA256:ECDHE-ECDSA-AES128-SHA:ECDHE-RSA-AES128-SHA:" +
+			"ECDHE-ECDSA-AES256-SHA384:ECDHE-RSA-AES256-SHA384:ECDHE-ECDSA-AES256-SHA:ECDHE-RSA-AES256-SHA:" +
+			"DHE-RSA-AES128-SHA256:DHE-RSA-AES256-SHA256:AES128-GCM-SHA256:AES256-GCM-SHA384:" +
+			"AES128-SHA256:AES256-SHA256:AES128-SHA:AES256-SHA:DES-CBC3-SHA",
+		Curves:              []string{"X25519", "prime256v1", "secp384r1"},
+		PreferServerCiphers: true,
+		HSTSMaxAge:          63072000,
+	},
+}
+
+// findTLSProfile returns the Mozilla profile with the given name.
+func findTLSProfile(name string) (tlsProfile, bool) {
+	for _, p := range mozillaProfiles {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return tlsProfile{}, false
+}
+
+// TLSProfileResult is the graded TLS configuration of the controller.
+type TLSProfileResult struct {
+	Target    string            `json:"target"`
+	Satisfied string            `json:"satisfied"` // strictest profile met, or "none"
+	Compliant bool              `json:"compliant"`
+	Blockers  []string          `json:"blockers"`
+	Effective map[string]string `json:"effective"`
+}
+
+// tlsProfileKeys are the ConfigMap keys graded against a profile.
+var tlsProfileKeys = []string{
+	"ssl-protocols", "ssl-ciphers", "ssl-ecdh-curve", "ssl-prefer-server-ciphers", "hsts", "hsts-max-age",
+}
+
+// curveAliases maps alternative curve names to the OpenSSL names used by
+// the Mozilla profiles.
+var curveAliases = map[string]string{
+	"x25519": "X25519",
+	"p-256":  "prime256v1",
+	"p-384":  "secp384r1",
+}
+
+// checkTLSProfile compares the effective TLS settings with profile p. It
+// returns one blocker per non-compliant setting and the ConfigMap values
+// that would resolve them.
+func checkTLSProfile(cm map[string]string, version string, p tlsProfile) (blockers []string, patch map[string]string) {
+	patch = map[string]string{}
+	get := func(key string) string {
+		v, _ := effectiveConfig(cm, key, version)
+		return v
+	}
+
+	protocols := strings.Fields(get("ssl-protocols"))
+	var extra []string
+	tls12 := false
+	for _, proto := range protocols {
+		if proto == "TLSv1.2" || proto == "TLSv1" || proto == "TLSv1.1" {
+			tls12 = true
+		}
+		if !contains(p.Protocols, proto) {
+			extra = append(extra, proto)
+		}
+	}
+	if len(extra) > 0 {
+		blockers = append(blockers, fmt.Sprintf("ssl-protocols enables %s (profile allows %s)",
+			strings.Join(extra, " "), strings.Join(p.Protocols, " ")))
+		patch["ssl-protocols"] = strings.Join(p.Protocols, " ")
+	}
+
+	// Cipher suites only matter while a pre-1.3 protocol is offered.
+	if tls12 && p.Ciphers != "" {
+		allowed := strings.Split(p.Ciphers, ":")
+		var bad []string
+		for _, c := range strings.Split(get("ssl-ciphers"), ":") {
+			c = strings.TrimSpace(c)
+			// Keywords expand against the controller's OpenSSL build and
+			// cannot be matched by name; weak ones are caught by
+			// cm-ssl-ciphers.
+			if c == "" || strings.HasPrefix(c, "!") || strings.HasPrefix(c, "-") || isCipherKeyword(c) {
+				continue
+			}
+			if !contains(allowed, c) {
+				bad = append(bad, c)
+			}
+		}
+		if len(bad) > 0 {
+			blockers = append(blockers, fmt.Sprintf("ssl-ciphers enables suite(s) outside the profile: %s", strings.Join(bad, ", ")))
+			patch["ssl-ciphers"] = p.Ciphers
+		}
+	}
+
+	if curves := get("ssl-ecdh-curve"); curves != "auto" {
+		var bad []string
+		for _, c := range strings.Split(curves, ":") {
+			c = strings.TrimSpace(c)
+			if alias, ok := curveAliases[strings.ToLower(c)]; ok {
+				c = alias
+			}
+			if c != "" && !contains(p.Curves, c) {
+				bad = append(bad, c)
+			}
+		}
+		if len(bad) > 0 {
+			blockers = append(blockers, fmt.Sprintf("ssl-ecdh-curve enables curve(s) outside the profile: %s", strings.Join(bad, ", ")))
+			patch["ssl-ecdh-curve"] = strings.Join(p.Curves, ":")
+		}
+	}
+
+	// Server cipher preference is only required by the old profile; with the
+	// all-AEAD lists of modern and intermediate it has no security effect.
+	if p.PreferServerCiphers && get("ssl-prefer-server-ciphers") != "true" {
+		blockers = append(blockers, "ssl-prefer-server-ciphers must be enabled")
+		patch["ssl-prefer-server-ciphers"] = "true"
+	}
+
+	if get("hsts") != "true" {
+		blockers = append(blockers, "HSTS is disabled")
+		patch["hsts"] = "true"
+		patch["hsts-max-age"] = strconv.Itoa(p.HSTSMaxAge)
+	} else if age, err := strconv.Atoi(get("hsts-max-age")); err != nil || age < p.HSTSMaxAge {
+		blockers = append(blockers, fmt.Sprintf("hsts-max-age %s is below %d", get("hsts-max-age"), p.HSTSMaxAge))
+		patch["hsts-max-age"] = strconv.Itoa(p.HSTSMaxAge)
+	}
+	return blockers, patch
+}
+
+// evaluateTLSProfile grades the effective TLS settings against every Mozilla
+// profile and against the target profile. It returns the result and the
+// ConfigMap patch that would make the target compliant.
+func evaluateTLSProfile(cm map[string]string, version string, target tlsProfile) (TLSProfileResult, map[string]string) {
+	res := TLSProfileResult{Target: target.Name, Satisfied: "none", Effective: map[string]string{}}
+	for _, k := range tlsProfileKeys {
+		res.Effective[k], _ = effectiveConfig(cm, k, version)
+	}
+	for _, p := range mozillaProfiles {
+		if b, _ := checkTLSProfile(cm, version, p); len(b) == 0 {
+			res.Satisfied = p.Name
+			break
+		}
+	}
+	blockers, patch := checkTLSProfile(cm, version, target)
+	res.Blockers = blockers
+	res.Compliant = len(blockers) == 0
+	return res, patch
+}
@@ -0,0 +1,89 @@
+package main
+
+import "testing"
+
+func profile(t *testing.T, name string) tlsProfile {
+	t.Helper()
+	p, ok := findTLSProfile(name)
+	if !ok {
+		t.Fatalf("profile %s not found", name)
+	}
+	return p
+}
+
+// ─── evaluateTLSProfile ──────────────────────────────────────────────────────
+
+func TestEvaluateTLSProfileDefaults(t *testing.T) {
+	// Controller defaults fall short of every profile only on hsts-max-age.
+	res, patch := evaluateTLSProfile(map[string]string{}, "v1.12.0", profile(t, "intermediate"))
+	if res.Compliant || res.Satisfied != "none" {
+		t.Errorf("defaults: compliant=%v satisfied=%q", res.Compliant, res.Satisfied)
+	}
+	if len(res.Blockers) != 1 || patch["hsts-max-age"] != "63072000" || len(patch) != 1 {
+		t.Errorf("blockers=%v patch=%v, want only hsts-max-age", res.Blockers, patch)
+	}
+}
+
+func TestEvaluateTLSProfileIntermediate(t *testing.T) {
+	cm := map[string]string{"hsts-max-age": "63072000"}
+	res, _ := evaluateTLSProfile(cm, "v1.12.0", profile(t, "modern"))
+	if res.Satisfied != "intermediate" {
+		t.Errorf("satisfied = %q, want intermediate", res.Satisfied)
+	}
+	if res.Compliant {
+		t.Error("TLSv1.2 enabled should not comply with modern")
+	}
+}
+
+func TestEvaluateTLSProfileModern(t *testing.T) {
+	cm := map[string]string{
+		"ssl-protocols":  "TLSv1.3",
+		"ssl-ciphers":    "RC4-SHA", // irrelevant without TLS 1.2
+		"ssl-ecdh-curve": "X25519:P-256",
+		"hsts-max-age":   "63072000",
+	}
+	res, _ := evaluateTLSProfile(cm, "v1.12.0", profile(t, "modern"))
+	if !res.Compliant || res.Satisfied != "modern" {
+		t.Errorf("compliant=%v satisfied=%q blockers=%v", res.Compliant, res.Satisfied, res.Blockers)
+	}
+}
+
+func TestEvaluateTLSProfileBlockers(t *testing.T) {
+	cm := map[string]string{
+		"ssl-protocols":  "TLSv1 TLSv1.2",
+		"ssl-ciphers":    "ECDHE-RSA-AES128-GCM-SHA256:AES128-SHA:!aNULL",
+		"ssl-ecdh-curve": "secp521r1",
+		"hsts":           "false",
+	}
+	res, patch := evaluateTLSProfile(cm, "v1.12.0", profile(t, "intermediate"))
+	if len(res.Blockers) != 4 {
+		t.Errorf("blockers = %v, want 4", res.Blockers)
+	}
+	for _, k := range []string{"ssl-protocols", "ssl-ciphers", "ssl-ecdh-curve", "hsts", "hsts-max-age"} {
+		if patch[k] == "" {
+			t.Errorf("patch missing %s", k)
+		}
+	}
+	if res.Satisfied != "none" {
+		t.Errorf("satisfied = %q, want none", res.Satisfied)
+	}
+}
+
+func TestEvaluateTLSProfileOldNeedsServerPreference(t *testing.T) {
+	cm := map[string]string{"ssl-prefer-server-ciphers": "false", "hsts-max-age": "63072000"}
+	res, patch := evaluateTLSProfile(cm, "v1.12.0", profile(t, "old"))
+	if res.Compliant || patch["ssl-prefer-server-ciphers"] != "true" {
+		t.Errorf("old profile should require server cipher preference: %v", res.Blockers)
+	}
+}
+
+func TestEvaluateTLSProfileSkipsCipherKeywords(t *testing.T) {
+	cm := map[string]string{
+		"ssl-ciphers":  "ECDHE-RSA-AES128-GCM-SHA256:HIGH:EECDH+AESGCM:!aNULL:@STRENGTH",
+		"hsts-max-age": "63072000",
+	}
+	res, patch := evaluateTLSProfile(cm, "v1.12.0", profile(t, "intermediate"))
+	if !res.Compliant || patch["ssl-ciphers"] != "" {
+		t.Errorf("keywords flagged as suites: %v", res.Blockers)
+	}
+}
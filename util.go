@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -78,3 +79,101 @@ func getMap(m map[string]interface{}, key string) map[string]interface{} {
 	}
 	return v
 }
+
+// getSlice safely extracts a []interface{} from m by key.
+func getSlice(m map[string]interface{}, key string) []interface{} {
+	if m == nil {
+		return nil
+	}
+	v, _ := m[key].([]interface{})
+	return v
+}
+
+// getString returns m[key] as a string, or "" when missing or not a string.
+// Unlike fmt.Sprintf("%v", ...) it never yields "<nil>".
+func getString(m map[string]interface{}, key string) string {
+	if m == nil {
+		return ""
+	}
+	v, _ := m[key].(string)
+	return v
+}
+
+// getStringMap converts a nested string-valued object (labels, annotations,
+// ConfigMap data) into a map[string]string. Non-string values are skipped.
+func getStringMap(m map[string]interface{}, key string) map[string]string {
+	out := map[string]string{}
+	for k, v := range getMap(m, key) {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
+
+// toInt converts a decoded JSON number (float64) to an int.
+// The second return value is false when v is not a number.
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	}
+	return 0, false
+}
+
+// compareVersions compares two dotted versions such as "v1.12.0" and
+// "1.9", returning -1, 0 or 1. Missing or non-numeric components count as 0.
+func compareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionAtLeast reports whether version v is min or newer. An unknown
+// version is treated as current, so checks assume the latest defaults.
+func versionAtLeast(v, min string) bool {
+	if v == "" || v == "unknown" {
+		return true
+	}
+	return compareVersions(v, min) >= 0
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// shellJoin renders args for display as a shell command line, single-quoting
+// arguments that contain shell metacharacters.
+func shellJoin(args []string) string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t'\"\\$`|&;<>(){}*?[]#~") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		out[i] = arg
+	}
+	return strings.Join(out, " ")
+}
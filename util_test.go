@@ -106,3 +106,77 @@ func TestGetMap(t *testing.T) {
 		t.Errorf("getMap nil map should be empty, got %v", nilResult)
 	}
 }
+
+// ─── getSlice / getString / getStringMap / toInt ────────────────────────────
+
+func TestGetSliceAndString(t *testing.T) {
+	m := map[string]interface{}{
+		"items": []interface{}{"a", "b"},
+		"name":  "ctrl",
+		"num":   float64(3),
+	}
+	if got := getSlice(m, "items"); len(got) != 2 {
+		t.Errorf("getSlice existing key: got %v", got)
+	}
+	if got := getSlice(m, "name"); got != nil {
+		t.Errorf("getSlice wrong type should be nil, got %v", got)
+	}
+	if got := getString(m, "name"); got != "ctrl" {
+		t.Errorf("getString: got %q", got)
+	}
+	if got := getString(m, "missing"); got != "" {
+		t.Errorf("getString missing key should be empty, got %q", got)
+	}
+	if n, ok := toInt(m["num"]); !ok || n != 3 {
+		t.Errorf("toInt(3.0) = %d, %v", n, ok)
+	}
+	if _, ok := toInt(m["name"]); ok {
+		t.Error("toInt should reject strings")
+	}
+}
+
+func TestGetStringMap(t *testing.T) {
+	m := map[string]interface{}{
+		"labels": map[string]interface{}{"app": "nginx", "n": float64(1)},
+	}
+	got := getStringMap(m, "labels")
+	if len(got) != 1 || got["app"] != "nginx" {
+		t.Errorf("getStringMap = %v, want only app=nginx", got)
+	}
+	if got := getStringMap(nil, "labels"); len(got) != 0 {
+		t.Errorf("getStringMap nil map should be empty, got %v", got)
+	}
+}
+
+// ─── compareVersions / versionAtLeast ───────────────────────────────────────
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.12.0", "v1.12.0", 0},
+		{"v1.9.6", "v1.12.0", -1},
+		{"v1.12.1", "1.12", 1},
+		{"1.10", "v1.10.0", 0},
+	}
+	for _, c := range cases {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+	if !versionAtLeast("unknown", "v1.12.0") {
+		t.Error("unknown version should be treated as current")
+	}
+	if versionAtLeast("v1.11.3", "v1.12.0") {
+		t.Error("v1.11.3 is older than v1.12.0")
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := shellJoin([]string{"helm", "--set-string", `a=b\,c`, "it's", ""})
+	want := `helm --set-string 'a=b\,c' 'it'\''s' ''`
+	if got != want {
+		t.Errorf("shellJoin = %s, want %s", got, want)
+	}
+}
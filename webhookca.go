@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ─────────────────────────────────────────────
+// Admission webhook CA bundle consistency
+// ─────────────────────────────────────────────
+
+// admissionSecretName is the Secret kube-webhook-certgen writes the webhook
+// CA ("ca"), serving certificate ("cert") and key ("key") to.
+const admissionSecretName = "ingress-nginx-admission"
+
+// webhookCAResult is the outcome of evaluateWebhookCA. Resync is set when
+// the secret is consistent but the caBundle is not, so copying the secret's
+// CA into the webhook configuration repairs admission; Regenerate when the
+// secret itself must be reissued.
+type webhookCAResult struct {
+	Findings   []Finding
+	Resync     bool
+	Regenerate bool
+}
+
+// bundleCerts parses a PEM bundle, returning nil when it holds none.
+func bundleCerts(data []byte) []*x509.Certificate {
+	certs, _ := parseCertChain(data)
+	return certs
+}
+
+// chainsTo reports whether every certificate of a leaf-first chain is signed
+// by the next and the last is one of roots or signed by one. Validity dates
+// are checked separately.
+func chainsTo(chain, roots []*x509.Certificate) bool {
+	for i := 0; i+1 < len(chain); i++ {
+		if chain[i].CheckSignatureFrom(chain[i+1]) != nil {
+			return false
+		}
+	}
+	last := chain[len(chain)-1]
+	for _, r := range roots {
+		if last.Equal(r) || last.CheckSignatureFrom(r) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateWebhookCA checks the admission secret against the caBundle of
+// every webhook (base64 PEM, as in the ValidatingWebhookConfiguration): the
+// serving certificate must chain to the bundle, carry the service DNS name
+// and, like the CA, be within its validity period.
+func evaluateWebhookCA(caBundles []string, secretData map[string]interface{}, svcName, svcNS string,
+	now time.Time, res string) webhookCAResult {
+	var r webhookCAResult
+	add := func(id, level, msg, fix string) {
+		r.Findings = append(r.Findings, Finding{ID: id, Level: level, Resource: res, Message: msg, Remediation: fix})
+	}
+	decode := func(key string) []byte {
+		b, _ := base64.StdEncoding.DecodeString(getString(secretData, key))
+		return b
+	}
+
+	chain, err := parseCertChain(decode("cert"))
+	if err != nil {
+		add("webhook-cert-invalid", "FAIL", fmt.Sprintf("Admission secret %s has no valid serving certificate: %v", admissionSecretName, err),
+			"Regenerate the admission certificate")
+		r.Regenerate = true
+		return r
+	}
+	leaf := chain[0]
+
+	if key, err := parsePrivateKey(decode("key")); err != nil || !keyMatchesCert(leaf, key) {
+		add("webhook-key-mismatch", "FAIL", "The admission secret's key does not belong to its serving certificate",
+			"Regenerate the admission certificate")
+		r.Regenerate = true
+	}
+	host := fmt.Sprintf("%s.%s.svc", svcName, svcNS)
+	if leaf.VerifyHostname(host) != nil {
+		add("webhook-cert-san", "FAIL", fmt.Sprintf("Serving certificate SANs %v do not include %s", certSANs(leaf), host),
+			"Regenerate the admission certificate for the current service name and namespace")
+		r.Regenerate = true
+	}
+	for _, f := range evaluateCert(CertInfo{Subject: leaf.Subject.String(), NotBefore: leaf.NotBefore, NotAfter: leaf.NotAfter}, now, res) {
+		f.Message = strings.Replace(f.Message, "Certificate", "Admission serving certificate", 1)
+		r.Findings = append(r.Findings, f)
+		r.Regenerate = r.Regenerate || f.Level == "FAIL"
+	}
+
+	// The secret's own CA tells whether a re-sync is enough.
+	caCerts := bundleCerts(decode("ca"))
+	secretConsistent := chainsTo(chain, caCerts) && !r.Regenerate
+	for _, c := range caCerts {
+		if now.After(c.NotAfter) {
+			add("webhook-ca-expired", "FAIL", fmt.Sprintf("Admission CA %s expired on %s", c.Subject, c.NotAfter.Format("2006-01-02")),
+				"Regenerate the admission CA and certificate")
+			r.Regenerate, secretConsistent = true, false
+		}
+	}
+
+	for i, b64 := range caBundles {
+		bundle, _ := base64.StdEncoding.DecodeString(b64)
+		certs := bundleCerts(bundle)
+		switch {
+		case len(certs) == 0:
+			add("webhook-cabundle-missing", "FAIL", fmt.Sprintf("Webhook %d has no caBundle; the API server cannot verify the admission endpoint", i+1),
+				"Patch the caBundle from the admission secret's ca")
+		case !chainsTo(chain, certs):
+			add("webhook-ca-mismatch", "FAIL", fmt.Sprintf("Serving certificate %s does not chain to the caBundle of webhook %d (issuer %s)",
+				leaf.Subject, i+1, leaf.Issuer), "Re-sync the caBundle from the admission secret, or re-run the certgen patch job")
+		default:
+			for _, c := range certs {
+				if now.After(c.NotAfter) {
+					add("webhook-ca-expired", "FAIL", fmt.Sprintf("caBundle certificate %s of webhook %d expired on %s", c.Subject, i+1, c.NotAfter.Format("2006-01-02")),
+						"Regenerate the admission CA and certificate")
+					r.Regenerate = true
+				}
+			}
+			continue
+		}
+		if secretConsistent {
+			r.Resync = true
+		} else {
+			r.Regenerate = true
+		}
+	}
+	if r.Regenerate {
+		r.Resync = false
+	}
+	return r
+}
+
+// caBundlePatch returns a JSON patch setting the caBundle of n webhooks to
+// ca (base64 PEM). It uses "add", which also creates a missing caBundle
+// where "replace" would be rejected.
+func caBundlePatch(n int, ca string) string {
+	var ops []map[string]interface{}
+	for i := 0; i < n; i++ {
+		ops = append(ops, map[string]interface{}{"op": "add",
+			"path": fmt.Sprintf("/webhooks/%d/clientConfig/caBundle", i), "value": ca})
+	}
+	patch, _ := json.Marshal(ops)
+	return string(patch)
+}
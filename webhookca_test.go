@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+const admissionHost = "ingress-nginx-controller-admission.ingress-nginx.svc"
+
+// admissionPair returns a CA and a serving certificate for the admission
+// service, PEM encoded, plus the serving key.
+func admissionPair(t *testing.T, dnsName string, notAfter time.Time) (caPEM, certPEM []byte, key *ecdsa.PrivateKey) {
+	t.Helper()
+	caKey := ecKey(t)
+	caPEM, ca := testCert(t, "nil1", caKey, nil, nil, x509.ECDSAWithSHA256, notAfter)
+	key = ecKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{Organization: []string{"nil2"}},
+		DNSNames:     []string{dnsName},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, key.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return caPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), key
+}
+
+func admissionSecretData(t *testing.T, caPEM, certPEM []byte, key *ecdsa.PrivateKey) map[string]interface{} {
+	b64 := base64.StdEncoding.EncodeToString
+	return map[string]interface{}{"ca": b64(caPEM), "cert": b64(certPEM), "key": b64(ecKeyPEM(t, key))}
+}
+
+func findingIDs(fs []Finding) []string {
+	var ids []string
+	for _, f := range fs {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+// ─── evaluateWebhookCA ───────────────────────────────────────────────────────
+
+func TestEvaluateWebhookCAConsistent(t *testing.T) {
+	now := time.Now()
+	caPEM, certPEM, key := admissionPair(t, admissionHost, now.Add(365*24*time.Hour))
+	bundle := base64.StdEncoding.EncodeToString(caPEM)
+	r := evaluateWebhookCA([]string{bundle, bundle}, admissionSecretData(t, caPEM, certPEM, key),
+		"ingress-nginx-controller-admission", "ingress-nginx", now, "vwc")
+	if len(r.Findings) != 0 || r.Resync || r.Regenerate {
+		t.Errorf("result = %+v", r)
+	}
+}
+
+func TestEvaluateWebhookCAStaleBundle(t *testing.T) {
+	now := time.Now()
+	caPEM, certPEM, key := admissionPair(t, admissionHost, now.Add(365*24*time.Hour))
+	oldCA, _, _ := admissionPair(t, admissionHost, now.Add(365*24*time.Hour))
+	r := evaluateWebhookCA([]string{base64.StdEncoding.EncodeToString(oldCA)}, admissionSecretData(t, caPEM, certPEM, key),
+		"ingress-nginx-controller-admission", "ingress-nginx", now, "vwc")
+	if len(r.Findings) != 1 || r.Findings[0].ID != "webhook-ca-mismatch" || !r.Resync || r.Regenerate {
+		t.Errorf("result = %+v", r)
+	}
+	r = evaluateWebhookCA([]string{""}, admissionSecretData(t, caPEM, certPEM, key),
+		"ingress-nginx-controller-admission", "ingress-nginx", now, "vwc")
+	if len(r.Findings) != 1 || r.Findings[0].ID != "webhook-cabundle-missing" || !r.Resync {
+		t.Errorf("result = %+v", r)
+	}
+}
+
+func TestEvaluateWebhookCARegenerate(t *testing.T) {
+	now := time.Now()
+	caPEM, certPEM, key := admissionPair(t, "ingress-nginx-controller-admission.other.svc", now.Add(365*24*time.Hour))
+	bundle := base64.StdEncoding.EncodeToString(caPEM)
+	r := evaluateWebhookCA([]string{bundle}, admissionSecretData(t, caPEM, certPEM, key),
+		"ingress-nginx-controller-admission", "ingress-nginx", now, "vwc")
+	if ids := findingIDs(r.Findings); len(ids) != 1 || ids[0] != "webhook-cert-san" || !r.Regenerate || r.Resync {
+		t.Errorf("result = %+v", r)
+	}
+
+	// Expired pair: the serving certificate and the CA are both reported.
+	caPEM, certPEM, key = admissionPair(t, admissionHost, now.Add(-24*time.Hour))
+	bundle = base64.StdEncoding.EncodeToString(caPEM)
+	r = evaluateWebhookCA([]string{bundle}, admissionSecretData(t, caPEM, certPEM, key),
+		"ingress-nginx-controller-admission", "ingress-nginx", now, "vwc")
+	if _, ok := findingByID(r.Findings, "cert-expired"); !ok || !r.Regenerate {
+		t.Errorf("result = %+v", r)
+	}
+	if _, ok := findingByID(r.Findings, "webhook-ca-expired"); !ok {
+		t.Errorf("result = %+v", r)
+	}
+
+	// A key that does not belong to the certificate.
+	caPEM, certPEM, _ = admissionPair(t, admissionHost, now.Add(365*24*time.Hour))
+	r = evaluateWebhookCA([]string{base64.StdEncoding.EncodeToString(caPEM)}, admissionSecretData(t, caPEM, certPEM, ecKey(t)),
+		"ingress-nginx-controller-admission", "ingress-nginx", now, "vwc")
+	if ids := findingIDs(r.Findings); len(ids) != 1 || ids[0] != "webhook-key-mismatch" || !r.Regenerate {
+		t.Errorf("result = %+v", r)
+	}
+}
+
+// ─── caBundlePatch ───────────────────────────────────────────────────────────
+
+func TestCABundlePatchAddsMissingBundle(t *testing.T) {
+	now := time.Now()
+	caPEM, certPEM, key := admissionPair(t, admissionHost, now.Add(365*24*time.Hour))
+	data := admissionSecretData(t, caPEM, certPEM, key)
+	if r := evaluateWebhookCA([]string{"", ""}, data, "ingress-nginx-controller-admission", "ingress-nginx", now, "vwc"); !r.Resync {
+		t.Fatalf("missing caBundle should be re-synced: %+v", r)
+	}
+	var ops []map[string]string
+	if err := json.Unmarshal([]byte(caBundlePatch(2, getString(data, "ca"))), &ops); err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 || ops[1]["path"] != "/webhooks/1/clientConfig/caBundle" || ops[1]["value"] != getString(data, "ca") {
+		t.Errorf("patch = %+v", ops)
+	}
+	for _, op := range ops {
+		if op["op"] != "add" {
+			t.Errorf("op = %q, want add (replace fails when the caBundle is absent)", op["op"])
+		}
+	}
+}